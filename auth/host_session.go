@@ -202,7 +202,7 @@ func SessionHandler(conn *websocket.Conn) {
 		if err = conn.WriteMessage(messageType, reponseData); err != nil {
 			logger.Log(0, "error during message writing:", err.Error())
 		}
-		go CheckNetRegAndHostUpdate(netsToAdd[:], &result.Host)
+		go CheckNetRegAndHostUpdate(netsToAdd[:], &result.Host, false)
 	case <-timeout: // the read from req.answerCh has timed out
 		if err = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
 			logger.Log(0, "error during timeout message writing:", err.Error())
@@ -220,13 +220,14 @@ func SessionHandler(conn *websocket.Conn) {
 	}
 }
 
-// CheckNetRegAndHostUpdate - run through networks and send a host update
-func CheckNetRegAndHostUpdate(networks []string, h *models.Host) {
+// CheckNetRegAndHostUpdate - run through networks and send a host update, the new nodes are pending approval when
+// requiresApproval is set or their network requires approval
+func CheckNetRegAndHostUpdate(networks []string, h *models.Host, requiresApproval bool) {
 	// publish host update through MQ
 	for i := range networks {
 		network := networks[i]
 		if ok, _ := logic.NetworkExists(network); ok {
-			newNode, err := logic.UpdateHostNetwork(h, network, true)
+			newNode, err := logic.RegisterHostNetwork(h, network, requiresApproval)
 			if err != nil {
 				logger.Log(0, "failed to add host to network:", h.ID.String(), h.Name, network, err.Error())
 				continue
@@ -16,15 +16,16 @@ var aclDenyCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fromNodeID := args[1]
 		toNodeID := args[2]
-		payload := acls.ACLContainer(map[acls.AclID]acls.ACL{
-			acls.AclID(fromNodeID): map[acls.AclID]byte{
-				acls.AclID(toNodeID): acls.NotAllowed,
-			},
-			acls.AclID(toNodeID): map[acls.AclID]byte{
-				acls.AclID(fromNodeID): acls.NotAllowed,
-			},
+		functions.UpdateACL(args[0], func(current *acls.ACLContainer) *acls.ACLContainer {
+			return &acls.ACLContainer{
+				acls.AclID(fromNodeID): acls.ACL{
+					acls.AclID(toNodeID): acls.NotAllowed,
+				},
+				acls.AclID(toNodeID): acls.ACL{
+					acls.AclID(fromNodeID): acls.NotAllowed,
+				},
+			}
 		})
-		functions.UpdateACL(args[0], &payload)
 		fmt.Println("Success")
 	},
 }
@@ -0,0 +1,32 @@
+package acl
+
+import (
+	"fmt"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var denySelected bool
+
+var aclSelectorCmd = &cobra.Command{
+	Use:   "selector [NETWORK NAME] [FROM SELECTOR] [TO SELECTOR]",
+	Args:  cobra.ExactArgs(3),
+	Short: "Allow or deny access between nodes selected by their labels",
+	Long: `Allow access between every node matching one label selector and every node matching another, or deny it with --deny.
+Node labels include the labels of their host, e.g. nmctl acl selector mynet role=web env=prod,role=db --deny`,
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.UpdateACLBySelector(args[0], &models.SelectorACLRequest{
+			From:    args[1],
+			To:      args[2],
+			Allowed: !denySelected,
+		})
+		fmt.Println("Success")
+	},
+}
+
+func init() {
+	aclSelectorCmd.Flags().BoolVar(&denySelected, "deny", false, "Deny access instead of allowing it")
+	rootCmd.AddCommand(aclSelectorCmd)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gravitl/netmaker/cli/declarative"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f FILE",
+	Args:  cobra.NoArgs,
+	Short: "Bring networks to the state described in a file",
+	Long: `Bring the networks described in a YAML file to the described state, only the differences to the server are applied.
+Networks, hosts, gateways, ACLs, DNS entries, ext clients and enrollment keys left out of the file are not changed.
+See cli/samples/network.yaml for the format.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		plan := buildPlan()
+		printPlan(plan)
+		if len(plan.Changes) == 0 {
+			return
+		}
+		plan.Apply(func(change declarative.Change) {
+			fmt.Printf("%s %s %s/%s done\n", change.Action, change.Kind, change.Network, change.Name)
+		})
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&specFilePath, "file", "f", "", "Path to the YAML file describing the networks")
+	applyCmd.MarkFlagRequired("file")
+	rootCmd.AddCommand(applyCmd)
+}
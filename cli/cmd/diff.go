@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/declarative"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var specFilePath string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff -f FILE",
+	Args:  cobra.NoArgs,
+	Short: "Show the changes apply would make",
+	Long:  `Compare the networks described in a YAML file with the server and show the changes apply would make, nothing is changed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		printPlan(buildPlan())
+	},
+}
+
+// buildPlan - loads the spec file and compares it with the server
+func buildPlan() *declarative.Plan {
+	spec, err := declarative.LoadSpec(specFilePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	plan, err := declarative.BuildPlan(spec, declarative.FetchState(spec))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return plan
+}
+
+func printPlan(plan *declarative.Plan) {
+	if commons.OutputFormat == commons.JsonOutput {
+		functions.PrettyPrint(plan)
+		return
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Println("warning:", warning)
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Println("no changes")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Action", "Kind", "Network", "Name", "Detail"})
+	for _, change := range plan.Changes {
+		table.Append([]string{string(change.Action), change.Kind, change.Network, change.Name, change.Detail})
+	}
+	table.Render()
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&specFilePath, "file", "f", "", "Path to the YAML file describing the networks")
+	diffCmd.MarkFlagRequired("file")
+	rootCmd.AddCommand(diffCmd)
+}
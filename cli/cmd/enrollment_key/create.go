@@ -9,11 +9,13 @@ import (
 )
 
 var (
-	expiration    int
-	usesRemaining int
-	networks      string
-	unlimited     bool
-	tags          string
+	expiration       int
+	usesRemaining    int
+	networks         string
+	unlimited        bool
+	tags             string
+	requiresApproval bool
+	ephemeral        bool
 )
 
 var enrollmentKeyCreateCmd = &cobra.Command{
@@ -23,9 +25,11 @@ var enrollmentKeyCreateCmd = &cobra.Command{
 	Long:  `Create an enrollment key`,
 	Run: func(cmd *cobra.Command, args []string) {
 		enrollKey := &models.APIEnrollmentKey{
-			Expiration:    int64(expiration),
-			UsesRemaining: usesRemaining,
-			Unlimited:     unlimited,
+			Expiration:       int64(expiration),
+			UsesRemaining:    usesRemaining,
+			Unlimited:        unlimited,
+			RequiresApproval: requiresApproval,
+			Ephemeral:        ephemeral,
 		}
 		if networks != "" {
 			enrollKey.Networks = strings.Split(networks, ",")
@@ -43,5 +47,7 @@ func init() {
 	enrollmentKeyCreateCmd.Flags().StringVar(&networks, "networks", "", "Comma-separated list of networks which the enrollment key can access")
 	enrollmentKeyCreateCmd.Flags().BoolVar(&unlimited, "unlimited", false, "Should the key have unlimited uses ?")
 	enrollmentKeyCreateCmd.Flags().StringVar(&tags, "tags", "", "Comma-separated list of any additional tags")
+	enrollmentKeyCreateCmd.Flags().BoolVar(&requiresApproval, "requires_approval", false, "Should the nodes of hosts registered with the key wait for an admin to approve them ?")
+	enrollmentKeyCreateCmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "Should hosts registered with the key be removed once they stay offline longer than the ephemeral host timeout ?")
 	rootCmd.AddCommand(enrollmentKeyCreateCmd)
 }
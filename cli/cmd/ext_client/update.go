@@ -21,24 +21,47 @@ var extClientUpdateCmd = &cobra.Command{
 	Long:  `Update an External Client`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
-			network   = args[0]
-			clientID  = args[1]
-			extClient = &models.CustomExtClient{}
+			network    = args[0]
+			clientID   = args[1]
+			fileClient *models.CustomExtClient
+			flags      = cmd.Flags()
 		)
 		if extClientUpdateFile != "" {
 			content, err := os.ReadFile(extClientUpdateFile)
 			if err != nil {
 				log.Fatal("Error when opening file: ", err)
 			}
-			if err := json.Unmarshal(content, extClient); err != nil {
+			fileClient = &models.CustomExtClient{}
+			if err := json.Unmarshal(content, fileClient); err != nil {
 				log.Fatal(err)
 			}
-		} else {
-			extClient.ClientID = extClientID
-			extClient.PublicKey = publicKey
-			extClient.DNS = dns
 		}
-		functions.PrettyPrint(functions.UpdateExtClient(network, clientID, extClient))
+		functions.PrettyPrint(functions.UpdateExtClient(network, clientID, func(current *models.ExtClient) *models.CustomExtClient {
+			if fileClient != nil {
+				return fileClient
+			}
+			extClient := &models.CustomExtClient{
+				ClientID:        current.ClientID,
+				PublicKey:       current.PublicKey,
+				DNS:             current.DNS,
+				ExtraAllowedIPs: current.ExtraAllowedIPs,
+				Enabled:         current.Enabled,
+				DeniedACLs:      current.DeniedACLs,
+			}
+			if flags.Changed("id") {
+				extClient.ClientID = extClientID
+			}
+			if flags.Changed("public_key") {
+				extClient.PublicKey = publicKey
+			}
+			if flags.Changed("dns") {
+				extClient.DNS = dns
+			}
+			if flags.Changed("allowedips") {
+				extClient.ExtraAllowedIPs = allowedips
+			}
+			return extClient
+		}))
 	},
 }
 
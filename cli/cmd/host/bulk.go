@@ -0,0 +1,168 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bulkFilter     models.HostFilter
+	bulkForce      bool
+	bulkWait       bool
+	bulkVerbosity  int
+	bulkMTU        int
+	bulkAutoUpdate bool
+)
+
+var hostBulkCmd = &cobra.Command{
+	Use:   "bulk",
+	Short: "Run an operation against every host matching a filter",
+	Long: `Run an operation against every host matching a filter of network, OS, version and label selector.
+The operation runs as a job on the server, its progress and the result of every host can be read with
+nmctl host bulk status, e.g. nmctl host bulk refresh_keys --os linux --selector env=prod --wait`,
+}
+
+var hostBulkAddNetworkCmd = &cobra.Command{
+	Use:   "add_network [NETWORK NAME]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Add the matching hosts to a network",
+	Long:  `Add the matching hosts to a network`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBulkJob(&models.BulkHostRequest{Operation: models.BulkAddToNetwork, Network: args[0]})
+	},
+}
+
+var hostBulkRemoveNetworkCmd = &cobra.Command{
+	Use:   "remove_network [NETWORK NAME]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Remove the matching hosts from a network",
+	Long:  `Remove the matching hosts from a network`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBulkJob(&models.BulkHostRequest{Operation: models.BulkRemoveFromNetwork, Network: args[0], Force: bulkForce})
+	},
+}
+
+var hostBulkRefreshKeysCmd = &cobra.Command{
+	Use:   "refresh_keys",
+	Args:  cobra.NoArgs,
+	Short: "Refresh the wireguard keys of the matching hosts",
+	Long:  `Refresh the wireguard keys of the matching hosts`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBulkJob(&models.BulkHostRequest{Operation: models.BulkRefreshKeys})
+	},
+}
+
+var hostBulkUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Args:  cobra.NoArgs,
+	Short: "Set the verbosity, MTU or auto update of the matching hosts",
+	Long:  `Set the verbosity, MTU or auto update of the matching hosts, only the given settings are changed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		request := &models.BulkHostRequest{Operation: models.BulkUpdateSettings}
+		flags := cmd.Flags()
+		if flags.Changed("verbosity") {
+			request.Verbosity = &bulkVerbosity
+		}
+		if flags.Changed("mtu") {
+			request.MTU = &bulkMTU
+		}
+		if flags.Changed("auto_update") {
+			request.AutoUpdate = &bulkAutoUpdate
+		}
+		runBulkJob(request)
+	},
+}
+
+var hostBulkDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Args:  cobra.NoArgs,
+	Short: "Delete the matching hosts",
+	Long:  `Delete the matching hosts, they can be restored from the trash until the retention period ends`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBulkJob(&models.BulkHostRequest{Operation: models.BulkDelete, Force: bulkForce})
+	},
+}
+
+var hostBulkStatusCmd = &cobra.Command{
+	Use:   "status [JOB ID]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Show a bulk job with the result of every host, or list the bulk jobs",
+	Long:  `Show a bulk job with the result of every host, or list the bulk jobs of the last week if no JOB ID is given`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			printBulkJobs(*functions.GetBulkJobs())
+			return
+		}
+		printBulkJob(functions.GetBulkJob(args[0]))
+	},
+}
+
+// runBulkJob - starts a bulk job for the hosts matching the filter flags and prints it, once it finished with --wait
+func runBulkJob(request *models.BulkHostRequest) {
+	request.Filter = bulkFilter
+	job := functions.CreateBulkJob(request)
+	for bulkWait && job.FinishedAt == nil {
+		time.Sleep(time.Second)
+		job = functions.GetBulkJob(job.ID)
+	}
+	printBulkJob(job)
+	if job.Status == models.BulkFailed {
+		os.Exit(1)
+	}
+}
+
+func printBulkJob(job *models.BulkJob) {
+	switch commons.OutputFormat {
+	case commons.JsonOutput:
+		functions.PrettyPrint(job)
+	default:
+		fmt.Printf("Job %s %s: %s, %d of %d hosts succeeded, %d failed\n", job.ID, job.Request.Operation, job.Status,
+			job.Succeeded, job.Total, job.Failed)
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Host ID", "Host Name", "Status", "Error"})
+		for _, result := range job.Results {
+			table.Append([]string{result.HostID, result.HostName, string(result.Status), result.Error})
+		}
+		table.Render()
+	}
+}
+
+func printBulkJobs(jobs []models.BulkJob) {
+	switch commons.OutputFormat {
+	case commons.JsonOutput:
+		functions.PrettyPrint(jobs)
+	default:
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"ID", "Operation", "Status", "Hosts", "Succeeded", "Failed", "Created By", "Created At"})
+		for _, job := range jobs {
+			table.Append([]string{job.ID, string(job.Request.Operation), string(job.Status), strconv.Itoa(job.Total),
+				strconv.Itoa(job.Succeeded), strconv.Itoa(job.Failed), job.CreatedBy, job.CreatedAt.Format(time.RFC3339)})
+		}
+		table.Render()
+	}
+}
+
+func init() {
+	flags := hostBulkCmd.PersistentFlags()
+	flags.StringVar(&bulkFilter.Network, "network", "", "Only hosts with a node in the network")
+	flags.StringVar(&bulkFilter.OS, "os", "", "Only hosts running the OS")
+	flags.StringVar(&bulkFilter.Version, "version", "", "Only hosts running the netclient version")
+	flags.StringVar(&bulkFilter.Selector, "selector", "", "Only hosts whose labels match the selector")
+	flags.BoolVar(&bulkWait, "wait", false, "Wait for the job to finish")
+	hostBulkRemoveNetworkCmd.Flags().BoolVar(&bulkForce, "force", false, "Remove the nodes without waiting for the hosts to acknowledge")
+	hostBulkDeleteCmd.Flags().BoolVar(&bulkForce, "force", false, "Delete the hosts without waiting for them to acknowledge")
+	hostBulkUpdateCmd.Flags().IntVar(&bulkVerbosity, "verbosity", 0, "Netclient log verbosity, 0 to 4")
+	hostBulkUpdateCmd.Flags().IntVar(&bulkMTU, "mtu", 0, "MTU of the wireguard interface")
+	hostBulkUpdateCmd.Flags().BoolVar(&bulkAutoUpdate, "auto_update", false, "Update the netclient automatically ?")
+	hostBulkCmd.AddCommand(hostBulkAddNetworkCmd, hostBulkRemoveNetworkCmd, hostBulkRefreshKeysCmd, hostBulkUpdateCmd,
+		hostBulkDeleteCmd, hostBulkStatusCmd)
+	rootCmd.AddCommand(hostBulkCmd)
+}
@@ -5,16 +5,23 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var hostSelector string
+
 var hostListCmd = &cobra.Command{
 	Use:   "list",
 	Args:  cobra.NoArgs,
 	Short: "List all hosts",
-	Long:  `List all hosts`,
+	Long:  `List all hosts, or with --selector the hosts whose labels match a selector like env=prod,role!=db`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if hostSelector != "" {
+			functions.PrettyPrint(functions.GetHostsBySelector(hostSelector))
+			return
+		}
 		functions.PrettyPrint(functions.GetHosts())
 	},
 }
 
 func init() {
+	hostListCmd.Flags().StringVar(&hostSelector, "selector", "", "Only list hosts whose labels match the selector")
 	rootCmd.AddCommand(hostListCmd)
 }
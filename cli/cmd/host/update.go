@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gravitl/netmaker/cli/functions"
 	"github.com/gravitl/netmaker/models"
@@ -18,6 +19,7 @@ var (
 	mtu             int
 	isStatic        bool
 	isDefault       bool
+	labels          string
 )
 
 var hostUpdateCmd = &cobra.Command{
@@ -26,28 +28,61 @@ var hostUpdateCmd = &cobra.Command{
 	Short: "Update a host",
 	Long:  `Update a host`,
 	Run: func(cmd *cobra.Command, args []string) {
-		apiHost := &models.ApiHost{}
+		var fileHost *models.ApiHost
 		if apiHostFilePath != "" {
 			content, err := os.ReadFile(apiHostFilePath)
 			if err != nil {
 				log.Fatal("Error when opening file: ", err)
 			}
-			if err := json.Unmarshal(content, apiHost); err != nil {
+			fileHost = &models.ApiHost{}
+			if err := json.Unmarshal(content, fileHost); err != nil {
 				log.Fatal(err)
 			}
-		} else {
-			apiHost.ID = args[0]
-			apiHost.EndpointIP = endpoint
-			apiHost.Name = name
-			apiHost.ListenPort = listenPort
-			apiHost.MTU = mtu
-			apiHost.IsStatic = isStatic
-			apiHost.IsDefault = isDefault
 		}
-		functions.PrettyPrint(functions.UpdateHost(args[0], apiHost))
+		flags := cmd.Flags()
+		functions.PrettyPrint(functions.UpdateHost(args[0], func(apiHost *models.ApiHost) *models.ApiHost {
+			if fileHost != nil {
+				return fileHost
+			}
+			if flags.Changed("endpoint") {
+				apiHost.EndpointIP = endpoint
+			}
+			if flags.Changed("name") {
+				apiHost.Name = name
+			}
+			if flags.Changed("listen_port") {
+				apiHost.ListenPort = listenPort
+			}
+			if flags.Changed("mtu") {
+				apiHost.MTU = mtu
+			}
+			if flags.Changed("static") {
+				apiHost.IsStatic = isStatic
+			}
+			if flags.Changed("default") {
+				apiHost.IsDefault = isDefault
+			}
+			if flags.Changed("labels") {
+				apiHost.Labels = parseLabels(labels)
+			}
+			return apiHost
+		}))
 	},
 }
 
+// parseLabels - parses comma separated key=value labels
+func parseLabels(value string) map[string]string {
+	tags := []string{}
+	if value != "" {
+		tags = strings.Split(value, ",")
+	}
+	parsed, invalid := models.LabelsFromTags(tags)
+	if len(invalid) > 0 {
+		log.Fatalf("invalid labels: %s", strings.Join(invalid, ", "))
+	}
+	return parsed
+}
+
 func init() {
 	hostUpdateCmd.Flags().StringVar(&apiHostFilePath, "file", "", "Path to host_definition.json")
 	hostUpdateCmd.Flags().StringVar(&endpoint, "endpoint", "", "Endpoint of the Host")
@@ -56,5 +91,6 @@ func init() {
 	hostUpdateCmd.Flags().IntVar(&mtu, "mtu", 0, "Host MTU size")
 	hostUpdateCmd.Flags().BoolVar(&isStatic, "static", false, "Make Host Static ?")
 	hostUpdateCmd.Flags().BoolVar(&isDefault, "default", false, "Make Host Default ?")
+	hostUpdateCmd.Flags().StringVar(&labels, "labels", "", "Comma separated key=value labels of the host, replaces its labels, empty to remove them")
 	rootCmd.AddCommand(hostUpdateCmd)
 }
@@ -0,0 +1,48 @@
+package network
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var networkApprovalCmd = &cobra.Command{
+	Use:   "approval [NETWORK NAME] [REQUIRED]",
+	Short: "Show the node approvals of a Network or set whether it requires approval",
+	Long: `Without REQUIRED, list the nodes of a Network approved or rejected by an admin, newest first.
+With REQUIRED set to true, the nodes of hosts joining the Network with an enrollment key or SSO stay pending
+until they are approved with nmctl node approve or rejected with nmctl node reject.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 2 {
+			required, err := strconv.ParseBool(args[1])
+			if err != nil {
+				log.Fatal(err)
+			}
+			functions.PrettyPrint(functions.SetApprovalPolicy(args[0], required))
+			return
+		}
+		approvals := functions.GetNodeApprovals(args[0])
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(approvals)
+		default:
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Node ID", "Host Name", "Decision", "User", "Time"})
+			for _, a := range *approvals {
+				table.Append([]string{a.NodeID, a.HostName, string(a.Decision), a.User, a.Time.Format(time.RFC3339)})
+			}
+			table.Render()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(networkApprovalCmd)
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"log"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var networkCloneCmd = &cobra.Command{
+	Use:   "clone [NETWORK NAME] [NEW NETWORK NAME]",
+	Short: "Create a Network with the settings of another Network",
+	Long: `Create a Network with the settings, pro settings, default ACL policy, custom DNS entries and
+enrollment keys of another Network. Custom DNS entries keep their offset in the new address ranges.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		request := &models.NetworkCloneRequest{
+			NetID:         args[1],
+			AddressRange:  address,
+			AddressRange6: address6,
+		}
+		if ipv6ULA {
+			request.AddressRange6 = models.ULAAddressRange6
+		}
+		if request.AddressRange == "" && request.AddressRange6 == "" {
+			log.Fatal("at least one of --ipv4_addr, --ipv6_addr and --ipv6_ula is required")
+		}
+		functions.PrettyPrint(functions.CloneNetwork(args[0], request))
+	},
+}
+
+func init() {
+	networkCloneCmd.Flags().StringVar(&address, "ipv4_addr", "", "IPv4 address range of the new network")
+	networkCloneCmd.Flags().StringVar(&address6, "ipv6_addr", "", "IPv6 address range of the new network")
+	networkCloneCmd.Flags().BoolVar(&ipv6ULA, "ipv6_ula", false, "Generate a unique local IPv6 address range for the new network")
+	networkCloneCmd.MarkFlagsMutuallyExclusive("ipv6_addr", "ipv6_ula")
+	rootCmd.AddCommand(networkCloneCmd)
+}
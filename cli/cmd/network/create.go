@@ -32,6 +32,11 @@ var networkCreateCmd = &cobra.Command{
 				network.AddressRange6 = address6
 				network.IsIPv6 = "yes"
 			}
+			if ipv6ULA {
+				network.AddressRange6 = models.ULAAddressRange6
+				network.IsIPv6 = "yes"
+			}
+			network.SecondaryRanges = secondaryRanges
 			if udpHolePunch {
 				network.DefaultUDPHolePunch = "yes"
 			}
@@ -47,6 +52,10 @@ var networkCreateCmd = &cobra.Command{
 			}
 			network.DefaultMTU = int32(defaultMTU)
 		}
+		if fromTemplate != "" {
+			functions.PrettyPrint(functions.CreateNetworkFromTemplate(network, fromTemplate))
+			return
+		}
 		functions.PrettyPrint(functions.CreateNetwork(network))
 	},
 }
@@ -57,6 +66,9 @@ func init() {
 	networkCreateCmd.MarkFlagsMutuallyExclusive("file", "name")
 	networkCreateCmd.Flags().StringVar(&address, "ipv4_addr", "", "IPv4 address of the network")
 	networkCreateCmd.Flags().StringVar(&address6, "ipv6_addr", "", "IPv6 address of the network")
+	networkCreateCmd.Flags().BoolVar(&ipv6ULA, "ipv6_ula", false, "Generate a unique local IPv6 address range for the network")
+	networkCreateCmd.MarkFlagsMutuallyExclusive("ipv6_addr", "ipv6_ula")
+	networkCreateCmd.Flags().StringSliceVar(&secondaryRanges, "secondary_ranges", nil, "Additional IPv4/IPv6 ranges used once the primary range is full")
 	networkCreateCmd.Flags().BoolVar(&udpHolePunch, "udp_hole_punch", false, "Enable UDP Hole Punching ?")
 	networkCreateCmd.Flags().BoolVar(&defaultACL, "default_acl", false, "Enable default Access Control List ?")
 	networkCreateCmd.Flags().StringVar(&defaultInterface, "interface", "", "Name of the network interface")
@@ -65,5 +77,6 @@ func init() {
 	networkCreateCmd.Flags().IntVar(&defaultKeepalive, "keep_alive", 20, "Keep Alive in seconds")
 	networkCreateCmd.Flags().IntVar(&defaultMTU, "mtu", 1280, "MTU size")
 	networkCreateCmd.Flags().BoolVar(&allowManualSignUp, "manual_signup", false, "Allow manual signup ?")
+	networkCreateCmd.Flags().StringVar(&fromTemplate, "from-template", "", "Name of the network template to take every setting but the name and address ranges from")
 	rootCmd.AddCommand(networkCreateCmd)
 }
@@ -5,6 +5,7 @@ var (
 	netID                     string
 	address                   string
 	address6                  string
+	ipv6ULA                   bool
 	udpHolePunch              bool
 	defaultACL                bool
 	defaultInterface          string
@@ -13,4 +14,13 @@ var (
 	defaultKeepalive          int
 	allowManualSignUp         bool
 	defaultMTU                int
+	reservationHostID         string
+	reservationHostName       string
+	reservationDescription    string
+	renumberAddress           string
+	renumberAddress6          string
+	renumberDryRun            bool
+	secondaryRanges           []string
+	fromTemplate              string
+	templateDescription       string
 )
@@ -0,0 +1,58 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var networkKeyRotationCmd = &cobra.Command{
+	Use:   "key_rotation [NETWORK NAME] [DAYS]",
+	Short: "Show or set the key rotation policy of a Network",
+	Long: `Without DAYS, show the key rotation policy of a Network and the key age of each of its hosts.
+With DAYS, rotate the wireguard keys of the hosts of the Network once they are older than DAYS days, 0 disables rotation.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 2 {
+			days, err := strconv.ParseInt(args[1], 10, 32)
+			if err != nil {
+				log.Fatal(err)
+			}
+			functions.PrettyPrint(functions.SetKeyRotationPolicy(args[0], int32(days)))
+			return
+		}
+		status := functions.GetKeyRotationStatus(args[0])
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(status)
+		default:
+			fmt.Printf("Network %s rotates keys every %d days (0 = never)\n", status.Network, status.IntervalDays)
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Host ID", "Host Name", "Keys Updated At", "Key Age (Days)", "Due At", "Overdue", "Rotation Requested At"})
+			for _, h := range status.Hosts {
+				table.Append([]string{h.HostID, h.HostName, formatTime(h.KeysUpdatedAt), strconv.Itoa(h.KeyAgeDays),
+					formatTime(h.DueAt), strconv.FormatBool(h.Overdue), formatTime(h.RotationRequestedAt)})
+			}
+			table.Render()
+		}
+	},
+}
+
+// formatTime - formats a time for a table, zero times are left empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func init() {
+	rootCmd.AddCommand(networkKeyRotationCmd)
+}
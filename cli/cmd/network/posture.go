@@ -0,0 +1,82 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	postureMinVersion    string
+	postureAllowedOS     string
+	postureFirewall      string
+	postureDenyDocker    bool
+	postureRequireDaemon bool
+	postureClear         bool
+)
+
+var networkPostureCmd = &cobra.Command{
+	Use:   "posture [NETWORK NAME]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Show or set the posture policy of a Network",
+	Long: `Without flags, show the posture policy of a Network and the nodes quarantined for failing it.
+With flags, replace the policy, e.g. nmctl network posture mynet --min_version v0.20.0 --firewall nftables --deny_docker
+Nodes of hosts failing the policy get no peers until their host complies, --clear removes the policy.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if postureClear {
+			functions.PrettyPrint(functions.SetPosturePolicy(args[0], &models.PosturePolicy{}))
+			return
+		}
+		flags := cmd.Flags()
+		if flags.Changed("min_version") || flags.Changed("allowed_os") || flags.Changed("firewall") ||
+			flags.Changed("deny_docker") || flags.Changed("require_daemon") {
+			policy := &models.PosturePolicy{
+				MinVersion:    postureMinVersion,
+				Firewall:      postureFirewall,
+				DenyDocker:    postureDenyDocker,
+				RequireDaemon: postureRequireDaemon,
+			}
+			if postureAllowedOS != "" {
+				policy.AllowedOS = strings.Split(postureAllowedOS, ",")
+			}
+			functions.PrettyPrint(functions.SetPosturePolicy(args[0], policy))
+			return
+		}
+		status := functions.GetPostureStatus(args[0])
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(status)
+		default:
+			if status.Policy == nil {
+				fmt.Printf("Network %s has no posture policy\n", status.Network)
+			} else {
+				fmt.Printf("Network %s requires min version %q, os %q, firewall %q, deny docker %t, require daemon %t\n",
+					status.Network, status.Policy.MinVersion, strings.Join(status.Policy.AllowedOS, ","),
+					status.Policy.Firewall, status.Policy.DenyDocker, status.Policy.RequireDaemon)
+			}
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Node ID", "Host Name", "Quarantined", "Violations"})
+			for _, n := range status.Nodes {
+				table.Append([]string{n.NodeID, n.HostName, strconv.FormatBool(n.Quarantined), strings.Join(n.Violations, "; ")})
+			}
+			table.Render()
+		}
+	},
+}
+
+func init() {
+	networkPostureCmd.Flags().StringVar(&postureMinVersion, "min_version", "", "Oldest netclient version allowed")
+	networkPostureCmd.Flags().StringVar(&postureAllowedOS, "allowed_os", "", "Comma-separated list of operating systems allowed")
+	networkPostureCmd.Flags().StringVar(&postureFirewall, "firewall", "", "Firewall hosts must use, e.g. nftables")
+	networkPostureCmd.Flags().BoolVar(&postureDenyDocker, "deny_docker", false, "Quarantine hosts running in docker ?")
+	networkPostureCmd.Flags().BoolVar(&postureRequireDaemon, "require_daemon", false, "Quarantine hosts not running the netclient as a daemon ?")
+	networkPostureCmd.Flags().BoolVar(&postureClear, "clear", false, "Remove the posture policy")
+	rootCmd.AddCommand(networkPostureCmd)
+}
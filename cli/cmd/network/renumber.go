@@ -0,0 +1,54 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var networkRenumberCmd = &cobra.Command{
+	Use:   "renumber [NETWORK NAME]",
+	Short: "Move a Network to new address ranges",
+	Long: `Move a Network to new address ranges, the addresses of its nodes, ext clients, DNS entries,
+reservations and gateway ranges are mapped to the new ranges and pushed to the hosts.
+Use --dry_run to only show the plan.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if renumberAddress == "" && renumberAddress6 == "" {
+			log.Fatal("at least one of --ipv4_addr and --ipv6_addr is required")
+		}
+		plan := functions.RenumberNetwork(args[0], &models.RenumberRequest{
+			AddressRange:  renumberAddress,
+			AddressRange6: renumberAddress6,
+		}, renumberDryRun)
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(plan)
+		default:
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Kind", "ID", "Name", "Old", "New"})
+			for _, change := range plan.Changes {
+				table.Append([]string{string(change.Kind), change.ID, change.Name, change.Old, change.New})
+			}
+			table.Render()
+			if plan.Applied {
+				fmt.Printf("network %s renumbered\n", plan.Network)
+			} else {
+				fmt.Printf("dry run, network %s is unchanged\n", plan.Network)
+			}
+		}
+	},
+}
+
+func init() {
+	networkRenumberCmd.Flags().StringVar(&renumberAddress, "ipv4_addr", "", "New IPv4 address range of the network")
+	networkRenumberCmd.Flags().StringVar(&renumberAddress6, "ipv6_addr", "", "New IPv6 address range of the network")
+	networkRenumberCmd.Flags().BoolVar(&renumberDryRun, "dry_run", false, "Only show how the addresses would change")
+	rootCmd.AddCommand(networkRenumberCmd)
+}
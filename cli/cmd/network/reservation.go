@@ -0,0 +1,15 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var networkReservationCmd = &cobra.Command{
+	Use:   "reservation",
+	Short: "Manage address reservations of a Network",
+	Long:  `Manage addresses of a Network that are only handed out to the host they are reserved for`,
+}
+
+func init() {
+	rootCmd.AddCommand(networkReservationCmd)
+}
@@ -0,0 +1,30 @@
+package network
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var networkReservationCreateCmd = &cobra.Command{
+	Use:   "create [NETWORK NAME] [ADDRESS]",
+	Short: "Reserve an address of a Network for a host",
+	Long:  `Reserve an address of a Network for a host, the host is given the address when it joins the network`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.CreateAddressReservation(args[0], &models.AddressReservation{
+			Address:     args[1],
+			HostID:      reservationHostID,
+			HostName:    reservationHostName,
+			Description: reservationDescription,
+		}))
+	},
+}
+
+func init() {
+	networkReservationCreateCmd.Flags().StringVar(&reservationHostID, "host_id", "", "ID of the host the address is reserved for")
+	networkReservationCreateCmd.Flags().StringVar(&reservationHostName, "host_name", "", "Name of the host the address is reserved for")
+	networkReservationCreateCmd.MarkFlagsMutuallyExclusive("host_id", "host_name")
+	networkReservationCreateCmd.Flags().StringVar(&reservationDescription, "description", "", "Description of the reservation")
+	networkReservationCmd.AddCommand(networkReservationCreateCmd)
+}
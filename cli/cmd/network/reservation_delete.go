@@ -0,0 +1,22 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var networkReservationDeleteCmd = &cobra.Command{
+	Use:   "delete [NETWORK NAME] [ADDRESS]",
+	Short: "Delete an address reservation of a Network",
+	Long:  `Delete an address reservation of a Network, a node already using the address keeps it`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(*functions.DeleteAddressReservation(args[0], args[1]))
+	},
+}
+
+func init() {
+	networkReservationCmd.AddCommand(networkReservationDeleteCmd)
+}
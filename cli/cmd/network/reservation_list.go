@@ -0,0 +1,35 @@
+package network
+
+import (
+	"os"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var networkReservationListCmd = &cobra.Command{
+	Use:   "list [NETWORK NAME]",
+	Short: "List address reservations of a Network",
+	Long:  `List address reservations of a Network`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		reservations := functions.GetAddressReservations(args[0])
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(reservations)
+		default:
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Address", "Host ID", "Host Name", "Description"})
+			for _, reservation := range *reservations {
+				table.Append([]string{reservation.Address, reservation.HostID, reservation.HostName, reservation.Description})
+			}
+			table.Render()
+		}
+	},
+}
+
+func init() {
+	networkReservationCmd.AddCommand(networkReservationListCmd)
+}
@@ -0,0 +1,15 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var networkReservedRangeCmd = &cobra.Command{
+	Use:   "reserved_range",
+	Short: "Manage reserved address ranges of a Network",
+	Long:  `Manage address ranges of a Network that are never handed out to nodes or ext clients`,
+}
+
+func init() {
+	rootCmd.AddCommand(networkReservedRangeCmd)
+}
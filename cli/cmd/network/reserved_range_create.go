@@ -0,0 +1,21 @@
+package network
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var networkReservedRangeCreateCmd = &cobra.Command{
+	Use:   "create [NETWORK NAME] [START ADDRESS] [END ADDRESS]",
+	Short: "Reserve an address range of a Network",
+	Long:  `Keep an address range of a Network from being handed out, nodes and ext clients already using its addresses keep them`,
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.ReserveAddressRange(args[0], &models.AddressRange{Start: args[1], End: args[2]}))
+	},
+}
+
+func init() {
+	networkReservedRangeCmd.AddCommand(networkReservedRangeCreateCmd)
+}
@@ -0,0 +1,21 @@
+package network
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var networkReservedRangeDeleteCmd = &cobra.Command{
+	Use:   "delete [NETWORK NAME] [START ADDRESS] [END ADDRESS]",
+	Short: "Delete a reserved address range of a Network",
+	Long:  `Let a reserved address range of a Network be handed out again`,
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.UnreserveAddressRange(args[0], &models.AddressRange{Start: args[1], End: args[2]}))
+	},
+}
+
+func init() {
+	networkReservedRangeCmd.AddCommand(networkReservedRangeDeleteCmd)
+}
@@ -0,0 +1,35 @@
+package network
+
+import (
+	"os"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var networkReservedRangeListCmd = &cobra.Command{
+	Use:   "list [NETWORK NAME]",
+	Short: "List reserved address ranges of a Network",
+	Long:  `List reserved address ranges of a Network`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ranges := functions.GetReservedAddressRanges(args[0])
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(ranges)
+		default:
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Start", "End"})
+			for _, addressRange := range *ranges {
+				table.Append([]string{addressRange.Start, addressRange.End})
+			}
+			table.Render()
+		}
+	},
+}
+
+func init() {
+	networkReservedRangeCmd.AddCommand(networkReservedRangeListCmd)
+}
@@ -0,0 +1,21 @@
+package network
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var networkSecondaryRangesCmd = &cobra.Command{
+	Use:   "secondary_ranges [NETWORK NAME] [RANGE...]",
+	Short: "Set the secondary address ranges of a Network",
+	Long: `Set the secondary IPv4/IPv6 address ranges of a Network, addresses are allocated from them once the primary range is full.
+Ranges that are left out are removed, passing no ranges removes all of them.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.UpdateNetworkRanges(args[0], args[1:]))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(networkSecondaryRangesCmd)
+}
@@ -0,0 +1,15 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var networkTemplateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage Network templates",
+	Long:  `Manage the templates new Networks can be created from with "network create --from-template"`,
+}
+
+func init() {
+	rootCmd.AddCommand(networkTemplateCmd)
+}
@@ -0,0 +1,33 @@
+package network
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var networkTemplateCreateCmd = &cobra.Command{
+	Use:   "create [TEMPLATE DEFINITION FILE]",
+	Short: "Create or replace a Network template from a file",
+	Long:  `Create or replace a Network template from a json file, see "network template get" for the format`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(args[0])
+		if err != nil {
+			log.Fatal("Error when opening file: ", err)
+		}
+		template := &models.NetworkTemplate{}
+		if err := json.Unmarshal(content, template); err != nil {
+			log.Fatal(err)
+		}
+		functions.PrettyPrint(functions.CreateNetworkTemplate(template))
+	},
+}
+
+func init() {
+	networkTemplateCmd.AddCommand(networkTemplateCreateCmd)
+}
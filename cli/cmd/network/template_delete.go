@@ -0,0 +1,22 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var networkTemplateDeleteCmd = &cobra.Command{
+	Use:   "delete [TEMPLATE NAME]",
+	Short: "Delete a Network template",
+	Long:  `Delete a Network template, Networks created from it are not changed`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(*functions.DeleteNetworkTemplate(args[0]))
+	},
+}
+
+func init() {
+	networkTemplateCmd.AddCommand(networkTemplateDeleteCmd)
+}
@@ -0,0 +1,20 @@
+package network
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var networkTemplateGetCmd = &cobra.Command{
+	Use:   "get [TEMPLATE NAME]",
+	Short: "Get a Network template",
+	Long:  `Get a Network template`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.GetNetworkTemplate(args[0]))
+	},
+}
+
+func init() {
+	networkTemplateCmd.AddCommand(networkTemplateGetCmd)
+}
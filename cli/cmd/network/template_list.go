@@ -0,0 +1,37 @@
+package network
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var networkTemplateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Network templates",
+	Long:  `List Network templates`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		templates := functions.GetNetworkTemplates()
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(templates)
+		default:
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Name", "Description", "DNS Entries", "Enrollment Keys"})
+			for _, template := range *templates {
+				table.Append([]string{template.Name, template.Description,
+					strconv.Itoa(len(template.DNS)), strconv.Itoa(len(template.EnrollmentKeys))})
+			}
+			table.Render()
+		}
+	},
+}
+
+func init() {
+	networkTemplateCmd.AddCommand(networkTemplateListCmd)
+}
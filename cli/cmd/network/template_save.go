@@ -0,0 +1,25 @@
+package network
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var networkTemplateSaveCmd = &cobra.Command{
+	Use:   "save [NETWORK NAME] [TEMPLATE NAME]",
+	Short: "Save a Network as a template",
+	Long:  `Save the settings, custom DNS entries and enrollment keys of a Network as a template`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.SaveNetworkTemplate(args[0], &models.NetworkTemplateRequest{
+			Name:        args[1],
+			Description: templateDescription,
+		}))
+	},
+}
+
+func init() {
+	networkTemplateSaveCmd.Flags().StringVar(&templateDescription, "description", "", "Description of the template")
+	networkTemplateCmd.AddCommand(networkTemplateSaveCmd)
+}
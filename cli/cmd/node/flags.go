@@ -18,4 +18,6 @@ var (
 	defaultACL             bool
 	dnsOn                  bool
 	disconnect             bool
+	selector               string
+	labels                 string
 )
@@ -16,12 +16,17 @@ var nodeListCmd = &cobra.Command{
 	Use:   "list",
 	Args:  cobra.NoArgs,
 	Short: "List all nodes",
-	Long:  `List all nodes`,
+	Long:  `List all nodes, or with --selector the nodes whose labels or host labels match a selector like env=prod,role!=db`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var data []models.ApiNode
-		if networkName != "" {
+		switch {
+		case selector != "" && networkName != "":
+			data = *functions.GetNodesBySelector(selector, networkName)
+		case selector != "":
+			data = *functions.GetNodesBySelector(selector)
+		case networkName != "":
 			data = *functions.GetNodes(networkName)
-		} else {
+		default:
 			data = *functions.GetNodes()
 		}
 		switch commons.OutputFormat {
@@ -29,7 +34,7 @@ var nodeListCmd = &cobra.Command{
 			functions.PrettyPrint(data)
 		default:
 			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"ID", "Addresses", "Network", "Egress", "Ingress", "Relay"})
+			table.SetHeader([]string{"ID", "Addresses", "Network", "Egress", "Ingress", "Relay", "Labels"})
 			for _, d := range data {
 				addresses := ""
 				if d.Address != "" {
@@ -42,7 +47,8 @@ var nodeListCmd = &cobra.Command{
 					addresses += d.Address6
 				}
 				table.Append([]string{d.ID, addresses, d.Network,
-					strconv.FormatBool(d.IsEgressGateway), strconv.FormatBool(d.IsIngressGateway), strconv.FormatBool(d.IsRelay)})
+					strconv.FormatBool(d.IsEgressGateway), strconv.FormatBool(d.IsIngressGateway), strconv.FormatBool(d.IsRelay),
+					models.FormatLabels(d.Labels)})
 			}
 			table.Render()
 		}
@@ -51,5 +57,6 @@ var nodeListCmd = &cobra.Command{
 
 func init() {
 	nodeListCmd.Flags().StringVar(&networkName, "network", "", "Network name specifier")
+	nodeListCmd.Flags().StringVar(&selector, "selector", "", "Only list nodes whose labels or host labels match the selector")
 	rootCmd.AddCommand(nodeListCmd)
 }
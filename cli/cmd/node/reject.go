@@ -0,0 +1,20 @@
+package node
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var nodeRejectCmd = &cobra.Command{
+	Use:   "reject [NETWORK NAME] [NODE ID]",
+	Args:  cobra.ExactArgs(2),
+	Short: "Reject a node pending approval",
+	Long:  `Reject a node pending approval, the node is removed from the network`,
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.RejectNode(args[0], args[1]))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nodeRejectCmd)
+}
@@ -1,19 +1,18 @@
 package node
 
 import (
-	"fmt"
-
 	"github.com/gravitl/netmaker/cli/functions"
 	"github.com/spf13/cobra"
 )
 
 var nodeUncordonCmd = &cobra.Command{
-	Use:   "uncordon [NETWORK NAME] [NODE ID]",
-	Args:  cobra.ExactArgs(2),
-	Short: "Get a node by ID",
-	Long:  `Get a node by ID`,
+	Use:     "uncordon [NETWORK NAME] [NODE ID]",
+	Aliases: []string{"approve"},
+	Args:    cobra.ExactArgs(2),
+	Short:   "Approve a node pending approval",
+	Long:    `Approve a node pending approval, it gets its peers and becomes a peer of the other nodes of the network`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(*functions.UncordonNode(args[0], args[1]))
+		functions.PrettyPrint(functions.ApproveNode(args[0], args[1]))
 	},
 }
 
@@ -18,41 +18,82 @@ var nodeUpdateCmd = &cobra.Command{
 	Long:  `Update a Node`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
-			node        = &models.ApiNode{}
 			networkName = args[0]
 			nodeID      = args[1]
+			fileNode    *models.ApiNode
+			flags       = cmd.Flags()
 		)
 		if nodeDefinitionFilePath != "" {
 			content, err := os.ReadFile(nodeDefinitionFilePath)
 			if err != nil {
 				log.Fatal("Error when opening file: ", err)
 			}
-			if err := json.Unmarshal(content, node); err != nil {
+			fileNode = &models.ApiNode{}
+			if err := json.Unmarshal(content, fileNode); err != nil {
 				log.Fatal(err)
 			}
-		} else {
-			node.Address = address
-			node.Address6 = address6
-			node.LocalAddress = localAddress
-			node.PersistentKeepalive = int32(keepAlive)
-			if relayedNodes != "" {
+		}
+		functions.PrettyPrint(functions.UpdateNode(networkName, nodeID, func(current *models.NodeGet) *models.ApiNode {
+			if fileNode != nil {
+				fileNode.HostID = current.Host.ID.String()
+				return fileNode
+			}
+			node := current.Node.ConvertToAPINode()
+			if flags.Changed("ipv4_addr") {
+				node.Address = address
+			}
+			if flags.Changed("ipv6_addr") {
+				node.Address6 = address6
+			}
+			if flags.Changed("local_addr") {
+				node.LocalAddress = localAddress
+			}
+			if flags.Changed("keep_alive") {
+				node.PersistentKeepalive = int32(keepAlive)
+			}
+			if flags.Changed("relayed_nodes") {
 				node.RelayedNodes = strings.Split(relayedNodes, ",")
 			}
-			if egressGatewayRanges != "" {
+			if flags.Changed("egress_addrs") {
 				node.EgressGatewayRanges = strings.Split(egressGatewayRanges, ",")
 			}
-			node.ExpirationDateTime = int64(expirationDateTime)
-			if defaultACL {
-				node.DefaultACL = "yes"
+			if flags.Changed("expiry") {
+				node.ExpirationDateTime = int64(expirationDateTime)
 			}
-			node.DNSOn = dnsOn
-			node.Connected = !disconnect
-		}
-		node.HostID = functions.GetNodeByID(networkName, nodeID).Host.ID.String()
-		functions.PrettyPrint(functions.UpdateNode(networkName, nodeID, node))
+			if flags.Changed("acl") {
+				node.DefaultACL = "no"
+				if defaultACL {
+					node.DefaultACL = "yes"
+				}
+			}
+			if flags.Changed("dns") {
+				node.DNSOn = dnsOn
+			}
+			if flags.Changed("disconnect") {
+				node.Connected = !disconnect
+			}
+			if flags.Changed("labels") {
+				node.Labels = parseLabels(labels)
+			}
+			node.HostID = current.Host.ID.String()
+			return node
+		}))
 	},
 }
 
+// parseLabels - parses comma separated key=value labels
+func parseLabels(value string) map[string]string {
+	tags := []string{}
+	if value != "" {
+		tags = strings.Split(value, ",")
+	}
+	parsed, invalid := models.LabelsFromTags(tags)
+	if len(invalid) > 0 {
+		log.Fatalf("invalid labels: %s", strings.Join(invalid, ", "))
+	}
+	return parsed
+}
+
 func init() {
 	nodeUpdateCmd.Flags().StringVar(&nodeDefinitionFilePath, "file", "", "Filepath of updated node definition in JSON")
 	nodeUpdateCmd.Flags().StringVar(&address, "ipv4_addr", "", "IPv4 address of the node")
@@ -68,5 +109,6 @@ func init() {
 	nodeUpdateCmd.Flags().BoolVar(&defaultACL, "acl", false, "Enable default ACL ?")
 	nodeUpdateCmd.Flags().BoolVar(&dnsOn, "dns", false, "Setup DNS entries for peers locally ?")
 	nodeUpdateCmd.Flags().BoolVar(&disconnect, "disconnect", false, "Disconnect from the network ?")
+	nodeUpdateCmd.Flags().StringVar(&labels, "labels", "", "Comma separated key=value labels of the node, replaces its labels, empty to remove them")
 	rootCmd.AddCommand(nodeUpdateCmd)
 }
@@ -0,0 +1,28 @@
+package peering
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var peeringCreateCmd = &cobra.Command{
+	Use:   "create [NETWORK A] [GATEWAY NODE ID A] [NETWORK B] [GATEWAY NODE ID B]",
+	Args:  cobra.ExactArgs(4),
+	Short: "Peer two networks",
+	Long: `Peer two networks through a host that has a node in both, the two gateway nodes must belong to that host.
+Each gateway routes the address ranges of the other network to the nodes of its network that its ACLs let reach it.
+The peering is removed when either gateway node or network is deleted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.CreateNetworkPeering(&models.NetworkPeeringRequest{
+			NetworkA: args[0],
+			GatewayA: args[1],
+			NetworkB: args[2],
+			GatewayB: args[3],
+		}))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(peeringCreateCmd)
+}
@@ -0,0 +1,22 @@
+package peering
+
+import (
+	"fmt"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var peeringDeleteCmd = &cobra.Command{
+	Use:   "delete [PEERING ID]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Delete a network peering",
+	Long:  `Delete a network peering`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(*functions.DeleteNetworkPeering(args[0]))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(peeringDeleteCmd)
+}
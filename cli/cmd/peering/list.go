@@ -0,0 +1,37 @@
+package peering
+
+import (
+	"os"
+	"time"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var peeringListCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "List network peerings",
+	Long:  `List network peerings`,
+	Run: func(cmd *cobra.Command, args []string) {
+		peerings := functions.GetNetworkPeerings()
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(peerings)
+		default:
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"ID", "Network A", "Gateway A", "Network B", "Gateway B", "Created At"})
+			for _, p := range *peerings {
+				table.Append([]string{p.ID, p.NetworkA, p.GatewayA, p.NetworkB, p.GatewayB,
+					time.Unix(p.CreatedAt, 0).Format(time.RFC3339)})
+			}
+			table.Render()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(peeringListCmd)
+}
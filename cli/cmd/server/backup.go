@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var serverBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Args:  cobra.NoArgs,
+	Short: "Backup the server to a file",
+	Long: `Export every table of the server, including its UUID, traffic keys, JWT secret and certs, to a versioned archive.
+The archive is encrypted with the given passphrase, an unencrypted archive is only written with --insecure.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if backupPassphrase == "" && !backupInsecure {
+			log.Fatal("a passphrase is required to encrypt the backup, use --insecure to write an unencrypted archive")
+		}
+		archive := functions.BackupServer(&models.BackupRequest{Passphrase: backupPassphrase, Insecure: backupInsecure})
+		data, err := json.Marshal(archive)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(backupFilePath, data, 0600); err != nil {
+			log.Fatal("Error writing backup: ", err)
+		}
+		fmt.Printf("backup written to %s (encrypted: %t)\n", backupFilePath, archive.Encrypted)
+	},
+}
+
+func init() {
+	serverBackupCmd.Flags().StringVar(&backupFilePath, "file", "", "Path to write the backup archive to")
+	serverBackupCmd.MarkFlagRequired("file")
+	serverBackupCmd.Flags().StringVar(&backupPassphrase, "passphrase", "", "Passphrase used to encrypt the backup archive")
+	serverBackupCmd.Flags().BoolVar(&backupInsecure, "insecure", false, "Write an unencrypted archive holding the server's secrets in plain text when no passphrase is given")
+	rootCmd.AddCommand(serverBackupCmd)
+}
@@ -0,0 +1,7 @@
+package server
+
+var (
+	backupFilePath   string
+	backupPassphrase string
+	backupInsecure   bool
+)
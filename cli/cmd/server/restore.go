@@ -0,0 +1,37 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/models"
+	"github.com/spf13/cobra"
+)
+
+var serverRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Args:  cobra.NoArgs,
+	Short: "Restore the server from a backup file",
+	Long: `Replace the contents of the server with a backup archive created by "server backup".
+Peer updates are pushed to all hosts once restored. Users must log in again afterwards.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(backupFilePath)
+		if err != nil {
+			log.Fatal("Error when opening file: ", err)
+		}
+		archive := &models.BackupArchive{}
+		if err := json.Unmarshal(content, archive); err != nil {
+			log.Fatal(err)
+		}
+		functions.PrettyPrint(functions.RestoreServer(archive, backupPassphrase))
+	},
+}
+
+func init() {
+	serverRestoreCmd.Flags().StringVar(&backupFilePath, "file", "", "Path to the backup archive")
+	serverRestoreCmd.MarkFlagRequired("file")
+	serverRestoreCmd.Flags().StringVar(&backupPassphrase, "passphrase", "", "Passphrase used to encrypt the backup archive")
+	rootCmd.AddCommand(serverRestoreCmd)
+}
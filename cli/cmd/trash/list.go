@@ -0,0 +1,37 @@
+package trash
+
+import (
+	"os"
+	"time"
+
+	"github.com/gravitl/netmaker/cli/cmd/commons"
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "List deleted objects that can still be restored",
+	Long:  `List deleted networks, hosts and ext clients that can still be restored`,
+	Run: func(cmd *cobra.Command, args []string) {
+		items := functions.GetTrash()
+		switch commons.OutputFormat {
+		case commons.JsonOutput:
+			functions.PrettyPrint(items)
+		default:
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"ID", "Kind", "Name", "Network", "Deleted By", "Deleted At", "Expires At"})
+			for _, item := range *items {
+				table.Append([]string{item.ID, string(item.Kind), item.Name, item.Network, item.DeletedBy,
+					item.DeletedAt.Format(time.RFC3339), item.ExpiresAt.Format(time.RFC3339)})
+			}
+			table.Render()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trashListCmd)
+}
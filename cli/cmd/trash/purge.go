@@ -0,0 +1,20 @@
+package trash
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge [TRASH ID]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Permanently delete an object from the trash",
+	Long:  `Permanently delete an object from the trash`,
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.PurgeTrash(args[0]))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trashPurgeCmd)
+}
@@ -0,0 +1,20 @@
+package trash
+
+import (
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/spf13/cobra"
+)
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore [TRASH ID]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Restore a deleted object",
+	Long:  `Restore a deleted network, host or ext client with its original addresses, keys, ACL rows and DNS entries`,
+	Run: func(cmd *cobra.Command, args []string) {
+		functions.PrettyPrint(functions.RestoreTrash(args[0]))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trashRestoreCmd)
+}
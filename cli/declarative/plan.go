@@ -0,0 +1,762 @@
+package declarative
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/logic/acls"
+	"github.com/gravitl/netmaker/models"
+)
+
+// Action - what a change does on the server
+type Action string
+
+const (
+	// ActionCreate - something is added
+	ActionCreate Action = "create"
+	// ActionUpdate - something is changed in place, or replaced when the server cannot change it in place
+	ActionUpdate Action = "update"
+	// ActionDelete - something is removed
+	ActionDelete Action = "delete"
+)
+
+// Change - one difference between a spec and the server
+type Change struct {
+	Action  Action `json:"action"`
+	Kind    string `json:"kind"`
+	Network string `json:"network"`
+	Name    string `json:"name"`
+	Detail  string `json:"detail,omitempty"`
+	apply   func()
+}
+
+// Plan - the changes that bring the server to the state of a spec, in the order they are applied
+type Plan struct {
+	Changes []Change `json:"changes"`
+	// Warnings - differences that cannot be applied through the API, such as settings that are fixed at creation
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Plan.Apply - applies the changes in order, progress is called after each change
+func (p *Plan) Apply(progress func(Change)) {
+	for _, change := range p.Changes {
+		change.apply()
+		progress(change)
+	}
+}
+
+// phase - changes are applied phase by phase so that everything a change relies on is in place before it
+type phase int
+
+const (
+	phaseNetworks phase = iota
+	phaseJoinHosts
+	phaseDeleteExtClients
+	phaseDeleteGateways
+	phaseLeaveHosts
+	phaseGateways
+	phaseACLs
+	phaseDNS
+	phaseExtClients
+	phaseEnrollmentKeys
+	phaseCount
+)
+
+type planner struct {
+	state    *State
+	phases   [phaseCount][]Change
+	warnings []string
+}
+
+// BuildPlan - compares a spec with the live state of the server
+func BuildPlan(spec *Spec, state *State) (*Plan, error) {
+	p := &planner{state: state}
+	for i := range spec.Networks {
+		if err := p.planNetwork(&spec.Networks[i]); err != nil {
+			return nil, fmt.Errorf("network %s: %w", spec.Networks[i].Name, err)
+		}
+	}
+	plan := &Plan{Changes: []Change{}, Warnings: p.warnings}
+	for _, changes := range p.phases {
+		sortChanges(changes)
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	return plan, nil
+}
+
+func (p *planner) add(ph phase, change Change) {
+	p.phases[ph] = append(p.phases[ph], change)
+}
+
+func (p *planner) planNetwork(spec *NetworkSpec) error {
+	live := p.state.Networks[spec.Name]
+	if live == nil {
+		p.add(phaseNetworks, Change{
+			Action:  ActionCreate,
+			Kind:    "network",
+			Network: spec.Name,
+			Name:    spec.Name,
+			Detail:  strings.Trim(spec.AddressRange+" "+spec.AddressRange6, " "),
+			apply:   func() { createNetwork(spec) },
+		})
+	} else if err := p.planNetworkSettings(spec, live); err != nil {
+		return err
+	}
+	members, err := p.planHosts(spec, live)
+	if err != nil {
+		return err
+	}
+	resolve := func(ref string) (models.ApiHost, error) {
+		host, ok := p.state.host(ref)
+		if !ok {
+			return host, fmt.Errorf("unknown host %s", ref)
+		}
+		if !members[host.ID] {
+			return host, fmt.Errorf("host %s is not part of the network, add it to hosts", ref)
+		}
+		return host, nil
+	}
+	for _, plan := range []func(*NetworkSpec, *NetworkState, func(string) (models.ApiHost, error)) error{
+		p.planEgress, p.planIngress, p.planRelays, p.planACLs, p.planExtClients,
+	} {
+		if err := plan(spec, live, resolve); err != nil {
+			return err
+		}
+	}
+	p.planDNS(spec, live)
+	p.planEnrollmentKeys(spec)
+	return nil
+}
+
+func createNetwork(spec *NetworkSpec) {
+	network := &models.Network{
+		NetID:             spec.Name,
+		AddressRange:      spec.AddressRange,
+		AddressRange6:     spec.AddressRange6,
+		DefaultACL:        spec.DefaultACL,
+		DefaultKeepalive:  spec.DefaultKeepalive,
+		DefaultMTU:        spec.DefaultMTU,
+		DefaultListenPort: spec.DefaultListenPort,
+		AllowManualSignUp: spec.AllowManualSignUp,
+		NodeLimit:         spec.NodeLimit,
+	}
+	if spec.Template != "" {
+		functions.CreateNetworkFromTemplate(network, spec.Template)
+		return
+	}
+	functions.CreateNetwork(network)
+}
+
+// planner.planNetworkSettings - only the node limit of an existing network can be changed, other settings and the
+// address ranges are fixed once the network exists
+func (p *planner) planNetworkSettings(spec *NetworkSpec, live *NetworkState) error {
+	network := live.Network
+	if !sameRange(spec.AddressRange, network.AddressRange) || !sameRange(spec.AddressRange6, network.AddressRange6) {
+		return fmt.Errorf("the address ranges differ from %s %s, use nmctl network renumber to move the network",
+			network.AddressRange, network.AddressRange6)
+	}
+	fixed := []struct {
+		name          string
+		desired, live string
+	}{
+		{"defaultacl", spec.DefaultACL, network.DefaultACL},
+		{"allowmanualsignup", spec.AllowManualSignUp, network.AllowManualSignUp},
+		{"defaultkeepalive", intSetting(spec.DefaultKeepalive), intSetting(network.DefaultKeepalive)},
+		{"defaultmtu", intSetting(spec.DefaultMTU), intSetting(network.DefaultMTU)},
+		{"defaultlistenport", intSetting(spec.DefaultListenPort), intSetting(network.DefaultListenPort)},
+	}
+	for _, setting := range fixed {
+		if setting.desired != "" && setting.desired != setting.live {
+			p.warnings = append(p.warnings, fmt.Sprintf("network %s: %s is %s on the server and can only be set when the network is created",
+				spec.Name, setting.name, setting.live))
+		}
+	}
+	if spec.NodeLimit != 0 && spec.NodeLimit != network.NodeLimit {
+		p.add(phaseNetworks, Change{
+			Action:  ActionUpdate,
+			Kind:    "network",
+			Network: spec.Name,
+			Name:    spec.Name,
+			Detail:  fmt.Sprintf("nodelimit %d -> %d", network.NodeLimit, spec.NodeLimit),
+			apply:   func() { functions.UpdateNetworkNodeLimit(spec.Name, spec.NodeLimit) },
+		})
+	}
+	return nil
+}
+
+// planner.planHosts - joins and removes hosts, returns the ids of the hosts that are part of the network once the
+// plan is applied
+func (p *planner) planHosts(spec *NetworkSpec, live *NetworkState) (map[string]bool, error) {
+	members := map[string]bool{}
+	if live != nil {
+		for _, node := range live.Nodes {
+			members[node.HostID] = true
+		}
+	}
+	if spec.Hosts == nil {
+		return members, nil
+	}
+	desired := map[string]bool{}
+	for _, ref := range spec.Hosts {
+		host, ok := p.state.host(ref)
+		if !ok {
+			return nil, fmt.Errorf("unknown host %s", ref)
+		}
+		desired[host.ID] = true
+		if members[host.ID] {
+			continue
+		}
+		members[host.ID] = true
+		p.add(phaseJoinHosts, Change{
+			Action:  ActionCreate,
+			Kind:    "host",
+			Network: spec.Name,
+			Name:    host.Name,
+			apply:   func() { functions.AddHostToNetwork(host.ID, spec.Name) },
+		})
+	}
+	for _, host := range p.state.Hosts {
+		if !members[host.ID] || desired[host.ID] {
+			continue
+		}
+		host := host
+		delete(members, host.ID)
+		p.add(phaseLeaveHosts, Change{
+			Action:  ActionDelete,
+			Kind:    "host",
+			Network: spec.Name,
+			Name:    host.Name,
+			apply:   func() { functions.DeleteHostFromNetwork(host.ID, spec.Name) },
+		})
+	}
+	return members, nil
+}
+
+func (p *planner) planEgress(spec *NetworkSpec, live *NetworkState, resolve func(string) (models.ApiHost, error)) error {
+	if spec.Egress == nil {
+		return nil
+	}
+	desired := map[string]bool{}
+	for _, egress := range spec.Egress {
+		egress := egress
+		host, err := resolve(egress.Host)
+		if err != nil {
+			return fmt.Errorf("egress gateway: %w", err)
+		}
+		desired[host.ID] = true
+		ranges, err := normalizeRanges(egress.Ranges)
+		if err != nil {
+			return fmt.Errorf("egress gateway %s: %w", egress.Host, err)
+		}
+		nat := egress.NAT == nil || *egress.NAT
+		node, exists := live.node(host)
+		action := ActionCreate
+		if exists && node.IsEgressGateway {
+			liveRanges, _ := normalizeRanges(node.EgressGatewayRanges)
+			if strings.Join(liveRanges, ",") == strings.Join(ranges, ",") && node.EgressGatewayNatEnabled == nat {
+				continue
+			}
+			action = ActionUpdate
+		}
+		p.add(phaseGateways, Change{
+			Action:  action,
+			Kind:    "egress",
+			Network: spec.Name,
+			Name:    host.Name,
+			Detail:  fmt.Sprintf("ranges %s nat %t", strings.Join(ranges, ","), nat),
+			apply: func() {
+				nodeID := p.state.liveNodeID(spec.Name, egress.Host)
+				natEnabled := "no"
+				if nat {
+					natEnabled = "yes"
+				}
+				functions.CreateEgress(spec.Name, nodeID, &models.EgressGatewayRequest{
+					NodeID:     nodeID,
+					NetID:      spec.Name,
+					NatEnabled: natEnabled,
+					Ranges:     ranges,
+				})
+			},
+		})
+	}
+	for _, node := range nodesOf(live) {
+		if node.IsEgressGateway && !desired[node.HostID] {
+			node := node
+			p.add(phaseDeleteGateways, Change{
+				Action:  ActionDelete,
+				Kind:    "egress",
+				Network: spec.Name,
+				Name:    p.state.hostName(live.Nodes, node.ID),
+				apply:   func() { functions.DeleteEgress(spec.Name, node.ID) },
+			})
+		}
+	}
+	return nil
+}
+
+func (p *planner) planIngress(spec *NetworkSpec, live *NetworkState, resolve func(string) (models.ApiHost, error)) error {
+	if spec.Ingress == nil {
+		return nil
+	}
+	desired := map[string]bool{}
+	for _, ingress := range spec.Ingress {
+		ingress := ingress
+		host, err := resolve(ingress.Host)
+		if err != nil {
+			return fmt.Errorf("ingress gateway: %w", err)
+		}
+		desired[host.ID] = true
+		node, exists := live.node(host)
+		change := Change{
+			Action:  ActionCreate,
+			Kind:    "ingress",
+			Network: spec.Name,
+			Name:    host.Name,
+			Detail:  fmt.Sprintf("failover %t", ingress.Failover),
+			apply: func() {
+				functions.CreateIngress(spec.Name, p.state.liveNodeID(spec.Name, ingress.Host), ingress.Failover)
+			},
+		}
+		if exists && node.IsIngressGateway {
+			if node.Failover == ingress.Failover {
+				continue
+			}
+			// the failover setting of an ingress gateway is only set when it is created
+			change.Action = ActionUpdate
+			change.apply = func() {
+				functions.DeleteIngress(spec.Name, node.ID)
+				functions.CreateIngress(spec.Name, node.ID, ingress.Failover)
+			}
+		}
+		p.add(phaseGateways, change)
+	}
+	for _, node := range nodesOf(live) {
+		if node.IsIngressGateway && !desired[node.HostID] {
+			node := node
+			p.add(phaseDeleteGateways, Change{
+				Action:  ActionDelete,
+				Kind:    "ingress",
+				Network: spec.Name,
+				Name:    p.state.hostName(live.Nodes, node.ID),
+				apply:   func() { functions.DeleteIngress(spec.Name, node.ID) },
+			})
+		}
+	}
+	return nil
+}
+
+func (p *planner) planRelays(spec *NetworkSpec, live *NetworkState, resolve func(string) (models.ApiHost, error)) error {
+	if spec.Relays == nil {
+		return nil
+	}
+	desired := map[string]bool{}
+	for _, relay := range spec.Relays {
+		relay := relay
+		host, err := resolve(relay.Host)
+		if err != nil {
+			return fmt.Errorf("relay: %w", err)
+		}
+		desired[host.ID] = true
+		relayed := []string{}
+		for _, ref := range relay.Relayed {
+			relayedHost, err := resolve(ref)
+			if err != nil {
+				return fmt.Errorf("relay %s: %w", relay.Host, err)
+			}
+			relayed = append(relayed, relayedHost.Name)
+		}
+		sort.Strings(relayed)
+		node, exists := live.node(host)
+		change := Change{
+			Action:  ActionCreate,
+			Kind:    "relay",
+			Network: spec.Name,
+			Name:    host.Name,
+			Detail:  "relayed " + strings.Join(relayed, ","),
+			apply: func() {
+				relayedNodes := []string{}
+				for _, ref := range relay.Relayed {
+					relayedNodes = append(relayedNodes, p.state.liveNodeID(spec.Name, ref))
+				}
+				nodeID := p.state.liveNodeID(spec.Name, relay.Host)
+				if exists && node.IsRelay {
+					functions.DeleteRelay(spec.Name, nodeID)
+				}
+				functions.CreateRelay(spec.Name, nodeID, relayedNodes)
+			},
+		}
+		if exists && node.IsRelay {
+			liveRelayed := []string{}
+			for _, nodeID := range node.RelayedNodes {
+				liveRelayed = append(liveRelayed, p.state.hostName(live.Nodes, nodeID))
+			}
+			sort.Strings(liveRelayed)
+			if strings.Join(liveRelayed, ",") == strings.Join(relayed, ",") {
+				continue
+			}
+			change.Action = ActionUpdate
+		}
+		p.add(phaseGateways, change)
+	}
+	for _, node := range nodesOf(live) {
+		if node.IsRelay && !desired[node.HostID] {
+			node := node
+			p.add(phaseDeleteGateways, Change{
+				Action:  ActionDelete,
+				Kind:    "relay",
+				Network: spec.Name,
+				Name:    p.state.hostName(live.Nodes, node.ID),
+				apply:   func() { functions.DeleteRelay(spec.Name, node.ID) },
+			})
+		}
+	}
+	return nil
+}
+
+func (p *planner) planACLs(spec *NetworkSpec, live *NetworkState, resolve func(string) (models.ApiHost, error)) error {
+	for _, acl := range spec.ACLs {
+		acl := acl
+		from, err := resolve(acl.From)
+		if err != nil {
+			return fmt.Errorf("acl: %w", err)
+		}
+		to, err := resolve(acl.To)
+		if err != nil {
+			return fmt.Errorf("acl: %w", err)
+		}
+		value := acls.NotAllowed
+		if acl.Action == aclAllow {
+			value = acls.Allowed
+		}
+		fromNode, fromExists := live.node(from)
+		toNode, toExists := live.node(to)
+		if fromExists && toExists &&
+			live.ACLs[acls.AclID(fromNode.ID)][acls.AclID(toNode.ID)] == value &&
+			live.ACLs[acls.AclID(toNode.ID)][acls.AclID(fromNode.ID)] == value {
+			continue
+		}
+		p.add(phaseACLs, Change{
+			Action:  ActionUpdate,
+			Kind:    "acl",
+			Network: spec.Name,
+			Name:    aclName(from.Name, to.Name),
+			Detail:  acl.Action,
+			apply: func() {
+				fromID := acls.AclID(p.state.liveNodeID(spec.Name, acl.From))
+				toID := acls.AclID(p.state.liveNodeID(spec.Name, acl.To))
+				functions.UpdateACL(spec.Name, func(current *acls.ACLContainer) *acls.ACLContainer {
+					return &acls.ACLContainer{
+						fromID: acls.ACL{toID: value},
+						toID:   acls.ACL{fromID: value},
+					}
+				})
+			},
+		})
+	}
+	return nil
+}
+
+func (p *planner) planDNS(spec *NetworkSpec, live *NetworkState) {
+	if spec.DNS == nil {
+		return
+	}
+	current := map[string]models.DNSEntry{}
+	if live != nil {
+		for _, entry := range live.DNS {
+			current[entry.Name] = entry
+		}
+	}
+	desired := map[string]bool{}
+	for _, dns := range spec.DNS {
+		dns := dns
+		desired[dns.Name] = true
+		entry := &models.DNSEntry{Name: dns.Name, Network: spec.Name, Address: dns.Address, Address6: dns.Address6}
+		change := Change{
+			Action:  ActionCreate,
+			Kind:    "dns",
+			Network: spec.Name,
+			Name:    dns.Name,
+			Detail:  strings.Trim(dns.Address+" "+dns.Address6, " "),
+			apply:   func() { functions.CreateDNS(spec.Name, entry) },
+		}
+		if liveEntry, ok := current[dns.Name]; ok {
+			if sameAddress(liveEntry.Address, dns.Address) && sameAddress(liveEntry.Address6, dns.Address6) {
+				continue
+			}
+			change.Action = ActionUpdate
+			change.apply = func() {
+				functions.DeleteDNS(spec.Name, dns.Name)
+				functions.CreateDNS(spec.Name, entry)
+			}
+		}
+		p.add(phaseDNS, change)
+	}
+	for name := range current {
+		if desired[name] {
+			continue
+		}
+		name := name
+		p.add(phaseDNS, Change{
+			Action:  ActionDelete,
+			Kind:    "dns",
+			Network: spec.Name,
+			Name:    name,
+			apply:   func() { functions.DeleteDNS(spec.Name, name) },
+		})
+	}
+}
+
+func (p *planner) planExtClients(spec *NetworkSpec, live *NetworkState, resolve func(string) (models.ApiHost, error)) error {
+	if spec.ExtClients == nil {
+		return nil
+	}
+	current := map[string]models.ExtClient{}
+	if live != nil {
+		for _, client := range live.ExtClients {
+			current[client.ClientID] = client
+		}
+	}
+	desired := map[string]bool{}
+	for _, client := range spec.ExtClients {
+		client := client
+		desired[client.ID] = true
+		gateway, err := resolve(client.Gateway)
+		if err != nil {
+			return fmt.Errorf("ext client %s: %w", client.ID, err)
+		}
+		create := func() {
+			functions.CreateExtClient(spec.Name, p.state.liveNodeID(spec.Name, client.Gateway), models.CustomExtClient{
+				ClientID:        client.ID,
+				DNS:             client.DNS,
+				ExtraAllowedIPs: client.ExtraAllowedIPs,
+			})
+			// new clients are enabled depending on the default acl of the network
+			if client.Enabled != nil {
+				updateExtClient(spec.Name, client)
+			}
+		}
+		change := Change{
+			Action:  ActionCreate,
+			Kind:    "extclient",
+			Network: spec.Name,
+			Name:    client.ID,
+			Detail:  "gateway " + gateway.Name,
+			apply:   create,
+		}
+		if liveClient, ok := current[client.ID]; ok {
+			if gatewayNode, _ := live.node(gateway); gatewayNode.ID != liveClient.IngressGatewayID {
+				// the gateway of a client cannot be changed, the client is replaced
+				change.Action = ActionUpdate
+				change.apply = func() {
+					functions.DeleteExtClient(spec.Name, client.ID)
+					create()
+				}
+			} else if diff := extClientDiff(client, liveClient); diff != "" {
+				change.Action = ActionUpdate
+				change.Detail = diff
+				change.apply = func() { updateExtClient(spec.Name, client) }
+			} else {
+				continue
+			}
+		}
+		p.add(phaseExtClients, change)
+	}
+	for id := range current {
+		if desired[id] {
+			continue
+		}
+		id := id
+		p.add(phaseDeleteExtClients, Change{
+			Action:  ActionDelete,
+			Kind:    "extclient",
+			Network: spec.Name,
+			Name:    id,
+			apply:   func() { functions.DeleteExtClient(spec.Name, id) },
+		})
+	}
+	return nil
+}
+
+// extClientDiff - describes how a client differs from its spec, fields left out of the spec are not compared
+func extClientDiff(spec ExtClientSpec, live models.ExtClient) string {
+	diffs := []string{}
+	if spec.DNS != "" && spec.DNS != live.DNS {
+		diffs = append(diffs, fmt.Sprintf("dns %s -> %s", live.DNS, spec.DNS))
+	}
+	if spec.ExtraAllowedIPs != nil {
+		desired := append([]string{}, spec.ExtraAllowedIPs...)
+		current := append([]string{}, live.ExtraAllowedIPs...)
+		sort.Strings(desired)
+		sort.Strings(current)
+		if strings.Join(desired, ",") != strings.Join(current, ",") {
+			diffs = append(diffs, fmt.Sprintf("extraallowedips %s -> %s", strings.Join(current, ","), strings.Join(desired, ",")))
+		}
+	}
+	if spec.Enabled != nil && *spec.Enabled != live.Enabled {
+		diffs = append(diffs, fmt.Sprintf("enabled %t -> %t", live.Enabled, *spec.Enabled))
+	}
+	return strings.Join(diffs, ", ")
+}
+
+func updateExtClient(network string, spec ExtClientSpec) {
+	functions.UpdateExtClient(network, spec.ID, func(current *models.ExtClient) *models.CustomExtClient {
+		update := &models.CustomExtClient{
+			ClientID:        current.ClientID,
+			PublicKey:       current.PublicKey,
+			DNS:             current.DNS,
+			ExtraAllowedIPs: current.ExtraAllowedIPs,
+			Enabled:         current.Enabled,
+			DeniedACLs:      current.DeniedACLs,
+		}
+		if spec.DNS != "" {
+			update.DNS = spec.DNS
+		}
+		if spec.ExtraAllowedIPs != nil {
+			update.ExtraAllowedIPs = spec.ExtraAllowedIPs
+		}
+		if spec.Enabled != nil {
+			update.Enabled = *spec.Enabled
+		}
+		return update
+	})
+}
+
+// planner.planEnrollmentKeys - only keys that are limited to this one network are managed
+func (p *planner) planEnrollmentKeys(spec *NetworkSpec) {
+	if spec.EnrollmentKeys == nil {
+		return
+	}
+	current := map[string]models.EnrollmentKey{}
+	stale := []models.EnrollmentKey{}
+	for _, key := range p.state.EnrollmentKeys {
+		if len(key.Networks) != 1 || key.Networks[0] != spec.Name {
+			continue
+		}
+		if _, ok := current[keyName(key.Tags)]; ok {
+			stale = append(stale, key)
+			continue
+		}
+		current[keyName(key.Tags)] = key
+	}
+	desired := map[string]bool{}
+	for _, key := range spec.EnrollmentKeys {
+		key := key
+		name := keyName(key.Tags)
+		desired[name] = true
+		create := func() {
+			request := &models.APIEnrollmentKey{
+				UsesRemaining: key.Uses,
+				Networks:      []string{spec.Name},
+				Unlimited:     key.Unlimited,
+				Tags:          key.Tags,
+			}
+			if !key.Expiration.IsZero() {
+				request.Expiration = key.Expiration.Unix()
+			}
+			functions.CreateEnrollmentKey(request)
+		}
+		change := Change{
+			Action:  ActionCreate,
+			Kind:    "enrollmentkey",
+			Network: spec.Name,
+			Name:    name,
+			Detail:  enrollmentKeyType(key).String(),
+			apply:   create,
+		}
+		if liveKey, ok := current[name]; ok {
+			if liveKey.Type == enrollmentKeyType(key) {
+				continue
+			}
+			// enrollment keys cannot be changed, the key is replaced
+			change.Action = ActionUpdate
+			change.apply = func() {
+				functions.DeleteEnrollmentKey(liveKey.Value)
+				create()
+			}
+		}
+		p.add(phaseEnrollmentKeys, change)
+	}
+	for name, key := range current {
+		if !desired[name] {
+			stale = append(stale, key)
+		}
+	}
+	for _, key := range stale {
+		key := key
+		p.add(phaseEnrollmentKeys, Change{
+			Action:  ActionDelete,
+			Kind:    "enrollmentkey",
+			Network: spec.Name,
+			Name:    keyName(key.Tags),
+			apply:   func() { functions.DeleteEnrollmentKey(key.Value) },
+		})
+	}
+}
+
+// enrollmentKeyType - the type the server gives a key created from the spec
+func enrollmentKeyType(key EnrollmentKeySpec) models.KeyType {
+	switch {
+	case key.Uses > 0:
+		return models.Uses
+	case !key.Expiration.IsZero():
+		return models.TimeExpiration
+	case key.Unlimited:
+		return models.Unlimited
+	}
+	return models.Undefined
+}
+
+func nodesOf(live *NetworkState) []models.ApiNode {
+	if live == nil {
+		return nil
+	}
+	return live.Nodes
+}
+
+// sortChanges - orders the changes of a phase by network and name so plans are stable
+func sortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Network != changes[j].Network {
+			return changes[i].Network < changes[j].Network
+		}
+		return changes[i].Name < changes[j].Name
+	})
+}
+
+func normalizeRanges(ranges []string) ([]string, error) {
+	normalized := []string{}
+	for _, r := range ranges {
+		_, cidr, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, cidr.String())
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func sameRange(desired, live string) bool {
+	if desired == "" || desired == models.ULAAddressRange6 {
+		return desired == "" && live == "" || desired == models.ULAAddressRange6 && live != ""
+	}
+	_, cidr, err := net.ParseCIDR(desired)
+	return err == nil && cidr.String() == live
+}
+
+func sameAddress(live, desired string) bool {
+	if live == "" || desired == "" {
+		return live == desired
+	}
+	return net.ParseIP(live).Equal(net.ParseIP(desired))
+}
+
+func intSetting(value int32) string {
+	if value == 0 {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
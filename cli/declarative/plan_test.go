@@ -0,0 +1,139 @@
+package declarative
+
+import (
+	"testing"
+
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPlan(t *testing.T) {
+	hosts := []models.ApiHost{
+		{ID: "host-1", Name: "alpha"},
+		{ID: "host-2", Name: "beta"},
+	}
+	liveNetwork := func() *NetworkState {
+		return &NetworkState{
+			Network: models.Network{NetID: "skynet", AddressRange: "10.10.0.0/24", DefaultACL: "yes", NodeLimit: 10},
+			Nodes: []models.ApiNode{
+				{ID: "node-1", HostID: "host-1", Network: "skynet"},
+				{ID: "node-2", HostID: "host-2", Network: "skynet"},
+			},
+			DNS: []models.DNSEntry{
+				{Name: "db", Network: "skynet", Address: "10.10.0.5"},
+			},
+		}
+	}
+	liveKeys := []models.EnrollmentKey{
+		{Value: "key-1", Networks: []string{"skynet"}, Tags: []string{"site"}, Type: models.Unlimited, Unlimited: true},
+	}
+	tests := []struct {
+		name     string
+		spec     NetworkSpec
+		live     *NetworkState
+		keys     []models.EnrollmentKey
+		changes  []Change
+		warnings []string
+		err      bool
+	}{
+		{
+			name: "Create",
+			spec: NetworkSpec{
+				Name:           "skynet",
+				AddressRange:   "10.10.0.0/24",
+				Hosts:          []string{"beta", "alpha"},
+				DNS:            []DNSSpec{{Name: "db", Address: "10.10.0.5"}},
+				EnrollmentKeys: []EnrollmentKeySpec{{Tags: []string{"site"}, Unlimited: true}},
+			},
+			changes: []Change{
+				{Action: ActionCreate, Kind: "network", Network: "skynet", Name: "skynet", Detail: "10.10.0.0/24"},
+				{Action: ActionCreate, Kind: "host", Network: "skynet", Name: "alpha"},
+				{Action: ActionCreate, Kind: "host", Network: "skynet", Name: "beta"},
+				{Action: ActionCreate, Kind: "dns", Network: "skynet", Name: "db", Detail: "10.10.0.5"},
+				{Action: ActionCreate, Kind: "enrollmentkey", Network: "skynet", Name: "site", Detail: models.Unlimited.String()},
+			},
+		},
+		{
+			name: "Update",
+			spec: NetworkSpec{
+				Name:           "skynet",
+				AddressRange:   "10.10.0.0/24",
+				DefaultACL:     "no",
+				NodeLimit:      20,
+				DNS:            []DNSSpec{{Name: "db", Address: "10.10.0.6"}},
+				EnrollmentKeys: []EnrollmentKeySpec{{Tags: []string{"site"}, Uses: 5}},
+			},
+			live: liveNetwork(),
+			keys: liveKeys,
+			changes: []Change{
+				{Action: ActionUpdate, Kind: "network", Network: "skynet", Name: "skynet", Detail: "nodelimit 10 -> 20"},
+				{Action: ActionUpdate, Kind: "dns", Network: "skynet", Name: "db", Detail: "10.10.0.6"},
+				{Action: ActionUpdate, Kind: "enrollmentkey", Network: "skynet", Name: "site", Detail: models.Uses.String()},
+			},
+			warnings: []string{"network skynet: defaultacl is yes on the server and can only be set when the network is created"},
+		},
+		{
+			name: "Delete",
+			spec: NetworkSpec{
+				Name:           "skynet",
+				AddressRange:   "10.10.0.0/24",
+				Hosts:          []string{"alpha"},
+				DNS:            []DNSSpec{},
+				EnrollmentKeys: []EnrollmentKeySpec{},
+			},
+			live: liveNetwork(),
+			keys: liveKeys,
+			changes: []Change{
+				{Action: ActionDelete, Kind: "host", Network: "skynet", Name: "beta"},
+				{Action: ActionDelete, Kind: "dns", Network: "skynet", Name: "db"},
+				{Action: ActionDelete, Kind: "enrollmentkey", Network: "skynet", Name: "site"},
+			},
+		},
+		{
+			name: "NoChanges",
+			spec: NetworkSpec{
+				Name:           "skynet",
+				AddressRange:   "10.10.0.0/24",
+				NodeLimit:      10,
+				Hosts:          []string{"alpha", "host-2"},
+				DNS:            []DNSSpec{{Name: "db", Address: "10.10.0.5"}},
+				EnrollmentKeys: []EnrollmentKeySpec{{Tags: []string{"site"}, Unlimited: true}},
+			},
+			live:    liveNetwork(),
+			keys:    liveKeys,
+			changes: []Change{},
+		},
+		{
+			name: "AddressRangeChanged",
+			spec: NetworkSpec{Name: "skynet", AddressRange: "10.20.0.0/24"},
+			live: liveNetwork(),
+			err:  true,
+		},
+		{
+			name: "UnknownHost",
+			spec: NetworkSpec{Name: "skynet", AddressRange: "10.10.0.0/24", Hosts: []string{"gamma"}},
+			err:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &State{
+				Hosts:          hosts,
+				EnrollmentKeys: test.keys,
+				Networks:       map[string]*NetworkState{test.spec.Name: test.live},
+			}
+			plan, err := BuildPlan(&Spec{Networks: []NetworkSpec{test.spec}}, state)
+			if test.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			for i := range plan.Changes {
+				assert.NotNil(t, plan.Changes[i].apply)
+				plan.Changes[i].apply = nil
+			}
+			assert.Equal(t, test.changes, plan.Changes)
+			assert.Equal(t, test.warnings, plan.Warnings)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package declarative
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec - the desired state of a set of networks, networks that are not listed are left alone
+type Spec struct {
+	Networks []NetworkSpec `yaml:"networks"`
+}
+
+// NetworkSpec - the desired state of a network
+// a list that is left out is not managed, an empty list removes everything of its kind from the network
+type NetworkSpec struct {
+	Name              string `yaml:"name"`
+	Template          string `yaml:"template"`
+	AddressRange      string `yaml:"addressrange"`
+	AddressRange6     string `yaml:"addressrange6"`
+	DefaultACL        string `yaml:"defaultacl"`
+	DefaultKeepalive  int32  `yaml:"defaultkeepalive"`
+	DefaultMTU        int32  `yaml:"defaultmtu"`
+	DefaultListenPort int32  `yaml:"defaultlistenport"`
+	AllowManualSignUp string `yaml:"allowmanualsignup"`
+	NodeLimit         int32  `yaml:"nodelimit"`
+	// Hosts - the names or ids of the hosts that are part of the network
+	Hosts          []string            `yaml:"hosts"`
+	Egress         []EgressSpec        `yaml:"egress"`
+	Ingress        []IngressSpec       `yaml:"ingress"`
+	Relays         []RelaySpec         `yaml:"relays"`
+	ACLs           []ACLSpec           `yaml:"acls"`
+	DNS            []DNSSpec           `yaml:"dns"`
+	ExtClients     []ExtClientSpec     `yaml:"extclients"`
+	EnrollmentKeys []EnrollmentKeySpec `yaml:"enrollmentkeys"`
+}
+
+// EgressSpec - a host that routes the given ranges into the network, nat defaults to true
+type EgressSpec struct {
+	Host   string   `yaml:"host"`
+	Ranges []string `yaml:"ranges"`
+	NAT    *bool    `yaml:"nat"`
+}
+
+// IngressSpec - a host that lets ext clients into the network
+type IngressSpec struct {
+	Host     string `yaml:"host"`
+	Failover bool   `yaml:"failover"`
+}
+
+// RelaySpec - a host that relays the traffic of other hosts of the network
+type RelaySpec struct {
+	Host    string   `yaml:"host"`
+	Relayed []string `yaml:"relayed"`
+}
+
+// ACLSpec - allows or denies traffic between two hosts of the network in both directions, pairs that are not listed
+// are left alone
+type ACLSpec struct {
+	From   string `yaml:"from"`
+	To     string `yaml:"to"`
+	Action string `yaml:"action"`
+}
+
+// DNSSpec - a custom DNS entry of the network
+type DNSSpec struct {
+	Name     string `yaml:"name"`
+	Address  string `yaml:"address"`
+	Address6 string `yaml:"address6"`
+}
+
+// ExtClientSpec - an ext client of the network, enabled defaults to true
+type ExtClientSpec struct {
+	ID              string   `yaml:"id"`
+	Gateway         string   `yaml:"gateway"`
+	DNS             string   `yaml:"dns"`
+	ExtraAllowedIPs []string `yaml:"extraallowedips"`
+	Enabled         *bool    `yaml:"enabled"`
+}
+
+// EnrollmentKeySpec - an enrollment key for the network, keys are told apart by their tags
+// the uses and expiration of an existing key are not reconciled as they change while the key is used
+type EnrollmentKeySpec struct {
+	Tags       []string  `yaml:"tags"`
+	Uses       int       `yaml:"uses"`
+	Unlimited  bool      `yaml:"unlimited"`
+	Expiration time.Time `yaml:"expiration"`
+}
+
+const (
+	aclAllow = "allow"
+	aclDeny  = "deny"
+)
+
+// LoadSpec - reads and validates a spec from a yaml file
+func LoadSpec(path string) (*Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return spec, spec.validate()
+}
+
+func (s *Spec) validate() error {
+	networks := map[string]bool{}
+	for _, network := range s.Networks {
+		if network.Name == "" {
+			return errors.New("every network needs a name")
+		}
+		if networks[network.Name] {
+			return fmt.Errorf("network %s is listed twice", network.Name)
+		}
+		networks[network.Name] = true
+		if err := network.validate(); err != nil {
+			return fmt.Errorf("network %s: %w", network.Name, err)
+		}
+	}
+	return nil
+}
+
+func (n *NetworkSpec) validate() error {
+	if n.AddressRange == "" && n.AddressRange6 == "" {
+		return errors.New("an addressrange or addressrange6 is required")
+	}
+	if err := unique("host", n.Hosts, func(host string) string { return host }); err != nil {
+		return err
+	}
+	if err := unique("egress gateway", n.Egress, func(egress EgressSpec) string { return egress.Host }); err != nil {
+		return err
+	}
+	for _, egress := range n.Egress {
+		if len(egress.Ranges) == 0 {
+			return fmt.Errorf("egress gateway %s needs ranges", egress.Host)
+		}
+	}
+	if err := unique("ingress gateway", n.Ingress, func(ingress IngressSpec) string { return ingress.Host }); err != nil {
+		return err
+	}
+	if err := unique("relay", n.Relays, func(relay RelaySpec) string { return relay.Host }); err != nil {
+		return err
+	}
+	for _, relay := range n.Relays {
+		if len(relay.Relayed) == 0 {
+			return fmt.Errorf("relay %s needs relayed hosts", relay.Host)
+		}
+	}
+	if err := unique("acl", n.ACLs, func(acl ACLSpec) string { return aclName(acl.From, acl.To) }); err != nil {
+		return err
+	}
+	for _, acl := range n.ACLs {
+		if acl.From == "" || acl.To == "" || acl.From == acl.To {
+			return fmt.Errorf("acl %s needs two different hosts", aclName(acl.From, acl.To))
+		}
+		if acl.Action != aclAllow && acl.Action != aclDeny {
+			return fmt.Errorf("acl %s: action must be %s or %s", aclName(acl.From, acl.To), aclAllow, aclDeny)
+		}
+	}
+	if err := unique("dns entry", n.DNS, func(dns DNSSpec) string { return dns.Name }); err != nil {
+		return err
+	}
+	for _, dns := range n.DNS {
+		if dns.Address == "" && dns.Address6 == "" {
+			return fmt.Errorf("dns entry %s needs an address", dns.Name)
+		}
+	}
+	if err := unique("ext client", n.ExtClients, func(client ExtClientSpec) string { return client.ID }); err != nil {
+		return err
+	}
+	for _, client := range n.ExtClients {
+		if client.Gateway == "" {
+			return fmt.Errorf("ext client %s needs a gateway", client.ID)
+		}
+	}
+	return unique("enrollment key", n.EnrollmentKeys, func(key EnrollmentKeySpec) string { return keyName(key.Tags) })
+}
+
+// unique - makes sure no two items of a list share a name and that no name is empty
+func unique[T any](kind string, items []T, name func(T) string) error {
+	seen := map[string]bool{}
+	for _, item := range items {
+		itemName := name(item)
+		if itemName == "" {
+			return fmt.Errorf("every %s needs a name", kind)
+		}
+		if seen[itemName] {
+			return fmt.Errorf("%s %s is listed twice", kind, itemName)
+		}
+		seen[itemName] = true
+	}
+	return nil
+}
+
+// aclName - the name of the acl between two hosts, the same for both directions
+func aclName(from, to string) string {
+	if to < from {
+		from, to = to, from
+	}
+	return from + " <-> " + to
+}
+
+// keyName - the name of an enrollment key, made of its sorted tags
+func keyName(tags []string) string {
+	sorted := append([]string{}, tags...)
+	sort.Strings(sorted)
+	name := strings.Join(sorted, ",")
+	if name == "" {
+		name = "untagged"
+	}
+	return name
+}
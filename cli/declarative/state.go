@@ -0,0 +1,107 @@
+package declarative
+
+import (
+	"log"
+
+	"github.com/gravitl/netmaker/cli/functions"
+	"github.com/gravitl/netmaker/logic/acls"
+	"github.com/gravitl/netmaker/models"
+)
+
+// State - the live state of the server that a spec is compared with
+type State struct {
+	Hosts          []models.ApiHost
+	EnrollmentKeys []models.EnrollmentKey
+	Networks       map[string]*NetworkState
+}
+
+// NetworkState - the live state of a network, nil for networks that do not exist yet
+type NetworkState struct {
+	Network    models.Network
+	Nodes      []models.ApiNode
+	ACLs       acls.ACLContainer
+	DNS        []models.DNSEntry
+	ExtClients []models.ExtClient
+}
+
+// FetchState - reads the live state of the networks of a spec from the server
+func FetchState(spec *Spec) *State {
+	state := &State{
+		Hosts:          *functions.GetHosts(),
+		EnrollmentKeys: *functions.GetEnrollmentKeys(),
+		Networks:       map[string]*NetworkState{},
+	}
+	existing := map[string]models.Network{}
+	for _, network := range *functions.GetNetworks() {
+		existing[network.NetID] = network
+	}
+	for _, networkSpec := range spec.Networks {
+		network, ok := existing[networkSpec.Name]
+		if !ok {
+			state.Networks[networkSpec.Name] = nil
+			continue
+		}
+		state.Networks[networkSpec.Name] = &NetworkState{
+			Network:    network,
+			Nodes:      *functions.GetNodes(network.NetID),
+			ACLs:       *functions.GetACL(network.NetID),
+			DNS:        *functions.GetCustomDNS(network.NetID),
+			ExtClients: *functions.GetNetworkExtClients(network.NetID),
+		}
+	}
+	return state
+}
+
+// State.host - finds a host by name or id
+func (s *State) host(ref string) (models.ApiHost, bool) {
+	for _, host := range s.Hosts {
+		if host.ID == ref || host.Name == ref {
+			return host, true
+		}
+	}
+	return models.ApiHost{}, false
+}
+
+// State.hostName - returns the name of the host of a node, or the node id if the host is unknown
+func (s *State) hostName(nodes []models.ApiNode, nodeID string) string {
+	for _, node := range nodes {
+		if node.ID != nodeID {
+			continue
+		}
+		for _, host := range s.Hosts {
+			if host.ID == node.HostID {
+				return host.Name
+			}
+		}
+	}
+	return nodeID
+}
+
+// NetworkState.node - finds the node of a host in the network
+func (n *NetworkState) node(host models.ApiHost) (models.ApiNode, bool) {
+	if n == nil {
+		return models.ApiNode{}, false
+	}
+	for _, node := range n.Nodes {
+		if node.HostID == host.ID {
+			return node, true
+		}
+	}
+	return models.ApiNode{}, false
+}
+
+// State.liveNodeID - looks up the node of a host in a network on the server, hosts may have joined the network
+// since the plan was made
+func (s *State) liveNodeID(network, ref string) string {
+	host, ok := s.host(ref)
+	if !ok {
+		log.Fatalf("host %s not found", ref)
+	}
+	for _, node := range *functions.GetNodes(network) {
+		if node.HostID == host.ID {
+			return node.ID
+		}
+	}
+	log.Fatalf("host %s is not part of network %s", ref, network)
+	return ""
+}
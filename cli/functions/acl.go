@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gravitl/netmaker/logic/acls"
+	"github.com/gravitl/netmaker/models"
 )
 
 // GetACL - fetch all ACLs associated with a network
@@ -12,7 +13,14 @@ func GetACL(networkName string) *acls.ACLContainer {
 	return request[acls.ACLContainer](http.MethodGet, fmt.Sprintf("/api/networks/%s/acls", networkName), nil)
 }
 
-// UpdateACL - update an ACL
-func UpdateACL(networkName string, payload *acls.ACLContainer) *acls.ACLContainer {
-	return request[acls.ACLContainer](http.MethodPut, fmt.Sprintf("/api/networks/%s/acls", networkName), payload)
+// UpdateACLBySelector - allow or deny traffic between the nodes matching two label selectors
+func UpdateACLBySelector(networkName string, payload *models.SelectorACLRequest) *acls.ACLContainer {
+	return request[acls.ACLContainer](http.MethodPut, fmt.Sprintf("/api/networks/%s/acls/selector", networkName), payload)
+}
+
+// UpdateACL - update an ACL, the update is rebuilt from the latest ACLs if they were changed concurrently
+func UpdateACL(networkName string, update func(current *acls.ACLContainer) *acls.ACLContainer) *acls.ACLContainer {
+	return conditionalUpdate[acls.ACLContainer, acls.ACLContainer](fmt.Sprintf("/api/networks/%s/acls", networkName), func(current *acls.ACLContainer) any {
+		return update(current)
+	})
 }
@@ -37,7 +37,9 @@ func DeleteExtClient(networkName, clientID string) *models.SuccessResponse {
 	return request[models.SuccessResponse](http.MethodDelete, fmt.Sprintf("/api/extclients/%s/%s", networkName, clientID), nil)
 }
 
-// UpdateExtClient - update an external client
-func UpdateExtClient(networkName, clientID string, payload *models.CustomExtClient) *models.ExtClient {
-	return request[models.ExtClient](http.MethodPut, fmt.Sprintf("/api/extclients/%s/%s", networkName, clientID), payload)
+// UpdateExtClient - update an external client, the update is rebuilt from the latest client if it was changed concurrently
+func UpdateExtClient(networkName, clientID string, update func(current *models.ExtClient) *models.CustomExtClient) *models.ExtClient {
+	return conditionalUpdate[models.ExtClient, models.ExtClient](fmt.Sprintf("/api/extclients/%s/%s", networkName, clientID), func(current *models.ExtClient) any {
+		return update(current)
+	})
 }
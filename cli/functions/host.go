@@ -3,6 +3,7 @@ package functions
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gravitl/netmaker/models"
 )
@@ -16,14 +17,26 @@ func GetHosts() *[]models.ApiHost {
 	return request[[]models.ApiHost](http.MethodGet, "/api/hosts", nil)
 }
 
+// GetHostsBySelector - fetch the hosts whose labels match a selector
+func GetHostsBySelector(selector string) *[]models.ApiHost {
+	return request[[]models.ApiHost](http.MethodGet, "/api/hosts?selector="+url.QueryEscape(selector), nil)
+}
+
 // DeleteHost - delete a host
 func DeleteHost(hostID string) *models.ApiHost {
 	return request[models.ApiHost](http.MethodDelete, "/api/hosts/"+hostID, nil)
 }
 
-// UpdateHost - update a host
-func UpdateHost(hostID string, body *models.ApiHost) *models.ApiHost {
-	return request[models.ApiHost](http.MethodPut, "/api/hosts/"+hostID, body)
+// GetHost - fetch a single host
+func GetHost(hostID string) *models.ApiHost {
+	return request[models.ApiHost](http.MethodGet, "/api/hosts/"+hostID, nil)
+}
+
+// UpdateHost - update a host, the update is rebuilt from the latest host if it was changed concurrently
+func UpdateHost(hostID string, update func(current *models.ApiHost) *models.ApiHost) *models.ApiHost {
+	return conditionalUpdate[models.ApiHost, models.ApiHost]("/api/hosts/"+hostID, func(current *models.ApiHost) any {
+		return update(current)
+	})
 }
 
 // AddHostToNetwork - add a network to host
@@ -58,3 +71,18 @@ func RefreshKeys(hostID string) any {
 	return request[any](http.MethodPut, fmt.Sprintf("/api/hosts/%s/keys", hostID), nil)
 
 }
+
+// CreateBulkJob - runs an operation against every host matching a filter as a background job
+func CreateBulkJob(payload *models.BulkHostRequest) *models.BulkJob {
+	return request[models.BulkJob](http.MethodPost, "/api/hosts/bulk", payload)
+}
+
+// GetBulkJobs - lists the bulk host jobs of the last week
+func GetBulkJobs() *[]models.BulkJob {
+	return request[[]models.BulkJob](http.MethodGet, "/api/hosts/bulk", nil)
+}
+
+// GetBulkJob - fetch a bulk host job with the result of every host
+func GetBulkJob(jobID string) *models.BulkJob {
+	return request[models.BulkJob](http.MethodGet, "/api/hosts/bulk/"+jobID, nil)
+}
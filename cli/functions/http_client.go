@@ -112,59 +112,99 @@ func getAuthToken(ctx config.Context, force bool) string {
 }
 
 func request[T any](method, route string, payload any) *T {
+	res, resBodyBytes := send(method, route, payload, nil)
+	if res.StatusCode != http.StatusOK {
+		log.Fatalf("Error Status: %d Response: %s", res.StatusCode, string(resBodyBytes))
+	}
+	return decode[T](resBodyBytes)
+}
+
+// maxUpdateAttempts - how often an update is retried when the resource keeps changing underneath it
+const maxUpdateAttempts = 5
+
+// conditionalUpdate - fetches a resource, builds the update from its current state and sends it with If-Match
+// if someone else changed the resource in the meantime the server answers 412 and the update is rebuilt and retried
+func conditionalUpdate[T, R any](route string, update func(current *T) any) *R {
+	for attempt := 1; ; attempt++ {
+		res, resBodyBytes := send(http.MethodGet, route, nil, nil)
+		if res.StatusCode != http.StatusOK {
+			log.Fatalf("Error Status: %d Response: %s", res.StatusCode, string(resBodyBytes))
+		}
+		payload := update(decode[T](resBodyBytes))
+		header := http.Header{}
+		if etag := res.Header.Get("ETag"); etag != "" {
+			header.Set("If-Match", etag)
+		}
+		res, resBodyBytes = send(http.MethodPut, route, payload, header)
+		if res.StatusCode == http.StatusPreconditionFailed && attempt < maxUpdateAttempts {
+			log.Printf("resource was modified by someone else, retrying (%d/%d)", attempt, maxUpdateAttempts)
+			continue
+		}
+		if res.StatusCode != http.StatusOK {
+			log.Fatalf("Error Status: %d Response: %s", res.StatusCode, string(resBodyBytes))
+		}
+		return decode[R](resBodyBytes)
+	}
+}
+
+func decode[T any](resBodyBytes []byte) *T {
+	body := new(T)
+	if len(resBodyBytes) > 0 {
+		if err := json.Unmarshal(resBodyBytes, body); err != nil {
+			log.Fatalf("Error unmarshalling JSON: %s", err)
+		}
+	}
+	return body
+}
+
+// send - makes an authenticated request, refreshing the JWT once if it has expired
+func send(method, route string, payload any, header http.Header) (*http.Response, []byte) {
 	var (
 		_, ctx = config.GetCurrentContext()
-		req    *http.Request
-		err    error
+		body   []byte
 	)
-	if payload == nil {
-		req, err = http.NewRequest(method, ctx.Endpoint+route, nil)
-		if err != nil {
-			log.Fatalf("Client could not create request: %s", err)
-		}
-	} else {
-		payloadBytes, jsonErr := json.Marshal(payload)
-		if jsonErr != nil {
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
 			log.Fatalf("Error in request JSON marshalling: %s", err)
 		}
-		req, err = http.NewRequest(method, ctx.Endpoint+route, bytes.NewReader(payloadBytes))
+	}
+	newRequest := func(authToken string) *http.Request {
+		req, err := http.NewRequest(method, ctx.Endpoint+route, bytes.NewReader(body))
 		if err != nil {
 			log.Fatalf("Client could not create request: %s", err)
 		}
-		req.Header.Set("Content-Type", "application/json")
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		return req
 	}
-	if ctx.MasterKey != "" {
-		req.Header.Set("Authorization", "Bearer "+ctx.MasterKey)
-	} else {
-		req.Header.Set("Authorization", "Bearer "+getAuthToken(ctx, false))
+	authToken := ctx.MasterKey
+	if authToken == "" {
+		authToken = getAuthToken(ctx, false)
 	}
-	retried := false
-retry:
-	res, err := http.DefaultClient.Do(req)
+	res, err := http.DefaultClient.Do(newRequest(authToken))
 	if err != nil {
 		log.Fatalf("Client error making http request: %s", err)
 	}
 	// refresh JWT token
-	if res.StatusCode == http.StatusUnauthorized && !retried && ctx.MasterKey == "" {
-		req.Header.Set("Authorization", "Bearer "+getAuthToken(ctx, true))
-		retried = true
-		// TODO add a retry limit, drop goto
-		goto retry
+	if res.StatusCode == http.StatusUnauthorized && ctx.MasterKey == "" {
+		res.Body.Close()
+		res, err = http.DefaultClient.Do(newRequest(getAuthToken(ctx, true)))
+		if err != nil {
+			log.Fatalf("Client error making http request: %s", err)
+		}
 	}
+	defer res.Body.Close()
 	resBodyBytes, err := io.ReadAll(res.Body)
 	if err != nil {
 		log.Fatalf("Client could not read response body: %s", err)
 	}
-	if res.StatusCode != http.StatusOK {
-		log.Fatalf("Error Status: %d Response: %s", res.StatusCode, string(resBodyBytes))
-	}
-	body := new(T)
-	if len(resBodyBytes) > 0 {
-		if err := json.Unmarshal(resBodyBytes, body); err != nil {
-			log.Fatalf("Error unmarshalling JSON: %s", err)
-		}
-	}
-	return body
+	return res, resBodyBytes
 }
 
 func get(route string) string {
@@ -3,6 +3,7 @@ package functions
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gravitl/netmaker/models"
 )
@@ -12,9 +13,11 @@ func CreateNetwork(payload *models.Network) *models.Network {
 	return request[models.Network](http.MethodPost, "/api/networks", payload)
 }
 
-// UpdateNetwork - updates a network
-func UpdateNetwork(name string, payload *models.Network) *models.Network {
-	return request[models.Network](http.MethodPut, "/api/networks/"+name, payload)
+// UpdateNetwork - updates a network, the update is rebuilt from the latest network if it was changed concurrently
+func UpdateNetwork(name string, update func(current *models.Network) *models.Network) *models.Network {
+	return conditionalUpdate[models.Network, models.Network]("/api/networks/"+name, func(current *models.Network) any {
+		return update(current)
+	})
 }
 
 // UpdateNetworkNodeLimit - updates a network
@@ -38,3 +41,88 @@ func GetNetwork(name string) *models.Network {
 func DeleteNetwork(name string) *string {
 	return request[string](http.MethodDelete, "/api/networks/"+name, nil)
 }
+
+// UpdateNetworkRanges - replaces the secondary address ranges of a network
+func UpdateNetworkRanges(name string, ranges []string) *models.Network {
+	return request[models.Network](http.MethodPut, fmt.Sprintf("/api/networks/%s/ranges", name), ranges)
+}
+
+// SetKeyRotationPolicy - sets how many days the hosts of a network keep their wireguard keys
+func SetKeyRotationPolicy(name string, intervalDays int32) *models.Network {
+	return request[models.Network](http.MethodPut, fmt.Sprintf("/api/networks/%s/keyrotation", name), &models.KeyRotationPolicy{
+		IntervalDays: intervalDays,
+	})
+}
+
+// GetKeyRotationStatus - fetch the key rotation policy of a network with the key age of each of its hosts
+func GetKeyRotationStatus(name string) *models.KeyRotationStatus {
+	return request[models.KeyRotationStatus](http.MethodGet, fmt.Sprintf("/api/networks/%s/keyrotation", name), nil)
+}
+
+// SetApprovalPolicy - sets whether hosts registering themselves into a network need their nodes approved
+func SetApprovalPolicy(name string, requiresApproval bool) *models.Network {
+	return request[models.Network](http.MethodPut, fmt.Sprintf("/api/networks/%s/approval", name), &models.ApprovalPolicy{
+		RequiresApproval: requiresApproval,
+	})
+}
+
+// GetNodeApprovals - fetch the approvals and rejections of nodes in a network
+func GetNodeApprovals(name string) *[]models.NodeApproval {
+	return request[[]models.NodeApproval](http.MethodGet, fmt.Sprintf("/api/networks/%s/approvals", name), nil)
+}
+
+// SetPosturePolicy - sets the requirements the hosts of a network must meet
+func SetPosturePolicy(name string, policy *models.PosturePolicy) *models.Network {
+	return request[models.Network](http.MethodPut, fmt.Sprintf("/api/networks/%s/posture", name), policy)
+}
+
+// GetPostureStatus - fetch the posture policy of a network with the nodes quarantined for failing it
+func GetPostureStatus(name string) *models.PostureStatus {
+	return request[models.PostureStatus](http.MethodGet, fmt.Sprintf("/api/networks/%s/posture", name), nil)
+}
+
+// RenumberNetwork - moves a network to new address ranges, or only plans the move when dryRun is set
+func RenumberNetwork(name string, payload *models.RenumberRequest, dryRun bool) *models.RenumberPlan {
+	return request[models.RenumberPlan](http.MethodPost, fmt.Sprintf("/api/networks/%s/renumber?dryrun=%t", name, dryRun), payload)
+}
+
+// GetAddressReservations - fetch the address reservations of a network
+func GetAddressReservations(name string) *[]models.AddressReservation {
+	return request[[]models.AddressReservation](http.MethodGet, fmt.Sprintf("/api/networks/%s/reservations", name), nil)
+}
+
+// CreateAddressReservation - reserve an address of a network for a host
+func CreateAddressReservation(name string, payload *models.AddressReservation) *models.AddressReservation {
+	return request[models.AddressReservation](http.MethodPost, fmt.Sprintf("/api/networks/%s/reservations", name), payload)
+}
+
+// DeleteAddressReservation - delete an address reservation of a network
+func DeleteAddressReservation(name, address string) *string {
+	return request[string](http.MethodDelete, fmt.Sprintf("/api/networks/%s/reservations/%s", name, address), nil)
+}
+
+// GetReservedAddressRanges - fetch the address ranges of a network that are never handed out
+func GetReservedAddressRanges(name string) *[]models.AddressRange {
+	return request[[]models.AddressRange](http.MethodGet, fmt.Sprintf("/api/networks/%s/reserved-ranges", name), nil)
+}
+
+// ReserveAddressRange - keep an address range of a network from being handed out
+func ReserveAddressRange(name string, payload *models.AddressRange) *[]models.AddressRange {
+	return request[[]models.AddressRange](http.MethodPost, fmt.Sprintf("/api/networks/%s/reserved-ranges", name), payload)
+}
+
+// UnreserveAddressRange - let a reserved address range of a network be handed out again
+func UnreserveAddressRange(name string, addressRange *models.AddressRange) *[]models.AddressRange {
+	return request[[]models.AddressRange](http.MethodDelete, fmt.Sprintf("/api/networks/%s/reserved-ranges/%s/%s", name,
+		addressRange.Start, addressRange.End), nil)
+}
+
+// CreateNetworkFromTemplate - creates a network from a network template, only the name and ranges of payload are used
+func CreateNetworkFromTemplate(payload *models.Network, template string) *models.Network {
+	return request[models.Network](http.MethodPost, "/api/networks?template="+url.QueryEscape(template), payload)
+}
+
+// CloneNetwork - creates a network with the settings, custom DNS entries and enrollment keys of another network
+func CloneNetwork(name string, payload *models.NetworkCloneRequest) *models.Network {
+	return request[models.Network](http.MethodPost, fmt.Sprintf("/api/networks/%s/clone", name), payload)
+}
@@ -0,0 +1,33 @@
+package functions
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gravitl/netmaker/models"
+)
+
+// GetNetworkTemplates - fetch all network templates
+func GetNetworkTemplates() *[]models.NetworkTemplate {
+	return request[[]models.NetworkTemplate](http.MethodGet, "/api/network-templates", nil)
+}
+
+// GetNetworkTemplate - fetch a single network template
+func GetNetworkTemplate(name string) *models.NetworkTemplate {
+	return request[models.NetworkTemplate](http.MethodGet, "/api/network-templates/"+name, nil)
+}
+
+// CreateNetworkTemplate - creates or replaces a network template
+func CreateNetworkTemplate(payload *models.NetworkTemplate) *models.NetworkTemplate {
+	return request[models.NetworkTemplate](http.MethodPost, "/api/network-templates", payload)
+}
+
+// SaveNetworkTemplate - saves an existing network as a network template
+func SaveNetworkTemplate(network string, payload *models.NetworkTemplateRequest) *models.NetworkTemplate {
+	return request[models.NetworkTemplate](http.MethodPost, fmt.Sprintf("/api/networks/%s/template", network), payload)
+}
+
+// DeleteNetworkTemplate - delete a network template
+func DeleteNetworkTemplate(name string) *string {
+	return request[string](http.MethodDelete, "/api/network-templates/"+name, nil)
+}
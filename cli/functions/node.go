@@ -3,6 +3,7 @@ package functions
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gravitl/netmaker/models"
 )
@@ -16,14 +17,25 @@ func GetNodes(networkName ...string) *[]models.ApiNode {
 	}
 }
 
+// GetNodesBySelector - fetch the nodes, of one network if given, whose labels or host labels match a selector
+func GetNodesBySelector(selector string, networkName ...string) *[]models.ApiNode {
+	query := "?selector=" + url.QueryEscape(selector)
+	if len(networkName) == 1 {
+		return request[[]models.ApiNode](http.MethodGet, "/api/nodes/"+networkName[0]+query, nil)
+	}
+	return request[[]models.ApiNode](http.MethodGet, "/api/nodes"+query, nil)
+}
+
 // GetNodeByID - fetch a single node by ID
 func GetNodeByID(networkName, nodeID string) *models.NodeGet {
 	return request[models.NodeGet](http.MethodGet, fmt.Sprintf("/api/nodes/%s/%s", networkName, nodeID), nil)
 }
 
-// UpdateNode - update a single node
-func UpdateNode(networkName, nodeID string, node *models.ApiNode) *models.ApiNode {
-	return request[models.ApiNode](http.MethodPut, fmt.Sprintf("/api/nodes/%s/%s", networkName, nodeID), node)
+// UpdateNode - update a single node, the update is rebuilt from the latest node if it was changed concurrently
+func UpdateNode(networkName, nodeID string, update func(current *models.NodeGet) *models.ApiNode) *models.ApiNode {
+	return conditionalUpdate[models.NodeGet, models.ApiNode](fmt.Sprintf("/api/nodes/%s/%s", networkName, nodeID), func(current *models.NodeGet) any {
+		return update(current)
+	})
 }
 
 // DeleteNode - delete a node
@@ -53,7 +65,12 @@ func DeleteIngress(networkName, nodeID string) *models.ApiNode {
 	return request[models.ApiNode](http.MethodDelete, fmt.Sprintf("/api/nodes/%s/%s/deleteingress", networkName, nodeID), nil)
 }
 
-// UncordonNode - uncordon a node
-func UncordonNode(networkName, nodeID string) *string {
-	return request[string](http.MethodPost, fmt.Sprintf("/api/nodes/%s/%s/approve", networkName, nodeID), nil)
+// ApproveNode - approve a node pending approval
+func ApproveNode(networkName, nodeID string) *models.ApiNode {
+	return request[models.ApiNode](http.MethodPost, fmt.Sprintf("/api/nodes/%s/%s/approve", networkName, nodeID), nil)
+}
+
+// RejectNode - reject a node pending approval, removing it from its network
+func RejectNode(networkName, nodeID string) *models.ApiNode {
+	return request[models.ApiNode](http.MethodPost, fmt.Sprintf("/api/nodes/%s/%s/reject", networkName, nodeID), nil)
 }
@@ -0,0 +1,22 @@
+package functions
+
+import (
+	"net/http"
+
+	"github.com/gravitl/netmaker/models"
+)
+
+// GetNetworkPeerings - lists the peerings between networks
+func GetNetworkPeerings() *[]models.NetworkPeering {
+	return request[[]models.NetworkPeering](http.MethodGet, "/api/peerings", nil)
+}
+
+// CreateNetworkPeering - peers two networks through gateway nodes of the same host
+func CreateNetworkPeering(payload *models.NetworkPeeringRequest) *models.NetworkPeering {
+	return request[models.NetworkPeering](http.MethodPost, "/api/peerings", payload)
+}
+
+// DeleteNetworkPeering - deletes a peering between networks
+func DeleteNetworkPeering(peeringID string) *string {
+	return request[string](http.MethodDelete, "/api/peerings/"+peeringID, nil)
+}
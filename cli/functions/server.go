@@ -26,3 +26,13 @@ func GetServerConfig() *cfg.ServerConfig {
 func GetServerHealth() string {
 	return get("/api/server/health")
 }
+
+// BackupServer - export every table of the server to an archive, encrypted unless the request is marked insecure
+func BackupServer(backupRequest *models.BackupRequest) *models.BackupArchive {
+	return request[models.BackupArchive](http.MethodPost, "/api/server/backup", backupRequest)
+}
+
+// RestoreServer - replace the contents of the server with a backup archive
+func RestoreServer(archive *models.BackupArchive, passphrase string) *models.SuccessResponse {
+	return request[models.SuccessResponse](http.MethodPost, "/api/server/restore", &models.RestoreRequest{Archive: *archive, Passphrase: passphrase})
+}
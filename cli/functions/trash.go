@@ -0,0 +1,22 @@
+package functions
+
+import (
+	"net/http"
+
+	"github.com/gravitl/netmaker/models"
+)
+
+// GetTrash - lists deleted objects that can still be restored
+func GetTrash() *[]models.TrashItem {
+	return request[[]models.TrashItem](http.MethodGet, "/api/trash", nil)
+}
+
+// RestoreTrash - restores a deleted object
+func RestoreTrash(trashID string) *models.TrashItem {
+	return request[models.TrashItem](http.MethodPost, "/api/trash/"+trashID+"/restore", nil)
+}
+
+// PurgeTrash - permanently deletes an object from the trash
+func PurgeTrash(trashID string) *models.SuccessResponse {
+	return request[models.SuccessResponse](http.MethodDelete, "/api/trash/"+trashID, nil)
+}
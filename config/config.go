@@ -52,6 +52,10 @@ type ServerConfig struct {
 	DisableRemoteIPCheck       string `yaml:"disableremoteipcheck"`
 	Version                    string `yaml:"version"`
 	SQLConn                    string `yaml:"sqlconn"`
+	DBEncryptionKeyFile        string `yaml:"dbencryptionkeyfile"`
+	TrashRetention             string `yaml:"trashretention"`
+	AddressCooldown            string `yaml:"addresscooldown"`
+	EphemeralHostTimeout       string `yaml:"ephemeralhosttimeout"`
 	Platform                   string `yaml:"platform"`
 	Database                   string `yaml:"database"`
 	Verbosity                  int32  `yaml:"verbosity"`
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/mq"
+)
+
+// swagger:route GET /api/hosts/bulk hosts getBulkJobs
+//
+// Lists the bulk host jobs of the last week, newest first.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: bulkJobsResponse
+func getBulkJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := logic.GetBulkJobs()
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch bulk jobs:", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// swagger:route GET /api/hosts/bulk/{jobid} hosts getBulkJob
+//
+// Gets a bulk host job with the result of every host.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: bulkJobResponse
+func getBulkJob(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["jobid"]
+	job, err := logic.GetBulkJob(id)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch bulk job", id, err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "notfound"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// swagger:route POST /api/hosts/bulk hosts createBulkJob
+//
+// Runs an operation against every host matching a filter. The job runs in the background,
+// its progress and the result of every host can be read from /api/hosts/bulk/{jobid}.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				202: bulkJobResponse
+func createBulkJob(w http.ResponseWriter, r *http.Request) {
+	var request models.BulkHostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	job, hosts, err := logic.CreateBulkJob(request, r.Header.Get("user"))
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to create bulk job:", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "started bulk job", job.ID, "to", string(job.Request.Operation), "on", strconv.Itoa(len(hosts)), "hosts")
+	jobCopy := *job
+	jobCopy.Results = append([]models.BulkHostResult{}, job.Results...)
+	go runBulkJob(job, hosts)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(jobCopy)
+}
+
+// runBulkJob - runs the operation of a job against its hosts one by one, recording the result of each, peers are
+// updated once at the end
+func runBulkJob(job *models.BulkJob, hosts []models.Host) {
+	job.Status = models.BulkRunning
+	if err := logic.SaveBulkJob(job); err != nil {
+		logger.Log(0, "failed to save bulk job", job.ID, err.Error())
+	}
+	for i := range hosts {
+		err := runBulkOperation(job, hosts[i].ID.String())
+		if err != nil {
+			logger.Log(0, "bulk job", job.ID, "failed on host", hosts[i].Name, err.Error())
+		}
+		if err := logic.SetBulkResult(job, i, err); err != nil {
+			logger.Log(0, "failed to save bulk job", job.ID, err.Error())
+		}
+	}
+	if job.Succeeded > 0 && (job.Request.Operation == models.BulkAddToNetwork || job.Request.Operation == models.BulkUpdateSettings) {
+		if err := mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "fail to publish peer update: ", err.Error())
+		}
+	}
+	if err := logic.FinishBulkJob(job); err != nil {
+		logger.Log(0, "failed to save bulk job", job.ID, err.Error())
+	}
+	logger.Log(1, "finished bulk job", job.ID, fmt.Sprintf("with %d of %d hosts succeeded", job.Succeeded, job.Total))
+}
+
+// runBulkOperation - runs the operation of a job against a single host, the host is read again as it may have changed
+// since the job was created, hosts that miss the update message catch up on their next pull
+func runBulkOperation(job *models.BulkJob, hostID string) error {
+	request := &job.Request
+	currHost, err := logic.GetHost(hostID)
+	if err != nil {
+		return err
+	}
+	hostUpdate := models.HostUpdate{Host: *currHost}
+	switch request.Operation {
+	case models.BulkAddToNetwork:
+		newNode, err := logic.UpdateHostNetwork(currHost, request.Network, true)
+		if err != nil {
+			return err
+		}
+		hostUpdate.Action = models.JoinHostToNetwork
+		hostUpdate.Node = *newNode
+	case models.BulkRemoveFromNetwork:
+		return removeHostFromNetwork(currHost, request.Network, request.Force)
+	case models.BulkRefreshKeys:
+		hostUpdate.Action = models.UpdateKeys
+	case models.BulkUpdateSettings:
+		if request.Verbosity != nil {
+			currHost.Verbosity = *request.Verbosity
+		}
+		if request.MTU != nil {
+			currHost.MTU = *request.MTU
+		}
+		if request.AutoUpdate != nil {
+			currHost.AutoUpdate = *request.AutoUpdate
+		}
+		if err := logic.UpsertHost(currHost); err != nil {
+			return err
+		}
+		hostUpdate.Action = models.UpdateHost
+		hostUpdate.Host = *currHost
+	case models.BulkDelete:
+		if err := logic.SoftDeleteHost(currHost, request.Force, job.CreatedBy); err != nil {
+			return err
+		}
+		hostUpdate.Action = models.DeleteHost
+	}
+	if err := mq.HostUpdate(&hostUpdate); err != nil {
+		logger.Log(0, "failed to send host update of bulk job", job.ID, "to host", currHost.ID.String(), err.Error())
+		if hostUpdate.Action == models.UpdateKeys {
+			return fmt.Errorf("failed to ask host to refresh its keys: %w", err)
+		}
+		return fmt.Errorf("changes were saved but the host was not notified: %w", err)
+	}
+	if hostUpdate.Action == models.UpdateKeys {
+		return logic.KeyRotationRequested(currHost)
+	}
+	return nil
+}
@@ -94,7 +94,7 @@ func getCustomDNS(w http.ResponseWriter, r *http.Request) {
 	var params = mux.Vars(r)
 	network := params["network"]
 	dns, err := logic.GetCustomDNS(network)
-	if err != nil {
+	if err != nil && !database.IsEmptyRecord(err) {
 		logger.Log(0, r.Header.Get("user"),
 			fmt.Sprintf("failed to get custom DNS entries for network [%s]: %v", network, err.Error()))
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
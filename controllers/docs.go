@@ -144,7 +144,7 @@ type createExtClientPathParams struct {
 	CustomExtClient models.CustomExtClient `json:"custom_ext_client"`
 }
 
-// swagger:parameters getNode updateNode deleteNode createRelay deleteRelay createEgressGateway deleteEgressGateway createIngressGateway deleteIngressGateway uncordonNode
+// swagger:parameters getNode updateNode deleteNode createRelay deleteRelay createEgressGateway deleteEgressGateway createIngressGateway deleteIngressGateway approveNode rejectNode
 type networkNodePathParams struct {
 	// Network
 	// in: path
@@ -182,7 +182,7 @@ type networkBodyParam struct {
 	Network models.Network `json:"network"`
 }
 
-// swagger:parameters updateNetwork getNetwork updateNetwork updateNetworkNodeLimit deleteNetwork keyUpdate createAccessKey getAccessKeys deleteAccessKey updateNetworkACL getNetworkACL
+// swagger:parameters updateNetwork getNetwork updateNetwork updateNetworkNodeLimit deleteNetwork keyUpdate createAccessKey getAccessKeys deleteAccessKey updateNetworkACL getNetworkACL getAddressReservations createAddressReservation deleteAddressReservation renumberNetwork updateNetworkRanges cloneNetwork saveNetworkTemplate updateNetworkACLBySelector getKeyRotationStatus updateKeyRotationPolicy updateApprovalPolicy getNodeApprovals getPostureStatus updatePosturePolicy getReservedAddressRanges reserveAddressRange unreserveAddressRange
 type networkPathParam struct {
 	// Network Name
 	// in: path
@@ -329,6 +329,292 @@ type usernamePathParam struct {
 	Username string `json:"username"`
 }
 
+// swagger:parameters backupServer
+type backupBodyParam struct {
+	// Backup Request
+	// in: body
+	BackupRequest models.BackupRequest `json:"backup_request"`
+}
+
+// swagger:response backupArchiveResponse
+type backupArchiveResponse struct {
+	// Backup Archive
+	// in: body
+	BackupArchive models.BackupArchive `json:"backup_archive"`
+}
+
+// swagger:parameters restoreServer
+type restoreBodyParam struct {
+	// Restore Request
+	// in: body
+	RestoreRequest models.RestoreRequest `json:"restore_request"`
+}
+
+// swagger:response apiHostResponse
+type apiHostResponse struct {
+	// Host
+	// in: body
+	Host models.ApiHost `json:"host"`
+}
+
+// swagger:parameters restoreTrash purgeTrash
+type trashPathParam struct {
+	// Trash ID
+	// in: path
+	TrashID string `json:"trashid"`
+}
+
+// swagger:response trashItemsResponse
+type trashItemsResponse struct {
+	// Trash Items
+	// in: body
+	TrashItems []models.TrashItem `json:"trash_items"`
+}
+
+// swagger:response trashItemResponse
+type trashItemResponse struct {
+	// Trash Item
+	// in: body
+	TrashItem models.TrashItem `json:"trash_item"`
+}
+
+// swagger:parameters deleteAddressReservation
+type reservationPathParam struct {
+	// Reserved Address
+	// in: path
+	Address string `json:"address"`
+}
+
+// swagger:parameters createAddressReservation
+type reservationBodyParam struct {
+	// Address Reservation
+	// in: body
+	Reservation models.AddressReservation `json:"reservation"`
+}
+
+// swagger:response reservationsResponse
+type reservationsResponse struct {
+	// Address Reservations
+	// in: body
+	Reservations []models.AddressReservation `json:"reservations"`
+}
+
+// swagger:response reservationResponse
+type reservationResponse struct {
+	// Address Reservation
+	// in: body
+	Reservation models.AddressReservation `json:"reservation"`
+}
+
+// swagger:parameters unreserveAddressRange
+type reservedRangePathParams struct {
+	// First Address of the Range
+	// in: path
+	Start string `json:"start"`
+	// Last Address of the Range
+	// in: path
+	End string `json:"end"`
+}
+
+// swagger:parameters reserveAddressRange
+type reservedRangeBodyParam struct {
+	// Address Range
+	// in: body
+	AddressRange models.AddressRange `json:"address_range"`
+}
+
+// swagger:response reservedRangesResponse
+type reservedRangesResponse struct {
+	// Reserved Address Ranges
+	// in: body
+	AddressRanges []models.AddressRange `json:"address_ranges"`
+}
+
+// swagger:parameters renumberNetwork
+type renumberParams struct {
+	// Only return the plan without changing anything
+	// in: query
+	DryRun bool `json:"dryrun"`
+	// New Address Ranges
+	// in: body
+	Request models.RenumberRequest `json:"request"`
+}
+
+// swagger:parameters updateNetworkRanges
+type networkRangesBodyParam struct {
+	// Secondary Address Ranges
+	// in: body
+	Ranges []string `json:"ranges"`
+}
+
+// swagger:response renumberPlanResponse
+type renumberPlanResponse struct {
+	// Renumber Plan
+	// in: body
+	Plan models.RenumberPlan `json:"plan"`
+}
+
+// swagger:parameters createNetwork
+type createNetworkTemplateParam struct {
+	// Name of the network template to create the network from
+	// in: query
+	Template string `json:"template"`
+}
+
+// swagger:parameters cloneNetwork
+type networkCloneBodyParam struct {
+	// Name and Address Ranges of the new Network
+	// in: body
+	Request models.NetworkCloneRequest `json:"request"`
+}
+
+// swagger:parameters getNetworkTemplate deleteNetworkTemplate
+type networkTemplatePathParam struct {
+	// Network Template Name
+	// in: path
+	TemplateName string `json:"templatename"`
+}
+
+// swagger:parameters createNetworkTemplate
+type networkTemplateBodyParam struct {
+	// Network Template
+	// in: body
+	Template models.NetworkTemplate `json:"template"`
+}
+
+// swagger:parameters saveNetworkTemplate
+type networkTemplateRequestBodyParam struct {
+	// Name and Description of the Network Template
+	// in: body
+	Request models.NetworkTemplateRequest `json:"request"`
+}
+
+// swagger:response networkTemplatesResponse
+type networkTemplatesResponse struct {
+	// Network Templates
+	// in: body
+	Templates []models.NetworkTemplate `json:"templates"`
+}
+
+// swagger:response networkTemplateResponse
+type networkTemplateResponse struct {
+	// Network Template
+	// in: body
+	Template models.NetworkTemplate `json:"template"`
+}
+
+// swagger:parameters getNetworkPeering deleteNetworkPeering
+type networkPeeringPathParam struct {
+	// Network Peering ID
+	// in: path
+	PeeringID string `json:"peeringid"`
+}
+
+// swagger:parameters createNetworkPeering
+type networkPeeringBodyParam struct {
+	// Networks and Gateway Nodes to peer
+	// in: body
+	Request models.NetworkPeeringRequest `json:"request"`
+}
+
+// swagger:response networkPeeringsResponse
+type networkPeeringsResponse struct {
+	// Network Peerings
+	// in: body
+	Peerings []models.NetworkPeering `json:"peerings"`
+}
+
+// swagger:response networkPeeringResponse
+type networkPeeringResponse struct {
+	// Network Peering
+	// in: body
+	Peering models.NetworkPeering `json:"peering"`
+}
+
+// swagger:parameters getHosts getAllNodes getNetworkNodes
+type labelSelectorParam struct {
+	// Comma separated label requirements like env=prod,role!=db,backup,!legacy
+	// in: query
+	Selector string `json:"selector"`
+}
+
+// swagger:parameters updateNetworkACLBySelector
+type selectorACLBodyParam struct {
+	// Label Selectors of the Nodes and whether they may talk to each other
+	// in: body
+	Request models.SelectorACLRequest `json:"request"`
+}
+
+// swagger:parameters getBulkJob
+type bulkJobPathParam struct {
+	// Bulk Job ID
+	// in: path
+	JobID string `json:"jobid"`
+}
+
+// swagger:parameters createBulkJob
+type bulkHostRequestBodyParam struct {
+	// Host Filter and Operation to run
+	// in: body
+	Request models.BulkHostRequest `json:"request"`
+}
+
+// swagger:response bulkJobsResponse
+type bulkJobsResponse struct {
+	// Bulk Jobs
+	// in: body
+	Jobs []models.BulkJob `json:"jobs"`
+}
+
+// swagger:response bulkJobResponse
+type bulkJobResponse struct {
+	// Bulk Job
+	// in: body
+	Job models.BulkJob `json:"job"`
+}
+
+// swagger:parameters updateKeyRotationPolicy
+type keyRotationPolicyBodyParam struct {
+	// Key Rotation Interval of the Network
+	// in: body
+	Policy models.KeyRotationPolicy `json:"policy"`
+}
+
+// swagger:response keyRotationStatusResponse
+type keyRotationStatusResponse struct {
+	// Key Rotation Policy and Key Age of the Hosts
+	// in: body
+	Status models.KeyRotationStatus `json:"status"`
+}
+
+// swagger:parameters updateApprovalPolicy
+type approvalPolicyBodyParam struct {
+	// Approval Policy of the Network
+	// in: body
+	Policy models.ApprovalPolicy `json:"policy"`
+}
+
+// swagger:response nodeApprovalsResponse
+type nodeApprovalsResponse struct {
+	// Approvals and Rejections of Nodes
+	// in: body
+	Approvals []models.NodeApproval `json:"approvals"`
+}
+
+// swagger:parameters updatePosturePolicy
+type posturePolicyBodyParam struct {
+	// Posture Policy of the Network
+	// in: body
+	Policy models.PosturePolicy `json:"policy"`
+}
+
+// swagger:response postureStatusResponse
+type postureStatusResponse struct {
+	// Posture Policy and Quarantined Nodes
+	// in: body
+	Status models.PostureStatus `json:"status"`
+}
+
 // prevent issues with integration tests for types just used by Swagger docs.
 func useUnused() bool {
 	_ = dnsPathParams{}
@@ -370,5 +656,45 @@ func useUnused() bool {
 	_ = userBodyResponse{}
 	_ = userAuthBodyParam{}
 	_ = usernamePathParam{}
+	_ = backupBodyParam{}
+	_ = backupArchiveResponse{}
+	_ = restoreBodyParam{}
+	_ = apiHostResponse{}
+	_ = trashPathParam{}
+	_ = trashItemsResponse{}
+	_ = trashItemResponse{}
+	_ = reservationPathParam{}
+	_ = reservationBodyParam{}
+	_ = reservationsResponse{}
+	_ = reservationResponse{}
+	_ = reservedRangePathParams{}
+	_ = reservedRangeBodyParam{}
+	_ = reservedRangesResponse{}
+	_ = renumberParams{}
+	_ = renumberPlanResponse{}
+	_ = networkRangesBodyParam{}
+	_ = createNetworkTemplateParam{}
+	_ = networkCloneBodyParam{}
+	_ = networkTemplatePathParam{}
+	_ = networkTemplateBodyParam{}
+	_ = networkTemplateRequestBodyParam{}
+	_ = networkTemplatesResponse{}
+	_ = networkTemplateResponse{}
+	_ = networkPeeringPathParam{}
+	_ = networkPeeringBodyParam{}
+	_ = networkPeeringsResponse{}
+	_ = networkPeeringResponse{}
+	_ = labelSelectorParam{}
+	_ = selectorACLBodyParam{}
+	_ = bulkJobPathParam{}
+	_ = bulkHostRequestBodyParam{}
+	_ = bulkJobsResponse{}
+	_ = bulkJobResponse{}
+	_ = keyRotationPolicyBodyParam{}
+	_ = keyRotationStatusResponse{}
+	_ = approvalPolicyBodyParam{}
+	_ = nodeApprovalsResponse{}
+	_ = posturePolicyBodyParam{}
+	_ = postureStatusResponse{}
 	return false
 }
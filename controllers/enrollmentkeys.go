@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gravitl/netmaker/auth"
@@ -113,12 +112,7 @@ func createEnrollmentKey(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return
 	}
-	var newTime time.Time
-	if enrollmentKeyBody.Expiration > 0 {
-		newTime = time.Unix(enrollmentKeyBody.Expiration, 0)
-	}
-
-	newEnrollmentKey, err := logic.CreateEnrollmentKey(enrollmentKeyBody.UsesRemaining, newTime, enrollmentKeyBody.Networks, enrollmentKeyBody.Tags, enrollmentKeyBody.Unlimited)
+	newEnrollmentKey, err := logic.CreateEnrollmentKey(enrollmentKeyBody)
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"), "failed to create enrollment key:", err.Error())
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
@@ -185,6 +179,10 @@ func handleHostRegister(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return
 	}
+	if err := models.ValidateLabels(newHost.Labels); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
 	if newHost.TrafficKeyPublic == nil && newHost.OS != models.OS_Types.IoT {
 		err := fmt.Errorf("missing traffic key")
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
@@ -217,6 +215,8 @@ func handleHostRegister(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		}
+		logic.ApplyEnrollmentKeyLabels(&newHost, enrollmentKey)
+		newHost.Ephemeral = enrollmentKey.Ephemeral
 		if err = logic.CreateHost(&newHost); err != nil {
 			logger.Log(0, "host", newHost.ID.String(), newHost.Name, "failed registration -", err.Error())
 			logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
@@ -233,6 +233,13 @@ func handleHostRegister(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		enrollmentKey.Networks = networksToAdd
+		if currentHost, err := logic.GetHost(newHost.ID.String()); err == nil && len(enrollmentKey.Tags) > 0 {
+			logic.ApplyEnrollmentKeyLabels(currentHost, enrollmentKey)
+			if err := logic.UpsertHost(currentHost); err != nil {
+				logger.Log(0, "failed to label host", currentHost.Name, "with the tags of its enrollment key:", err.Error())
+			}
+			newHost.Labels = currentHost.Labels
+		}
 	}
 	// ready the response
 	server := servercfg.GetServerInfo()
@@ -250,5 +257,5 @@ func handleHostRegister(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(&response)
 	// notify host of changes, peer and node updates
-	go auth.CheckNetRegAndHostUpdate(enrollmentKey.Networks, &newHost)
+	go auth.CheckNetRegAndHostUpdate(enrollmentKey.Networks, &newHost, enrollmentKey.RequiresApproval)
 }
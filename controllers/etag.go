@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+)
+
+// errResourceModified - returned when an If-Match header does not match the current revision of a resource
+var errResourceModified = errors.New("resource has been modified since it was fetched, fetch it again and retry")
+
+// formatETag - formats a record revision as a strong ETag
+func formatETag(revision uint64) string {
+	return `"` + strconv.FormatUint(revision, 10) + `"`
+}
+
+// setETag - sets the ETag header to the current revision of a record, must be called before writing the status
+func setETag(w http.ResponseWriter, tableName, key string) {
+	revision, err := database.GetRevision(tableName, key)
+	if err != nil {
+		logger.Log(1, "failed to get revision of", tableName, key, err.Error())
+		return
+	}
+	w.Header().Set("ETag", formatETag(revision))
+}
+
+// checkIfMatch - compares the If-Match header against the current revision of a record and writes a 412 response if it
+// does not match, requests without an If-Match header (or with *) are always allowed
+// returns the matched revision, which the update must pass on so that its write fails if the record changes in between
+func checkIfMatch(w http.ResponseWriter, r *http.Request, tableName, key string) (*uint64, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil, true
+	}
+	revision, err := database.GetRevision(tableName, key)
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return nil, false
+	}
+	current := formatETag(revision)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true
+		}
+		if tag == current {
+			return &revision, true
+		}
+	}
+	logger.Log(1, r.Header.Get("user"), "rejected update of", tableName, key, "revision", current, "does not match", ifMatch)
+	logic.ReturnErrorResponse(w, r, logic.FormatError(errResourceModified, "preconditionfailed"))
+	return nil, false
+}
+
+// revisionErrorType - returns the error type of a failed revision guarded write, a 412 if the record was modified
+// after its If-Match header was checked
+func revisionErrorType(err error, errType string) string {
+	if errors.Is(err, database.ErrRevisionMismatch) {
+		return "preconditionfailed"
+	}
+	return errType
+}
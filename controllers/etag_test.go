@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	database.DeleteRecord(database.NETWORKS_TABLE_NAME, "etagnet")
+	assert.Nil(t, database.Insert("etagnet", `{"netid":"etagnet"}`, database.NETWORKS_TABLE_NAME))
+	revision, err := database.GetRevision(database.NETWORKS_TABLE_NAME, "etagnet")
+	assert.Nil(t, err)
+	current := formatETag(revision)
+	check := func(ifMatch string) (*uint64, bool, int) {
+		r := httptest.NewRequest(http.MethodPut, "/api/networks/etagnet", nil)
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		matched, ok := checkIfMatch(w, r, database.NETWORKS_TABLE_NAME, "etagnet")
+		return matched, ok, w.Code
+	}
+	t.Run("NoHeader", func(t *testing.T) {
+		matched, ok, _ := check("")
+		assert.True(t, ok)
+		assert.Nil(t, matched)
+	})
+	t.Run("Matching", func(t *testing.T) {
+		matched, ok, _ := check(current)
+		assert.True(t, ok)
+		assert.Equal(t, &revision, matched)
+		matched, ok, _ = check(`"999", ` + current)
+		assert.True(t, ok)
+		assert.Equal(t, &revision, matched)
+		matched, ok, _ = check("*")
+		assert.True(t, ok)
+		assert.Nil(t, matched)
+	})
+	t.Run("ModifiedAfterCheck", func(t *testing.T) {
+		matched, ok, _ := check(current)
+		assert.True(t, ok)
+		assert.Nil(t, database.Insert("etagnet", `{"netid":"etagnet"}`, database.NETWORKS_TABLE_NAME))
+		tx := database.BeginTx()
+		tx.ExpectRevision(database.NETWORKS_TABLE_NAME, "etagnet", *matched)
+		assert.Nil(t, tx.Insert("etagnet", `{"netid":"etagnet"}`, database.NETWORKS_TABLE_NAME))
+		err := tx.Commit()
+		assert.ErrorIs(t, err, database.ErrRevisionMismatch)
+		assert.Equal(t, "preconditionfailed", revisionErrorType(err, "internal"))
+	})
+	t.Run("Stale", func(t *testing.T) {
+		_, ok, code := check(current)
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusPreconditionFailed, code)
+	})
+	t.Run("SetETag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setETag(w, database.NETWORKS_TABLE_NAME, "etagnet")
+		assert.Equal(t, formatETag(revision+1), w.Header().Get("ETag"))
+	})
+	database.DeleteRecord(database.NETWORKS_TABLE_NAME, "etagnet")
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/gravitl/netmaker/database"
@@ -149,6 +150,9 @@ func getExtClient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if key, err := logic.GetRecordKey(client.ClientID, client.Network); err == nil {
+		setETag(w, database.EXT_CLIENT_TABLE_NAME, key)
+	}
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(client)
 }
@@ -216,14 +220,8 @@ func getExtClientConf(w http.ResponseWriter, r *http.Request) {
 	if network.DefaultKeepalive != 0 {
 		keepalive = "PersistentKeepalive = " + strconv.Itoa(int(network.DefaultKeepalive))
 	}
-	gwendpoint := host.EndpointIP.String() + ":" + strconv.Itoa(host.ListenPort)
-	newAllowedIPs := network.AddressRange
-	if newAllowedIPs != "" && network.AddressRange6 != "" {
-		newAllowedIPs += ","
-	}
-	if network.AddressRange6 != "" {
-		newAllowedIPs += network.AddressRange6
-	}
+	gwendpoint := net.JoinHostPort(host.EndpointIP.String(), strconv.Itoa(host.ListenPort))
+	newAllowedIPs := strings.Join(network.AllAddressRanges(), ",")
 	if egressGatewayRanges, err := logic.GetEgressRangesOnNetwork(&client); err == nil {
 		for _, egressGatewayRange := range egressGatewayRanges {
 			newAllowedIPs += "," + egressGatewayRange
@@ -442,6 +440,10 @@ func updateExtClient(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return
 	}
+	revision, ok := checkIfMatch(w, r, database.EXT_CLIENT_TABLE_NAME, key)
+	if !ok {
+		return
+	}
 	data, err := database.FetchRecord(database.EXT_CLIENT_TABLE_NAME, key)
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"),
@@ -487,12 +489,12 @@ func updateExtClient(w http.ResponseWriter, r *http.Request) {
 	}
 	// extra var need as logic.Update changes oldExtClient
 	currentClient := oldExtClient
-	newclient, err := logic.UpdateExtClient(&oldExtClient, &update)
+	newclient, err := logic.UpdateExtClientAtRevision(&oldExtClient, &update, revision)
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"),
 			fmt.Sprintf("failed to update ext client [%s], network [%s]: %v",
 				clientid, network, err))
-		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, "internal")))
 		return
 	}
 	logger.Log(0, r.Header.Get("user"), "updated ext client", update.ClientID)
@@ -503,6 +505,9 @@ func updateExtClient(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	if newKey, err := logic.GetRecordKey(newclient.ClientID, newclient.Network); err == nil {
+		setETag(w, database.EXT_CLIENT_TABLE_NAME, newKey)
+	}
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(newclient)
 	if changedID {
@@ -517,6 +522,7 @@ func updateExtClient(w http.ResponseWriter, r *http.Request) {
 // swagger:route DELETE /api/extclients/{network}/{clientid} ext_client deleteExtClient
 //
 // Delete an individual extclient.
+// The extclient can be restored from the trash until the retention period ends.
 //
 //			Schemes: https
 //
@@ -567,7 +573,7 @@ func deleteExtClient(w http.ResponseWriter, r *http.Request) {
 
 	// == END PRO ==
 
-	err = logic.DeleteExtClient(params["network"], params["clientid"])
+	err = logic.SoftDeleteExtClient(params["network"], params["clientid"], r.Header.Get("user"))
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"),
 			fmt.Sprintf("failed to delete extclient [%s],network [%s]: %v", clientid, network, err))
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/database"
 	"github.com/gravitl/netmaker/logger"
 	"github.com/gravitl/netmaker/logic"
 	"github.com/gravitl/netmaker/models"
@@ -18,7 +19,11 @@ import (
 func hostHandlers(r *mux.Router) {
 	r.HandleFunc("/api/hosts", logic.SecurityCheck(false, http.HandlerFunc(getHosts))).Methods(http.MethodGet)
 	r.HandleFunc("/api/hosts/keys", logic.SecurityCheck(true, http.HandlerFunc(updateAllKeys))).Methods(http.MethodPut)
+	r.HandleFunc("/api/hosts/bulk", logic.SecurityCheck(true, http.HandlerFunc(getBulkJobs))).Methods(http.MethodGet)
+	r.HandleFunc("/api/hosts/bulk", logic.SecurityCheck(true, http.HandlerFunc(createBulkJob))).Methods(http.MethodPost)
+	r.HandleFunc("/api/hosts/bulk/{jobid}", logic.SecurityCheck(true, http.HandlerFunc(getBulkJob))).Methods(http.MethodGet)
 	r.HandleFunc("/api/hosts/{hostid}/keys", logic.SecurityCheck(true, http.HandlerFunc(updateKeys))).Methods(http.MethodPut)
+	r.HandleFunc("/api/hosts/{hostid}", logic.SecurityCheck(true, http.HandlerFunc(getHost))).Methods(http.MethodGet)
 	r.HandleFunc("/api/hosts/{hostid}", logic.SecurityCheck(true, http.HandlerFunc(updateHost))).Methods(http.MethodPut)
 	r.HandleFunc("/api/hosts/{hostid}", logic.SecurityCheck(true, http.HandlerFunc(deleteHost))).Methods(http.MethodDelete)
 	r.HandleFunc("/api/hosts/{hostid}/networks/{network}", logic.SecurityCheck(true, http.HandlerFunc(addHostToNetwork))).Methods(http.MethodPost)
@@ -41,12 +46,18 @@ func hostHandlers(r *mux.Router) {
 //			Responses:
 //				200: getHostsSliceResponse
 func getHosts(w http.ResponseWriter, r *http.Request) {
+	selector, err := models.ParseLabelSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
 	currentHosts, err := logic.GetAllHosts()
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"), "failed to fetch hosts: ", err.Error())
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
+	currentHosts = logic.SelectHosts(currentHosts, selector)
 	apiHosts := logic.GetAllHostsAPI(currentHosts[:])
 	logger.Log(2, r.Header.Get("user"), "fetched all hosts")
 	logic.SortApiHosts(apiHosts[:])
@@ -54,6 +65,30 @@ func getHosts(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(apiHosts)
 }
 
+// swagger:route GET /api/hosts/{hostid} hosts getHost
+//
+// Gets a single host, the ETag header holds its current revision.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: apiHostResponse
+func getHost(w http.ResponseWriter, r *http.Request) {
+	hostid := mux.Vars(r)["hostid"]
+	host, err := logic.GetHost(hostid)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch host", hostid, err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "notfound"))
+		return
+	}
+	setETag(w, database.HOSTS_TABLE_NAME, hostid)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(host.ConvertNMHostToAPI())
+}
+
 // swagger:route GET /api/v1/host pull pullHost
 //
 // Used by clients for "pull" command
@@ -136,6 +171,10 @@ func updateHost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	revision, ok := checkIfMatch(w, r, database.HOSTS_TABLE_NAME, newHostData.ID)
+	if !ok {
+		return
+	}
 	// confirm host exists
 	currHost, err := logic.GetHost(newHostData.ID)
 	if err != nil {
@@ -144,12 +183,17 @@ func updateHost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := models.ValidateLabels(newHostData.Labels); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+
 	newHost := newHostData.ConvertAPIHostToNMHost(currHost)
 
 	logic.UpdateHost(newHost, currHost) // update the in memory struct values
-	if err = logic.UpsertHost(newHost); err != nil {
+	if err = logic.UpsertHostAtRevision(newHost, revision); err != nil {
 		logger.Log(0, r.Header.Get("user"), "failed to update a host:", err.Error())
-		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, "internal")))
 		return
 	}
 	// publish host update through MQ
@@ -180,6 +224,7 @@ func updateHost(w http.ResponseWriter, r *http.Request) {
 
 	apiHostData := newHost.ConvertNMHostToAPI()
 	logger.Log(2, r.Header.Get("user"), "updated host", newHost.ID.String())
+	setETag(w, database.HOSTS_TABLE_NAME, newHost.ID.String())
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(apiHostData)
 }
@@ -187,6 +232,7 @@ func updateHost(w http.ResponseWriter, r *http.Request) {
 // swagger:route DELETE /api/hosts/{hostid} hosts deleteHost
 //
 // Deletes a Netclient host from Netmaker server.
+// The host can be restored from the trash until the retention period ends.
 //
 //			Schemes: https
 //
@@ -207,7 +253,7 @@ func deleteHost(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
-	if err = logic.RemoveHost(currHost, forceDelete); err != nil {
+	if err = logic.SoftDeleteHost(currHost, forceDelete, r.Header.Get("user")); err != nil {
 		logger.Log(0, r.Header.Get("user"), "failed to delete a host:", err.Error())
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
@@ -301,12 +347,22 @@ func deleteHostFromNetwork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	node, err := logic.UpdateHostNetwork(currHost, network, false)
-	if err != nil {
+	if err := removeHostFromNetwork(currHost, network, forceDelete); err != nil {
 		logger.Log(0, r.Header.Get("user"), "failed to remove host from network:", hostid, network, err.Error())
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
+	logger.Log(2, r.Header.Get("user"), fmt.Sprintf("removed host %s from network %s", currHost.Name, network))
+	w.WriteHeader(http.StatusOK)
+}
+
+// removeHostFromNetwork - deletes the node of a host in a network, detaches it from relays and notifies the host and
+// its peers
+func removeHostFromNetwork(currHost *models.Host, network string, forceDelete bool) error {
+	node, err := logic.UpdateHostNetwork(currHost, network, false)
+	if err != nil {
+		return err
+	}
 	if node.IsRelayed {
 		// cleanup node from relayednodes on relay node
 		relayNode, err := logic.GetNodeByID(node.RelayedBy)
@@ -330,8 +386,7 @@ func deleteHostFromNetwork(w http.ResponseWriter, r *http.Request) {
 	node.PendingDelete = true
 	logger.Log(1, "deleting  node", node.ID.String(), "from host", currHost.Name)
 	if err := logic.DeleteNode(node, forceDelete); err != nil {
-		logic.ReturnErrorResponse(w, r, logic.FormatError(fmt.Errorf("failed to delete node"), "internal"))
-		return
+		return fmt.Errorf("failed to delete node: %w", err)
 	}
 	// notify node change
 	runUpdates(node, false)
@@ -344,8 +399,7 @@ func deleteHostFromNetwork(w http.ResponseWriter, r *http.Request) {
 			logger.Log(1, "error publishing dns update", err.Error())
 		}
 	}()
-	logger.Log(2, r.Header.Get("user"), fmt.Sprintf("removed host %s from network %s", currHost.Name, network))
-	w.WriteHeader(http.StatusOK)
+	return nil
 }
 
 // swagger:route POST /api/hosts/adm/authenticate hosts authenticateHost
@@ -538,6 +592,8 @@ func updateAllKeys(w http.ResponseWriter, r *http.Request) {
 			logger.Log(2, "updating host", host.ID.String(), " for a key update")
 			if err = mq.HostUpdate(&hostUpdate); err != nil {
 				logger.Log(0, "failed to send update to node during a network wide key update", host.ID.String(), err.Error())
+			} else if err = logic.KeyRotationRequested(&host); err != nil {
+				logger.Log(0, "failed to save key rotation request of host", host.ID.String(), err.Error())
 			}
 		}
 	}()
@@ -578,6 +634,8 @@ func updateKeys(w http.ResponseWriter, r *http.Request) {
 		}
 		if err = mq.HostUpdate(&hostUpdate); err != nil {
 			logger.Log(0, "failed to send host key update", host.ID.String(), err.Error())
+		} else if err = logic.KeyRotationRequested(host); err != nil {
+			logger.Log(0, "failed to save key rotation request of host", host.ID.String(), err.Error())
 		}
 	}()
 	logger.Log(2, r.Header.Get("user"), "updated key on host", host.Name)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -27,7 +28,24 @@ func networkHandlers(r *mux.Router) {
 	r.HandleFunc("/api/networks/{networkname}", logic.SecurityCheck(true, http.HandlerFunc(updateNetwork))).Methods(http.MethodPut)
 	// ACLs
 	r.HandleFunc("/api/networks/{networkname}/acls", logic.SecurityCheck(true, http.HandlerFunc(updateNetworkACL))).Methods(http.MethodPut)
+	r.HandleFunc("/api/networks/{networkname}/acls/selector", logic.SecurityCheck(true, http.HandlerFunc(updateNetworkACLBySelector))).Methods(http.MethodPut)
 	r.HandleFunc("/api/networks/{networkname}/acls", logic.SecurityCheck(true, http.HandlerFunc(getNetworkACL))).Methods(http.MethodGet)
+	r.HandleFunc("/api/networks/{networkname}/ranges", logic.SecurityCheck(true, http.HandlerFunc(updateNetworkRanges))).Methods(http.MethodPut)
+	r.HandleFunc("/api/networks/{networkname}/keyrotation", logic.SecurityCheck(true, http.HandlerFunc(getKeyRotationStatus))).Methods(http.MethodGet)
+	r.HandleFunc("/api/networks/{networkname}/keyrotation", logic.SecurityCheck(true, http.HandlerFunc(updateKeyRotationPolicy))).Methods(http.MethodPut)
+	r.HandleFunc("/api/networks/{networkname}/approval", logic.SecurityCheck(true, http.HandlerFunc(updateApprovalPolicy))).Methods(http.MethodPut)
+	r.HandleFunc("/api/networks/{networkname}/approvals", logic.SecurityCheck(true, http.HandlerFunc(getNodeApprovals))).Methods(http.MethodGet)
+	r.HandleFunc("/api/networks/{networkname}/posture", logic.SecurityCheck(true, http.HandlerFunc(getPostureStatus))).Methods(http.MethodGet)
+	r.HandleFunc("/api/networks/{networkname}/posture", logic.SecurityCheck(true, http.HandlerFunc(updatePosturePolicy))).Methods(http.MethodPut)
+	r.HandleFunc("/api/networks/{networkname}/clone", logic.SecurityCheck(true, checkFreeTierLimits(networks_l, http.HandlerFunc(cloneNetwork)))).Methods(http.MethodPost)
+	r.HandleFunc("/api/networks/{networkname}/renumber", logic.SecurityCheck(true, http.HandlerFunc(renumberNetwork))).Methods(http.MethodPost)
+	// address reservations
+	r.HandleFunc("/api/networks/{networkname}/reservations", logic.SecurityCheck(true, http.HandlerFunc(getAddressReservations))).Methods(http.MethodGet)
+	r.HandleFunc("/api/networks/{networkname}/reservations", logic.SecurityCheck(true, http.HandlerFunc(createAddressReservation))).Methods(http.MethodPost)
+	r.HandleFunc("/api/networks/{networkname}/reservations/{address}", logic.SecurityCheck(true, http.HandlerFunc(deleteAddressReservation))).Methods(http.MethodDelete)
+	r.HandleFunc("/api/networks/{networkname}/reserved-ranges", logic.SecurityCheck(true, http.HandlerFunc(getReservedAddressRanges))).Methods(http.MethodGet)
+	r.HandleFunc("/api/networks/{networkname}/reserved-ranges", logic.SecurityCheck(true, http.HandlerFunc(reserveAddressRange))).Methods(http.MethodPost)
+	r.HandleFunc("/api/networks/{networkname}/reserved-ranges/{start}/{end}", logic.SecurityCheck(true, http.HandlerFunc(unreserveAddressRange))).Methods(http.MethodDelete)
 }
 
 // swagger:route GET /api/networks networks getNetworks
@@ -98,6 +116,7 @@ func getNetwork(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Log(2, r.Header.Get("user"), "fetched network", netname)
+	setETag(w, database.NETWORKS_TABLE_NAME, netname)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(network)
 }
@@ -117,6 +136,10 @@ func updateNetworkACL(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var params = mux.Vars(r)
 	netname := params["networkname"]
+	revision, ok := checkIfMatch(w, r, database.NODE_ACLS_TABLE_NAME, netname)
+	if !ok {
+		return
+	}
 	var networkACLChange acls.ACLContainer
 	networkACLChange, err := networkACLChange.Get(acls.ContainerID(netname))
 	if err != nil {
@@ -132,11 +155,11 @@ func updateNetworkACL(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return
 	}
-	newNetACL, err := networkACLChange.Save(acls.ContainerID(netname))
+	newNetACL, err := networkACLChange.SaveAtRevision(acls.ContainerID(netname), revision)
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"),
 			fmt.Sprintf("failed to update ACLs for network [%s]: %v", netname, err))
-		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, "badrequest")))
 		return
 	}
 	logger.Log(1, r.Header.Get("user"), "updated ACLs for network", netname)
@@ -147,6 +170,53 @@ func updateNetworkACL(w http.ResponseWriter, r *http.Request) {
 			logger.Log(0, "failed to publish peer update after ACL update on", netname)
 		}
 	}
+	setETag(w, database.NODE_ACLS_TABLE_NAME, netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newNetACL)
+}
+
+// swagger:route PUT /api/networks/{networkname}/acls/selector networks updateNetworkACLBySelector
+//
+// Allow or deny traffic between every node matching one label selector and every node matching another, node labels
+// include the labels of their host.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: aclContainerResponse
+func updateNetworkACLBySelector(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	revision, ok := checkIfMatch(w, r, database.NODE_ACLS_TABLE_NAME, netname)
+	if !ok {
+		return
+	}
+	var request models.SelectorACLRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	newNetACL, err := logic.UpdateACLsBySelector(netname, request, revision)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to update ACLs by selector for network [%s]: %v", netname, err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, "badrequest")))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "updated ACLs between", request.From, "and", request.To, "for network", netname)
+
+	// send peer updates
+	if servercfg.IsMessageQueueBackend() {
+		if err = mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "failed to publish peer update after ACL update on", netname)
+		}
+	}
+	setETag(w, database.NODE_ACLS_TABLE_NAME, netname)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(newNetACL)
 }
@@ -171,6 +241,7 @@ func getNetworkACL(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if database.IsEmptyRecord(err) {
 			networkACL = acls.ACLContainer{}
+			setETag(w, database.NODE_ACLS_TABLE_NAME, netname)
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(networkACL)
 			return
@@ -181,13 +252,559 @@ func getNetworkACL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logger.Log(2, r.Header.Get("user"), "fetched acl for network", netname)
+	setETag(w, database.NODE_ACLS_TABLE_NAME, netname)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(networkACL)
 }
 
+// swagger:route PUT /api/networks/{networkname}/ranges networks updateNetworkRanges
+//
+// Replaces the secondary address ranges of a network. Addresses are allocated from them in order once the
+// primary range of their ip family is full. A range can only be removed once no node, ext client or reservation uses it.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkBodyResponse
+func updateNetworkRanges(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	revision, ok := checkIfMatch(w, r, database.NETWORKS_TABLE_NAME, netname)
+	if !ok {
+		return
+	}
+	var ranges []string
+	if err := json.NewDecoder(r.Body).Decode(&ranges); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	network, nodes, err := logic.UpdateNetworkRanges(netname, ranges, revision)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to update address ranges of network [%s]: %v", netname, err))
+		errType := "badrequest"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, errType)))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "updated secondary ranges of network", netname, strings.Join(network.SecondaryRanges, ","))
+	go func() {
+		for i := range nodes {
+			if err := mq.NodeUpdate(&nodes[i]); err != nil {
+				logger.Log(1, "error publishing node update to node", nodes[i].ID.String(), err.Error())
+			}
+		}
+		if err := mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "failed to publish peer update after updating ranges of", netname, err.Error())
+		}
+	}()
+	setETag(w, database.NETWORKS_TABLE_NAME, netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(network)
+}
+
+// swagger:route GET /api/networks/{networkname}/keyrotation networks getKeyRotationStatus
+//
+// Gets the key rotation policy of a network along with the key age of each of its hosts.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: keyRotationStatusResponse
+func getKeyRotationStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	status, err := logic.GetKeyRotationStatus(netname)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch key rotation status of network [%s]: %v", netname, err))
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
+
+// swagger:route PUT /api/networks/{networkname}/keyrotation networks updateKeyRotationPolicy
+//
+// Sets how many days the hosts of a network keep their wireguard keys. Online hosts with older keys are
+// asked to rotate them a few at a time, offline hosts once they check in. 0 disables rotation.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkBodyResponse
+func updateKeyRotationPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	revision, ok := checkIfMatch(w, r, database.NETWORKS_TABLE_NAME, netname)
+	if !ok {
+		return
+	}
+	var policy models.KeyRotationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	network, err := logic.SetKeyRotationPolicy(netname, policy, revision)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to update key rotation policy of network [%s]: %v", netname, err))
+		errType := "badrequest"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, errType)))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "set key rotation of network", netname, "to", strconv.Itoa(int(policy.IntervalDays)), "days")
+	setETag(w, database.NETWORKS_TABLE_NAME, netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(network)
+}
+
+// swagger:route PUT /api/networks/{networkname}/approval networks updateApprovalPolicy
+//
+// Sets whether the nodes of hosts registering themselves into a network stay pending until an admin approves them.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkBodyResponse
+func updateApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	revision, ok := checkIfMatch(w, r, database.NETWORKS_TABLE_NAME, netname)
+	if !ok {
+		return
+	}
+	var policy models.ApprovalPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	network, err := logic.SetApprovalPolicy(netname, policy, revision)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to update approval policy of network [%s]: %v", netname, err))
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, errType)))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "set requires approval of network", netname, "to", strconv.FormatBool(policy.RequiresApproval))
+	setETag(w, database.NETWORKS_TABLE_NAME, netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(network)
+}
+
+// swagger:route GET /api/networks/{networkname}/approvals networks getNodeApprovals
+//
+// Lists the approvals and rejections of nodes in a network, newest first.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: nodeApprovalsResponse
+func getNodeApprovals(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	approvals, err := logic.GetNodeApprovals(netname)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch node approvals of network [%s]: %v", netname, err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(approvals)
+}
+
+// swagger:route GET /api/networks/{networkname}/posture networks getPostureStatus
+//
+// Gets the posture policy of a network along with the nodes quarantined for failing it and why.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: postureStatusResponse
+func getPostureStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	status, err := logic.GetPostureStatus(netname)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch posture status of network [%s]: %v", netname, err))
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
+
+// swagger:route PUT /api/networks/{networkname}/posture networks updatePosturePolicy
+//
+// Sets the requirements the hosts of a network must meet, such as a minimum netclient version or a firewall.
+// Nodes of hosts that fail them are quarantined until the hosts comply, an empty policy removes them.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkBodyResponse
+func updatePosturePolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	revision, ok := checkIfMatch(w, r, database.NETWORKS_TABLE_NAME, netname)
+	if !ok {
+		return
+	}
+	var policy models.PosturePolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	network, err := logic.SetPosturePolicy(netname, policy, revision)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to update posture policy of network [%s]: %v", netname, err))
+		errType := "badrequest"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, errType)))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "updated posture policy of network", netname)
+	setETag(w, database.NETWORKS_TABLE_NAME, netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(network)
+	go func() {
+		changed, err := logic.EvaluateNetworkPosture(netname)
+		if err != nil {
+			logger.Log(0, "failed to check posture of the hosts of network", netname, err.Error())
+		}
+		if changed {
+			if err := mq.PublishPeerUpdate(); err != nil {
+				logger.Log(0, "failed to publish peer update after posture change", err.Error())
+			}
+		}
+	}()
+}
+
+// swagger:route POST /api/networks/{networkname}/renumber networks renumberNetwork
+//
+// Moves a network to new address ranges. The addresses of its nodes, ext clients, custom DNS entries,
+// reservations and gateway ranges are mapped to the new ranges in a single transaction and pushed to the hosts.
+// With dryrun=true only the plan is returned.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: renumberPlanResponse
+func renumberNetwork(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	dryRun := r.URL.Query().Get("dryrun") == "true"
+	var request models.RenumberRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	var plan *models.RenumberPlan
+	var err error
+	if dryRun {
+		plan, err = logic.PlanRenumber(netname, request)
+	} else {
+		plan, err = logic.RenumberNetwork(netname, request)
+	}
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to renumber network [%s]: %v", netname, err))
+		errType := "badrequest"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	if !dryRun {
+		logger.Log(0, r.Header.Get("user"), "renumbered network", netname, "to", plan.NewAddressRange, plan.NewAddressRange6)
+		if servercfg.IsDNSMode() {
+			if err := logic.SetDNS(); err != nil {
+				logger.Log(0, "failed to set dns after renumbering network", netname, err.Error())
+			}
+		}
+		go func() {
+			if err := mq.PublishRenumber(plan); err != nil {
+				logger.Log(0, "failed to publish renumbered network", netname, err.Error())
+			}
+		}()
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// swagger:route GET /api/networks/{networkname}/reservations networks getAddressReservations
+//
+// Lists the addresses of a network that are reserved for specific hosts.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: reservationsResponse
+func getAddressReservations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	if _, err := logic.GetNetwork(netname); err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch network [%s] info: %v", netname, err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "notfound"))
+		return
+	}
+	reservations, err := logic.GetAddressReservations(netname)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch address reservations for network [%s]: %v", netname, err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	logger.Log(2, r.Header.Get("user"), "fetched address reservations for network", netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reservations)
+}
+
+// swagger:route POST /api/networks/{networkname}/reservations networks createAddressReservation
+//
+// Reserves an address of a network for a host id or host name, the host is given the address when it joins the network.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: reservationResponse
+func createAddressReservation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	var reservation models.AddressReservation
+	if err := json.NewDecoder(r.Body).Decode(&reservation); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	reservation, err := logic.CreateAddressReservation(netname, reservation)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to reserve address [%s] on network [%s]: %v", reservation.Address, netname, err))
+		errType := "badrequest"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		} else if errors.Is(err, logic.ErrReservationConflict) {
+			errType = "conflict"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "reserved address", reservation.Address, "on network", netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reservation)
+}
+
+// swagger:route DELETE /api/networks/{networkname}/reservations/{address} networks deleteAddressReservation
+//
+// Removes an address reservation, a node already using the address keeps it.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: stringJSONResponse
+func deleteAddressReservation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var params = mux.Vars(r)
+	netname := params["networkname"]
+	address := params["address"]
+	if err := logic.DeleteAddressReservation(netname, address); err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to delete address reservation [%s] on network [%s]: %v", address, netname, err))
+		errType := "internal"
+		if errors.Is(err, logic.ErrReservationNotFound) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "deleted address reservation", address, "on network", netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode("success")
+}
+
+// swagger:route GET /api/networks/{networkname}/reserved-ranges networks getReservedAddressRanges
+//
+// Lists the address ranges of a network that are never handed out.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: reservedRangesResponse
+func getReservedAddressRanges(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	if _, err := logic.GetNetwork(netname); err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch network [%s] info: %v", netname, err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "notfound"))
+		return
+	}
+	ranges, err := logic.GetReservedAddressRanges(netname)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch reserved address ranges for network [%s]: %v", netname, err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	logger.Log(2, r.Header.Get("user"), "fetched reserved address ranges for network", netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ranges)
+}
+
+// swagger:route POST /api/networks/{networkname}/reserved-ranges networks reserveAddressRange
+//
+// Keeps an address range of a network from being handed out, nodes and ext clients already using its addresses keep them.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: reservedRangesResponse
+func reserveAddressRange(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	var addressRange models.AddressRange
+	if err := json.NewDecoder(r.Body).Decode(&addressRange); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	if err := logic.ReserveAddressRange(netname, addressRange); err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to reserve address range [%s - %s] on network [%s]: %v", addressRange.Start, addressRange.End, netname, err))
+		errType := "badrequest"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	ranges, err := logic.GetReservedAddressRanges(netname)
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "reserved address range", addressRange.Start, "-", addressRange.End, "on network", netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ranges)
+}
+
+// swagger:route DELETE /api/networks/{networkname}/reserved-ranges/{start}/{end} networks unreserveAddressRange
+//
+// Lets a reserved address range of a network be handed out again.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: reservedRangesResponse
+func unreserveAddressRange(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var params = mux.Vars(r)
+	netname := params["networkname"]
+	addressRange := models.AddressRange{Start: params["start"], End: params["end"]}
+	if err := logic.UnreserveAddressRange(netname, addressRange); err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to unreserve address range [%s - %s] on network [%s]: %v", addressRange.Start, addressRange.End, netname, err))
+		errType := "internal"
+		if errors.Is(err, logic.ErrReservationNotFound) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	ranges, err := logic.GetReservedAddressRanges(netname)
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "unreserved address range", addressRange.Start, "-", addressRange.End, "on network", netname)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ranges)
+}
+
 // swagger:route DELETE /api/networks/{networkname} networks deleteNetwork
 //
 // Delete a network.  Will not delete if there are any nodes that belong to the network.
+// The network can be restored from the trash until the retention period ends.
 //
 //			Schemes: https
 //
@@ -202,7 +819,7 @@ func deleteNetwork(w http.ResponseWriter, r *http.Request) {
 
 	var params = mux.Vars(r)
 	network := params["networkname"]
-	err := logic.DeleteNetwork(network)
+	err := logic.SoftDeleteNetwork(network, r.Header.Get("user"))
 	if err != nil {
 		errtype := "badrequest"
 		if strings.Contains(err.Error(), "Node check failed") {
@@ -221,7 +838,8 @@ func deleteNetwork(w http.ResponseWriter, r *http.Request) {
 
 // swagger:route POST /api/networks networks createNetwork
 //
-// Create a network.
+// Create a network, with template=name the network is created from a network template and only its name and
+// address ranges are taken from the body.
 //
 //			Schemes: https
 //
@@ -253,7 +871,21 @@ func createNetwork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	network, err = logic.CreateNetwork(network)
+	if templateName := r.URL.Query().Get("template"); templateName != "" {
+		template, err := logic.GetNetworkTemplate(templateName)
+		if err != nil {
+			logger.Log(0, r.Header.Get("user"), "failed to fetch network template", templateName, err.Error())
+			errType := "internal"
+			if database.IsEmptyRecord(err) {
+				errType = "notfound"
+			}
+			logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+			return
+		}
+		network, err = logic.CreateNetworkFromTemplate(template, network)
+	} else {
+		network, err = logic.CreateNetwork(network)
+	}
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"), "failed to create network: ",
 			err.Error())
@@ -261,14 +893,77 @@ func createNetwork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = addDefaultHostsToNetwork(r.Header.Get("user"), network.NetID); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+
+	logger.Log(1, r.Header.Get("user"), "created network", network.NetID)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(network)
+}
+
+// swagger:route POST /api/networks/{networkname}/clone networks cloneNetwork
+//
+// Create a network with the settings, pro settings, default ACL policy, custom DNS entries and enrollment keys of
+// an existing network. Custom DNS entries keep their offset in the new address ranges.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkBodyResponse
+func cloneNetwork(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	netname := mux.Vars(r)["networkname"]
+	var request models.NetworkCloneRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	if request.AddressRange == "" && request.AddressRange6 == "" {
+		err := errors.New("IPv4 or IPv6 CIDR required")
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	network, err := logic.CloneNetwork(netname, request)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to clone network [%s]: %v", netname, err))
+		errType := "badrequest"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	if servercfg.IsDNSMode() {
+		if err := logic.SetDNS(); err != nil {
+			logger.Log(0, "failed to set dns after cloning network", netname, err.Error())
+		}
+	}
+	if err = addDefaultHostsToNetwork(r.Header.Get("user"), network.NetID); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "cloned network", netname, "to", network.NetID)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(network)
+}
+
+// addDefaultHostsToNetwork - joins the default hosts of the server to a new network
+func addDefaultHostsToNetwork(user, netID string) error {
 	defaultHosts := logic.GetDefaultHosts()
 	for i := range defaultHosts {
 		currHost := &defaultHosts[i]
-		newNode, err := logic.UpdateHostNetwork(currHost, network.NetID, true)
+		newNode, err := logic.UpdateHostNetwork(currHost, netID, true)
 		if err != nil {
-			logger.Log(0, r.Header.Get("user"), "failed to add host to network:", currHost.ID.String(), network.NetID, err.Error())
-			logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
-			return
+			logger.Log(0, user, "failed to add host to network:", currHost.ID.String(), netID, err.Error())
+			return err
 		}
 		logger.Log(1, "added new node", newNode.ID.String(), "to host", currHost.Name)
 		if err = mq.HostUpdate(&models.HostUpdate{
@@ -276,13 +971,10 @@ func createNetwork(w http.ResponseWriter, r *http.Request) {
 			Host:   *currHost,
 			Node:   *newNode,
 		}); err != nil {
-			logger.Log(0, r.Header.Get("user"), "failed to add host to network:", currHost.ID.String(), network.NetID, err.Error())
+			logger.Log(0, user, "failed to add host to network:", currHost.ID.String(), netID, err.Error())
 		}
 	}
-
-	logger.Log(1, r.Header.Get("user"), "created network", network.NetID)
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(network)
+	return nil
 }
 
 // swagger:route PUT /api/networks networks updateNetwork
@@ -310,6 +1002,10 @@ func updateNetwork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	revision, ok := checkIfMatch(w, r, database.NETWORKS_TABLE_NAME, payload.NetID)
+	if !ok {
+		return
+	}
 	netOld1, err := logic.GetNetwork(payload.NetID)
 	if err != nil {
 		slog.Info("error fetching network", "user", r.Header.Get("user"), "err", err)
@@ -319,14 +1015,15 @@ func updateNetwork(w http.ResponseWriter, r *http.Request) {
 	// partial update
 	netOld2 := netOld1
 	netOld2.ProSettings = payload.ProSettings
-	_, _, _, _, _, err = logic.UpdateNetwork(&netOld1, &netOld2)
+	_, _, _, _, _, err = logic.UpdateNetworkAtRevision(&netOld1, &netOld2, revision)
 	if err != nil {
 		slog.Info("failed to update network", "user", r.Header.Get("user"), "err", err)
-		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, "badrequest")))
 		return
 	}
 
 	slog.Info("updated network", "network", payload.NetID, "user", r.Header.Get("user"))
+	setETag(w, database.NETWORKS_TABLE_NAME, netOld1.NetID)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(payload)
 }
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+)
+
+func networkTemplateHandlers(r *mux.Router) {
+	r.HandleFunc("/api/network-templates", logic.SecurityCheck(true, http.HandlerFunc(getNetworkTemplates))).Methods(http.MethodGet)
+	r.HandleFunc("/api/network-templates", logic.SecurityCheck(true, http.HandlerFunc(createNetworkTemplate))).Methods(http.MethodPost)
+	r.HandleFunc("/api/network-templates/{templatename}", logic.SecurityCheck(true, http.HandlerFunc(getNetworkTemplate))).Methods(http.MethodGet)
+	r.HandleFunc("/api/network-templates/{templatename}", logic.SecurityCheck(true, http.HandlerFunc(deleteNetworkTemplate))).Methods(http.MethodDelete)
+	r.HandleFunc("/api/networks/{networkname}/template", logic.SecurityCheck(true, http.HandlerFunc(saveNetworkTemplate))).Methods(http.MethodPost)
+}
+
+// swagger:route GET /api/network-templates networktemplates getNetworkTemplates
+//
+// Lists the templates new networks can be created from.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkTemplatesResponse
+func getNetworkTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := logic.GetNetworkTemplates()
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch network templates:", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
+
+// swagger:route GET /api/network-templates/{templatename} networktemplates getNetworkTemplate
+//
+// Get a network template.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkTemplateResponse
+func getNetworkTemplate(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["templatename"]
+	template, err := logic.GetNetworkTemplate(name)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch network template", name, err.Error())
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// swagger:route POST /api/network-templates networktemplates createNetworkTemplate
+//
+// Create or replace a network template.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkTemplateResponse
+func createNetworkTemplate(w http.ResponseWriter, r *http.Request) {
+	var template models.NetworkTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	template, err := logic.CreateNetworkTemplate(template)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to create network template:", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "created network template", template.Name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// swagger:route POST /api/networks/{networkname}/template networktemplates saveNetworkTemplate
+//
+// Save the settings, custom DNS entries and enrollment keys of a network as a template.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkTemplateResponse
+func saveNetworkTemplate(w http.ResponseWriter, r *http.Request) {
+	netname := mux.Vars(r)["networkname"]
+	var request models.NetworkTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	template, err := logic.NetworkTemplateFromNetwork(netname, request)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to capture network [%s] as a template: %v", netname, err))
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	if template, err = logic.CreateNetworkTemplate(template); err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to create network template:", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "saved network", netname, "as template", template.Name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// swagger:route DELETE /api/network-templates/{templatename} networktemplates deleteNetworkTemplate
+//
+// Delete a network template, networks created from it are not changed.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: stringJSONResponse
+func deleteNetworkTemplate(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["templatename"]
+	if err := logic.DeleteNetworkTemplate(name); err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to delete network template", name, err.Error())
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "deleted network template", name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(name + " deleted.")
+}
@@ -31,6 +31,8 @@ func nodeHandlers(r *mux.Router) {
 	r.HandleFunc("/api/nodes/{network}/{nodeid}/deletegateway", Authorize(false, true, "user", http.HandlerFunc(deleteEgressGateway))).Methods(http.MethodDelete)
 	r.HandleFunc("/api/nodes/{network}/{nodeid}/createingress", logic.SecurityCheck(false, http.HandlerFunc(createIngressGateway))).Methods(http.MethodPost)
 	r.HandleFunc("/api/nodes/{network}/{nodeid}/deleteingress", logic.SecurityCheck(false, http.HandlerFunc(deleteIngressGateway))).Methods(http.MethodDelete)
+	r.HandleFunc("/api/nodes/{network}/{nodeid}/approve", Authorize(false, true, "user", http.HandlerFunc(approveNode))).Methods(http.MethodPost)
+	r.HandleFunc("/api/nodes/{network}/{nodeid}/reject", Authorize(false, true, "user", http.HandlerFunc(rejectNode))).Methods(http.MethodPost)
 	r.HandleFunc("/api/nodes/{network}/{nodeid}", Authorize(true, true, "node", http.HandlerFunc(updateNode))).Methods(http.MethodPost)
 	r.HandleFunc("/api/nodes/adm/{network}/authenticate", authenticate).Methods(http.MethodPost)
 	r.HandleFunc("/api/v1/nodes/migrate", migrate).Methods(http.MethodPost)
@@ -280,6 +282,11 @@ func getNetworkNodes(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var params = mux.Vars(r)
 	networkName := params["network"]
+	selector, err := models.ParseLabelSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
 	nodes, err := logic.GetNetworkNodes(networkName)
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"),
@@ -287,6 +294,7 @@ func getNetworkNodes(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
+	nodes = logic.SelectNodes(nodes, selector)
 
 	// returns all the nodes in JSON/API format
 	apiNodes := logic.GetAllNodesAPI(nodes[:])
@@ -317,6 +325,11 @@ func getAllNodes(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
+	selector, err := models.ParseLabelSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
 	var nodes []models.Node
 	if user.IsAdmin || r.Header.Get("ismasterkey") == "yes" {
 		nodes, err = logic.GetAllNodes()
@@ -334,6 +347,7 @@ func getAllNodes(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	nodes = logic.SelectNodes(nodes, selector)
 	// return all the nodes in JSON/API format
 	apiNodes := logic.GetAllNodesAPI(nodes[:])
 	logger.Log(3, r.Header.Get("user"), "fetched all nodes they have access to")
@@ -415,6 +429,7 @@ func getNode(w http.ResponseWriter, r *http.Request) {
 		PeerIDs:      hostPeerUpdate.PeerIDs,
 	}
 
+	setETag(w, database.NODES_TABLE_NAME, node.ID.String())
 	if servercfg.Is_EE && nodeRequest {
 		if err = logic.EnterpriseResetAllPeersFailovers(node.ID, node.Network); err != nil {
 			logger.Log(1, "failed to reset failover list during node config pull", node.ID.String(), node.Network)
@@ -605,6 +620,99 @@ func deleteIngressGateway(w http.ResponseWriter, r *http.Request) {
 	runUpdates(&node, true)
 }
 
+// swagger:route POST /api/nodes/{network}/{nodeid}/approve nodes approveNode
+//
+// Approves a node pending approval, it gets its peers and becomes a peer of the other nodes of its network.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: nodeResponse
+func approveNode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var params = mux.Vars(r)
+	node, err := getPendingNode(params["network"], params["nodeid"])
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to approve node [%s] on network [%s]: %v", params["nodeid"], params["network"], err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	if err := logic.ApproveNode(&node, r.Header.Get("user")); err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to approve node [%s] on network [%s]: %v", params["nodeid"], params["network"], err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(node.ConvertToAPINode())
+	runUpdates(&node, true)
+	go func() {
+		if err := mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "failed to publish peer update after approving node", node.ID.String(), err.Error())
+		}
+	}()
+}
+
+// swagger:route POST /api/nodes/{network}/{nodeid}/reject nodes rejectNode
+//
+// Rejects a node pending approval, the node is removed from its network.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: nodeResponse
+func rejectNode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var params = mux.Vars(r)
+	node, err := getPendingNode(params["network"], params["nodeid"])
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to reject node [%s] on network [%s]: %v", params["nodeid"], params["network"], err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	host, err := logic.GetHost(node.HostID.String())
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to fetch host of node [%s]: %v", node.ID.String(), err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	if err := removeHostFromNetwork(host, node.Network, true); err != nil {
+		logger.Log(0, r.Header.Get("user"),
+			fmt.Sprintf("failed to reject node [%s] on network [%s]: %v", params["nodeid"], params["network"], err))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	if err := logic.RecordNodeApproval(&node, models.NodeRejected, r.Header.Get("user")); err != nil {
+		logger.Log(0, "failed to record rejection of node", node.ID.String(), err.Error())
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(node.ConvertToAPINode())
+}
+
+// getPendingNode - returns a node of a network that is waiting for approval
+func getPendingNode(network, nodeID string) (models.Node, error) {
+	node, err := logic.GetNodeByID(nodeID)
+	if err != nil {
+		return node, err
+	}
+	if node.Network != network {
+		return node, fmt.Errorf("node %s is not in network %s", nodeID, network)
+	}
+	if !node.IsPending {
+		return node, fmt.Errorf("node %s is not pending approval", nodeID)
+	}
+	return node, nil
+}
+
 // swagger:route PUT /api/nodes/{network}/{nodeid} nodes updateNode
 //
 // Update an individual node.
@@ -623,6 +731,10 @@ func updateNode(w http.ResponseWriter, r *http.Request) {
 
 	//start here
 	nodeid := params["nodeid"]
+	revision, ok := checkIfMatch(w, r, database.NODES_TABLE_NAME, nodeid)
+	if !ok {
+		return
+	}
 	currentNode, err := logic.GetNodeByID(nodeid)
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"),
@@ -639,6 +751,10 @@ func updateNode(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return
 	}
+	if err := models.ValidateLabels(newData.Labels); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
 	newNode := newData.ConvertToServerNode(&currentNode)
 	relayupdate := false
 	if servercfg.Is_EE && newNode.IsRelay && len(newNode.RelayedNodes) > 0 {
@@ -667,11 +783,11 @@ func updateNode(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = logic.UpdateNode(&currentNode, newNode)
+	err = logic.UpdateNodeAtRevision(&currentNode, newNode, revision)
 	if err != nil {
 		logger.Log(0, r.Header.Get("user"),
 			fmt.Sprintf("failed to update node info [ %s ] info: %v", nodeid, err))
-		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, revisionErrorType(err, "internal")))
 		return
 	}
 	if relayupdate {
@@ -688,6 +804,7 @@ func updateNode(w http.ResponseWriter, r *http.Request) {
 
 	apiNode := newNode.ConvertToAPINode()
 	logger.Log(1, r.Header.Get("user"), "updated node", currentNode.ID.String(), "on network", currentNode.Network)
+	setETag(w, database.NODES_TABLE_NAME, newNode.ID.String())
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(apiNode)
 	runUpdates(newNode, ifaceDelta)
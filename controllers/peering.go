@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/mq"
+)
+
+func peeringHandlers(r *mux.Router) {
+	r.HandleFunc("/api/peerings", logic.SecurityCheck(true, http.HandlerFunc(getNetworkPeerings))).Methods(http.MethodGet)
+	r.HandleFunc("/api/peerings", logic.SecurityCheck(true, http.HandlerFunc(createNetworkPeering))).Methods(http.MethodPost)
+	r.HandleFunc("/api/peerings/{peeringid}", logic.SecurityCheck(true, http.HandlerFunc(getNetworkPeering))).Methods(http.MethodGet)
+	r.HandleFunc("/api/peerings/{peeringid}", logic.SecurityCheck(true, http.HandlerFunc(deleteNetworkPeering))).Methods(http.MethodDelete)
+}
+
+// swagger:route GET /api/peerings peerings getNetworkPeerings
+//
+// Lists the peerings between networks.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkPeeringsResponse
+func getNetworkPeerings(w http.ResponseWriter, r *http.Request) {
+	peerings, err := logic.GetNetworkPeerings()
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch network peerings:", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(peerings)
+}
+
+// swagger:route GET /api/peerings/{peeringid} peerings getNetworkPeering
+//
+// Get a peering between networks.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkPeeringResponse
+func getNetworkPeering(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["peeringid"]
+	peering, err := logic.GetNetworkPeering(id)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch network peering", id, err.Error())
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(peering)
+}
+
+// swagger:route POST /api/peerings peerings createNetworkPeering
+//
+// Peer two networks through a host that has a gateway node in both, each gateway routes the address ranges of the
+// other network to the nodes its network ACLs let reach it.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: networkPeeringResponse
+func createNetworkPeering(w http.ResponseWriter, r *http.Request) {
+	var request models.NetworkPeeringRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ",
+			err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	peering, err := logic.CreateNetworkPeering(request)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to peer networks", request.NetworkA, "and", request.NetworkB, err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "peered networks", peering.NetworkA, "and", peering.NetworkB)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(peering)
+	go func() {
+		if err := mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "failed to publish peer update after peering", peering.ID, err.Error())
+		}
+	}()
+}
+
+// swagger:route DELETE /api/peerings/{peeringid} peerings deleteNetworkPeering
+//
+// Delete a peering, the networks stop routing to each other.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: stringJSONResponse
+func deleteNetworkPeering(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["peeringid"]
+	if err := logic.DeleteNetworkPeering(id); err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to delete network peering", id, err.Error())
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "deleted network peering", id)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(id + " deleted.")
+	go func() {
+		if err := mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "failed to publish peer update after deleting peering", id, err.Error())
+		}
+	}()
+}
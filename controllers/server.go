@@ -2,15 +2,19 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
 	"github.com/gravitl/netmaker/logic"
 	"github.com/gravitl/netmaker/models"
 	"github.com/gravitl/netmaker/mq"
 	"github.com/gravitl/netmaker/servercfg"
+	"github.com/gravitl/netmaker/serverctl"
 )
 
 func serverHandlers(r *mux.Router) {
@@ -23,6 +27,8 @@ func serverHandlers(r *mux.Router) {
 	r.HandleFunc("/api/server/getserverinfo", Authorize(true, false, "node", http.HandlerFunc(getServerInfo))).Methods(http.MethodGet)
 	r.HandleFunc("/api/server/status", http.HandlerFunc(getStatus)).Methods(http.MethodGet)
 	r.HandleFunc("/api/server/usage", Authorize(true, false, "user", http.HandlerFunc(getUsage))).Methods(http.MethodGet)
+	r.HandleFunc("/api/server/backup", logic.SecurityCheck(true, http.HandlerFunc(backupServer))).Methods(http.MethodPost)
+	r.HandleFunc("/api/server/restore", logic.SecurityCheck(true, http.HandlerFunc(restoreServer))).Methods(http.MethodPost)
 }
 func getUsage(w http.ResponseWriter, r *http.Request) {
 	type usage struct {
@@ -160,3 +166,81 @@ func getConfig(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(scfg)
 	//w.WriteHeader(http.StatusOK)
 }
+
+// swagger:route POST /api/server/backup server backupServer
+//
+// Export every table of the server to a versioned archive encrypted with a passphrase, an unencrypted archive is only
+// written when insecure is set.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: backupArchiveResponse
+func backupServer(w http.ResponseWriter, r *http.Request) {
+	var req models.BackupRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Log(0, r.Header.Get("user"), "error decoding request body: ", err.Error())
+			logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+			return
+		}
+	}
+	archive, err := serverctl.CreateBackup(req)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to create server backup:", err.Error())
+		errType := "internal"
+		if errors.Is(err, serverctl.ErrBackupUnencrypted) {
+			errType = "badrequest"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(0, r.Header.Get("user"), "created server backup, encrypted:", models.FormatBool(archive.Encrypted))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(archive)
+}
+
+// swagger:route POST /api/server/restore server restoreServer
+//
+// Replace the contents of the server with a backup archive and push the restored state to hosts.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: successResponse
+func restoreServer(w http.ResponseWriter, r *http.Request) {
+	var req models.RestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log(0, r.Header.Get("user"), "error decoding request body: ", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	if err := serverctl.RestoreBackup(&req.Archive, req.Passphrase); err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to restore server backup:", err.Error())
+		errType := "internal"
+		if errors.Is(err, serverctl.ErrBackupPassphrase) || errors.Is(err, serverctl.ErrBackupVersion) {
+			errType = "badrequest"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(0, r.Header.Get("user"), "restored server backup created at", time.Unix(req.Archive.CreatedAt, 0).String())
+	if servercfg.IsDNSMode() {
+		if err := logic.SetDNS(); err != nil {
+			logger.Log(0, "failed to set DNS after restore:", err.Error())
+		}
+	}
+	go func() {
+		if err := mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "failed to publish peer update after restore: ", err.Error())
+		}
+	}()
+	logic.ReturnSuccessResponse(w, r, "server restored, users must log in again")
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/mq"
+)
+
+func trashHandlers(r *mux.Router) {
+	r.HandleFunc("/api/trash", logic.SecurityCheck(true, http.HandlerFunc(getTrash))).Methods(http.MethodGet)
+	r.HandleFunc("/api/trash/{trashid}/restore", logic.SecurityCheck(true, http.HandlerFunc(restoreTrash))).Methods(http.MethodPost)
+	r.HandleFunc("/api/trash/{trashid}", logic.SecurityCheck(true, http.HandlerFunc(purgeTrash))).Methods(http.MethodDelete)
+}
+
+// swagger:route GET /api/trash trash getTrash
+//
+// Lists the deleted networks, hosts and ext clients that can still be restored.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: trashItemsResponse
+func getTrash(w http.ResponseWriter, r *http.Request) {
+	items, err := logic.GetTrash()
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to fetch trash:", err.Error())
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+// swagger:route POST /api/trash/{trashid}/restore trash restoreTrash
+//
+// Restores a deleted network, host or ext client with its original addresses, keys, ACL rows and DNS entries.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: trashItemResponse
+func restoreTrash(w http.ResponseWriter, r *http.Request) {
+	trashID := mux.Vars(r)["trashid"]
+	item, err := logic.RestoreTrash(trashID)
+	if err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to restore", trashID, "from trash:", err.Error())
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		} else if errors.Is(err, logic.ErrRestoreConflict) {
+			errType = "conflict"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(0, r.Header.Get("user"), "restored", string(item.Kind), item.Name, "from trash")
+	go func() {
+		if err := mq.PublishPeerUpdate(); err != nil {
+			logger.Log(0, "failed to publish peer update after restore: ", err.Error())
+		}
+	}()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(item)
+}
+
+// swagger:route DELETE /api/trash/{trashid} trash purgeTrash
+//
+// Permanently deletes an object from the trash.
+//
+//			Schemes: https
+//
+//			Security:
+//	  		oauth
+//
+//			Responses:
+//				200: successResponse
+func purgeTrash(w http.ResponseWriter, r *http.Request) {
+	trashID := mux.Vars(r)["trashid"]
+	if err := logic.PurgeTrash(trashID); err != nil {
+		logger.Log(0, r.Header.Get("user"), "failed to purge", trashID, "from trash:", err.Error())
+		errType := "internal"
+		if database.IsEmptyRecord(err) {
+			errType = "notfound"
+		}
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, errType))
+		return
+	}
+	logger.Log(0, r.Header.Get("user"), "purged", trashID, "from trash")
+	logic.ReturnSuccessResponse(w, r, "purged "+trashID+" from trash")
+}
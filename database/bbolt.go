@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// == bbolt ==
+// a pure go embedded store for single server installs, every table is a bucket
+
+const boltFilename = "netmaker.bolt"
+
+// BoltDB - the bbolt database object
+var BoltDB *bolt.DB
+
+// BBOLT_FUNCTIONS - contains a map of the functions for bbolt
+var BBOLT_FUNCTIONS = map[string]interface{}{
+	INIT_DB:         initBoltDB,
+	CREATE_TABLE:    boltCreateTable,
+	INSERT:          boltInsert,
+	INSERT_PEER:     boltInsertPeer,
+	DELETE:          boltDeleteRecord,
+	DELETE_ALL:      boltDeleteAllRecords,
+	FETCH_ALL:       boltFetchRecords,
+	FETCH_ONE:       boltFetchRecord,
+	FETCH_PREFIX:    boltFetchRecordsByPrefix,
+	COMMIT_TX:       boltCommitTx,
+	PUBLISH_CHANGES: publishNoop,
+	WATCH_CHANGES:   boltWatchChanges,
+	CLOSE_DB:        boltCloseDB,
+	isConnected:     boltConnected,
+}
+
+func initBoltDB() error {
+	if _, err := os.Stat("data"); os.IsNotExist(err) {
+		os.Mkdir("data", 0700)
+	}
+	var err error
+	// a timeout keeps a second server on the same file from blocking forever on the file lock
+	BoltDB, err = bolt.Open(filepath.Join("data", boltFilename), 0600, &bolt.Options{Timeout: 5 * time.Second})
+	return err
+}
+
+func boltCreateTable(tableName string) error {
+	return BoltDB.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(tableName))
+		return err
+	})
+}
+
+func boltBucket(tx *bolt.Tx, tableName string) (*bolt.Bucket, error) {
+	bucket := tx.Bucket([]byte(tableName))
+	if bucket == nil {
+		return nil, errors.New("no such table: " + tableName)
+	}
+	return bucket, nil
+}
+
+func boltInsert(key string, value string, tableName string) error {
+	if key != "" && value != "" && IsJSONString(value) {
+		return BoltDB.Update(func(tx *bolt.Tx) error {
+			bucket, err := boltBucket(tx, tableName)
+			if err != nil {
+				return err
+			}
+			return bucket.Put([]byte(key), []byte(value))
+		})
+	}
+	return errors.New("invalid insert " + key + " : " + value)
+}
+
+func boltInsertPeer(key string, value string) error {
+	if key != "" && value != "" && IsJSONString(value) {
+		return boltInsert(key, value, PEERS_TABLE_NAME)
+	}
+	return errors.New("invalid peer insert " + key + " : " + value)
+}
+
+func boltDeleteRecord(tableName string, key string) error {
+	return BoltDB.Update(func(tx *bolt.Tx) error {
+		bucket, err := boltBucket(tx, tableName)
+		if err != nil {
+			return err
+		}
+		return bucket.Delete([]byte(key))
+	})
+}
+
+func boltDeleteAllRecords(tableName string) error {
+	return BoltDB.Update(func(tx *bolt.Tx) error {
+		return boltClearBucket(tx, tableName)
+	})
+}
+
+// boltClearBucket - drops and recreates a bucket, the same as deleting all of its records
+func boltClearBucket(tx *bolt.Tx, tableName string) error {
+	if err := tx.DeleteBucket([]byte(tableName)); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+		return err
+	}
+	_, err := tx.CreateBucket([]byte(tableName))
+	return err
+}
+
+func boltFetchRecords(tableName string) (map[string]string, error) {
+	records := make(map[string]string)
+	err := BoltDB.View(func(tx *bolt.Tx) error {
+		bucket, err := boltBucket(tx, tableName)
+		if err != nil {
+			return err
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			records[string(k)] = string(v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New(NO_RECORDS)
+	}
+	return records, nil
+}
+
+func boltFetchRecord(tableName string, key string) (string, error) {
+	var value string
+	err := BoltDB.View(func(tx *bolt.Tx) error {
+		bucket, err := boltBucket(tx, tableName)
+		if err != nil {
+			return err
+		}
+		if data := bucket.Get([]byte(key)); data != nil {
+			value = string(data)
+			return nil
+		}
+		// keep lookup errors consistent with FetchRecords
+		if first, _ := bucket.Cursor().First(); first == nil {
+			return errors.New(NO_RECORDS)
+		}
+		return errors.New(NO_RECORD)
+	})
+	return value, err
+}
+
+func boltFetchRecordsByPrefix(tableName string, prefix string) (map[string]string, error) {
+	records := make(map[string]string)
+	err := BoltDB.View(func(tx *bolt.Tx) error {
+		bucket, err := boltBucket(tx, tableName)
+		if err != nil {
+			return err
+		}
+		// keys are kept sorted, so the matches are one contiguous range
+		cursor := bucket.Cursor()
+		for k, v := cursor.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, v = cursor.Next() {
+			records[string(k)] = string(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New(NO_RECORDS)
+	}
+	return records, nil
+}
+
+func boltCommitTx(ops []TxOp) error {
+	return BoltDB.Update(func(tx *bolt.Tx) error {
+		for _, op := range ops {
+			if op.Action == DELETE_ALL {
+				if tx.Bucket([]byte(op.TableName)) == nil {
+					return errors.New("no such table: " + op.TableName)
+				}
+				if err := boltClearBucket(tx, op.TableName); err != nil {
+					return err
+				}
+				continue
+			}
+			bucket, err := boltBucket(tx, op.TableName)
+			if err != nil {
+				return err
+			}
+			switch op.Action {
+			case INSERT:
+				err = bucket.Put([]byte(op.Key), []byte(op.Value))
+			case DELETE:
+				err = bucket.Delete([]byte(op.Key))
+			default:
+				err = errors.New("unsupported transaction action " + op.Action)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func boltCloseDB() {
+	BoltDB.Close()
+}
+
+func boltConnected() bool {
+	return BoltDB != nil && BoltDB.Path() != ""
+}
+
+func boltWatchChanges(ctx context.Context, deliver func(Change)) error {
+	return pollChanges(ctx, boltFetchRecords, deliver)
+}
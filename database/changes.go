@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/logger"
+)
+
+// CHANGE_POLL_INTERVAL - how often backends without native notifications are polled for changes
+const CHANGE_POLL_INTERVAL = 5 * time.Second
+
+// Change - a write made to a record of a revisioned table by another server instance
+// an empty Key means the whole table may have changed, ie. after notifications were missed
+type Change struct {
+	Instance string `json:"instance"`
+	Table    string `json:"table"`
+	Key      string `json:"key"`
+}
+
+var (
+	// instanceID - identifies the changes made by this server instance
+	instanceID     = uuid.NewString()
+	changeMutex    sync.Mutex
+	changeHandlers []func(Change)
+	// knownRevisions - the revisions seen by the last poll, nil until the first poll
+	knownRevisions map[string]uint64
+)
+
+// OnChange - registers a func to be called for every change made by another server instance
+func OnChange(handler func(Change)) {
+	changeMutex.Lock()
+	defer changeMutex.Unlock()
+	changeHandlers = append(changeHandlers, handler)
+}
+
+// WatchChanges - delivers changes made by other server instances to the registered handlers until ctx is done
+func WatchChanges(ctx context.Context) error {
+	return getCurrentDB()[WATCH_CHANGES].(func(context.Context, func(Change)) error)(ctx, dispatchChange)
+}
+
+func dispatchChange(change Change) {
+	if change.Instance == instanceID {
+		return
+	}
+	changeMutex.Lock()
+	handlers := append([]func(Change){}, changeHandlers...)
+	changeMutex.Unlock()
+	for _, handler := range handlers {
+		handler(change)
+	}
+}
+
+// tableChanged - notifies every handler that a whole revisioned table may have changed
+func tableChanged(deliver func(Change), table string) {
+	if revisionedTables[table] {
+		deliver(Change{Table: table})
+	}
+}
+
+// recordChanges - publishes committed writes to revisioned tables, caller must hold dbMutex
+func recordChanges(ops []TxOp) {
+	changes := []Change{}
+	changeMutex.Lock()
+	for _, op := range ops {
+		if op.TableName == REVISIONS_TABLE_NAME {
+			// remember our own writes so polling does not report them back to us
+			if knownRevisions != nil {
+				if op.Action == DELETE {
+					delete(knownRevisions, op.Key)
+				} else if revision, err := parseRevision(op.Value); err == nil {
+					knownRevisions[op.Key] = revision
+				}
+			}
+			continue
+		}
+		if revisionedTables[op.TableName] {
+			changes = append(changes, Change{Instance: instanceID, Table: op.TableName, Key: op.Key})
+		}
+	}
+	changeMutex.Unlock()
+	if len(changes) == 0 {
+		return
+	}
+	if err := getCurrentDB()[PUBLISH_CHANGES].(func([]Change) error)(changes); err != nil {
+		logger.Log(0, "failed to publish database changes:", err.Error())
+	}
+}
+
+func parseRevision(value string) (uint64, error) {
+	var record revisionRecord
+	err := json.Unmarshal([]byte(value), &record)
+	return record.Revision, err
+}
+
+// publishNoop - backends that are polled record their changes in the revisions table only
+func publishNoop([]Change) error {
+	return nil
+}
+
+// pollChanges - compares the revisions table against the last poll to find writes made by other instances
+func pollChanges(ctx context.Context, fetch func(string) (map[string]string, error), deliver func(Change)) error {
+	ticker := time.NewTicker(CHANGE_POLL_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			dbMutex.RLock()
+			records, err := fetch(REVISIONS_TABLE_NAME)
+			dbMutex.RUnlock()
+			if err != nil && !IsEmptyRecord(err) {
+				logger.Log(0, "failed to poll database changes:", err.Error())
+				continue
+			}
+			for _, change := range diffRevisions(records) {
+				deliver(change)
+			}
+		}
+	}
+}
+
+// diffRevisions - returns the records whose revision changed since the last poll
+func diffRevisions(records map[string]string) []Change {
+	changeMutex.Lock()
+	defer changeMutex.Unlock()
+	current := make(map[string]uint64, len(records))
+	for revKey, value := range records {
+		revision, err := parseRevision(value)
+		if err != nil {
+			continue
+		}
+		current[revKey] = revision
+	}
+	previous := knownRevisions
+	knownRevisions = current
+	if previous == nil {
+		return nil
+	}
+	changes := []Change{}
+	for revKey, revision := range current {
+		if previous[revKey] != revision {
+			changes = append(changes, revisionChange(revKey))
+		}
+	}
+	for revKey := range previous {
+		if _, ok := current[revKey]; !ok {
+			changes = append(changes, revisionChange(revKey))
+		}
+	}
+	return changes
+}
+
+func revisionChange(revKey string) Change {
+	table, key, _ := strings.Cut(revKey, "/")
+	return Change{Table: table, Key: key}
+}
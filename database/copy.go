@@ -0,0 +1,120 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gravitl/netmaker/logger"
+)
+
+// supportedBackends - the database backends records can be copied between
+var supportedBackends = []string{"sqlite", "postgres", "rqlite", "bbolt"}
+
+// TableCopyResult - the number of records read from the source and found in the destination for a table
+type TableCopyResult struct {
+	Table       string `json:"table"`
+	Source      int    `json:"source"`
+	Destination int    `json:"destination"`
+}
+
+// CopyDatabase - copies every table from one database backend to another and verifies the result
+// both backends are configured through the usual server config (SQL_HOST, SQL_CONN, etc.)
+// the destination must be empty unless overwrite is set, in which case its tables are replaced
+func CopyDatabase(source, destination string, overwrite bool) ([]TableCopyResult, error) {
+	if source == destination {
+		return nil, errors.New("source and destination database must differ")
+	}
+	if !isSupportedBackend(source) || !isSupportedBackend(destination) {
+		return nil, fmt.Errorf("unsupported database, must be one of %v", supportedBackends)
+	}
+	src, dst := getDB(source), getDB(destination)
+	logger.Log(0, "connecting to source database", source)
+	if err := src[INIT_DB].(func() error)(); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", source, err)
+	}
+	defer src[CLOSE_DB].(func())()
+	logger.Log(0, "connecting to destination database", destination)
+	if err := dst[INIT_DB].(func() error)(); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", destination, err)
+	}
+	defer dst[CLOSE_DB].(func())()
+	return copyTables(src, dst, overwrite)
+}
+
+func copyTables(src, dst map[string]interface{}, overwrite bool) ([]TableCopyResult, error) {
+	records := make(map[string]map[string]string, len(tables))
+	ops := []TxOp{}
+	for _, table := range tables {
+		if err := dst[CREATE_TABLE].(func(string) error)(table); err != nil {
+			return nil, fmt.Errorf("failed to create table %s: %w", table, err)
+		}
+		existing, err := fetchTable(dst, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 && !overwrite {
+			return nil, fmt.Errorf("destination table %s is not empty, use overwrite to replace it", table)
+		}
+		records[table], err = fetchTable(src, table)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, TxOp{Action: DELETE_ALL, TableName: table})
+		for key, value := range records[table] {
+			if !IsJSONString(value) {
+				return nil, fmt.Errorf("record %s in table %s is not valid json", key, table)
+			}
+			ops = append(ops, TxOp{Action: INSERT, TableName: table, Key: key, Value: value})
+		}
+	}
+	logger.Log(0, "copying", fmt.Sprint(len(ops)-len(tables)), "records")
+	if err := dst[COMMIT_TX].(func([]TxOp) error)(ops); err != nil {
+		return nil, fmt.Errorf("failed to write records: %w", err)
+	}
+	return verifyTables(dst, records)
+}
+
+// verifyTables - checks every copied record is present in the destination, unchanged and valid json
+func verifyTables(dst map[string]interface{}, expected map[string]map[string]string) ([]TableCopyResult, error) {
+	results := make([]TableCopyResult, 0, len(tables))
+	for _, table := range tables {
+		copied, err := fetchTable(dst, table)
+		if err != nil {
+			return results, err
+		}
+		result := TableCopyResult{Table: table, Source: len(expected[table]), Destination: len(copied)}
+		results = append(results, result)
+		if result.Source != result.Destination {
+			return results, fmt.Errorf("table %s has %d records in the destination, expected %d", table, result.Destination, result.Source)
+		}
+		for key, value := range expected[table] {
+			if copied[key] != value {
+				return results, fmt.Errorf("record %s in table %s does not match the source", key, table)
+			}
+			if !IsJSONString(copied[key]) {
+				return results, fmt.Errorf("record %s in table %s is not valid json", key, table)
+			}
+		}
+	}
+	return results, nil
+}
+
+func fetchTable(db map[string]interface{}, table string) (map[string]string, error) {
+	records, err := db[FETCH_ALL].(func(string) (map[string]string, error))(table)
+	if err != nil {
+		if IsEmptyRecord(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read table %s: %w", table, err)
+	}
+	return records, nil
+}
+
+func isSupportedBackend(backend string) bool {
+	for _, supported := range supportedBackends {
+		if backend == supported {
+			return true
+		}
+	}
+	return false
+}
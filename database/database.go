@@ -61,6 +61,22 @@ const (
 	ENROLLMENT_KEYS_TABLE_NAME = "enrollmentkeys"
 	// HOST_ACTIONS_TABLE_NAME - table name for enrollmentkeys
 	HOST_ACTIONS_TABLE_NAME = "hostactions"
+	// REVISIONS_TABLE_NAME - table name for the revision counters of records
+	REVISIONS_TABLE_NAME = "revisions"
+	// EXPIRATIONS_TABLE_NAME - table name for the expiration times of records written with a ttl
+	EXPIRATIONS_TABLE_NAME = "expirations"
+	// TRASH_TABLE_NAME - table name for deleted networks, hosts and ext clients that can still be restored
+	TRASH_TABLE_NAME = "trash"
+	// IPAM_TABLE_NAME - table name for the reserved and recently released addresses of networks
+	IPAM_TABLE_NAME = "ipam"
+	// NETWORK_TEMPLATES_TABLE_NAME - table name for the templates new networks are created from
+	NETWORK_TEMPLATES_TABLE_NAME = "networktemplates"
+	// NETWORK_PEERINGS_TABLE_NAME - table name for the peerings that route traffic between two networks
+	NETWORK_PEERINGS_TABLE_NAME = "networkpeerings"
+	// BULK_JOBS_TABLE_NAME - table name for bulk host operations and their per host results
+	BULK_JOBS_TABLE_NAME = "bulkjobs"
+	// NODE_APPROVALS_TABLE_NAME - table name for the audit log of approved and rejected nodes
+	NODE_APPROVALS_TABLE_NAME = "nodeapprovals"
 
 	// == ERROR CONSTS ==
 	// NO_RECORD - no singular result found
@@ -81,8 +97,20 @@ const (
 	DELETE = "delete"
 	// DELETE_ALL - delete a table const
 	DELETE_ALL = "deleteall"
+	// CHECK_REVISION - require a record to be at a revision when a transaction commits const
+	CHECK_REVISION = "checkrevision"
 	// FETCH_ALL - fetch table contents const
 	FETCH_ALL = "fetchall"
+	// FETCH_ONE - fetch a single record by key const
+	FETCH_ONE = "fetchone"
+	// FETCH_PREFIX - fetch records whose key starts with a prefix const
+	FETCH_PREFIX = "fetchprefix"
+	// COMMIT_TX - atomically apply a set of writes const
+	COMMIT_TX = "committx"
+	// PUBLISH_CHANGES - tell other server instances about committed writes const
+	PUBLISH_CHANGES = "publishchanges"
+	// WATCH_CHANGES - receive writes committed by other server instances const
+	WATCH_CHANGES = "watchchanges"
 	// CLOSE_DB - graceful close of db const
 	CLOSE_DB = "closedb"
 	// isconnected
@@ -92,13 +120,19 @@ const (
 var dbMutex sync.RWMutex
 
 func getCurrentDB() map[string]interface{} {
-	switch servercfg.GetDB() {
+	return getDB(servercfg.GetDB())
+}
+
+func getDB(backend string) map[string]interface{} {
+	switch backend {
 	case "rqlite":
 		return RQLITE_FUNCTIONS
 	case "sqlite":
 		return SQLITE_FUNCTIONS
 	case "postgres":
 		return PG_FUNCTIONS
+	case "bbolt":
+		return BBOLT_FUNCTIONS
 	default:
 		return SQLITE_FUNCTIONS
 	}
@@ -106,6 +140,9 @@ func getCurrentDB() map[string]interface{} {
 
 // InitializeDatabase - initializes database
 func InitializeDatabase() error {
+	if err := loadEncryptionKeys(); err != nil {
+		return err
+	}
 	logger.Log(0, "connecting to", servercfg.GetDB())
 	tperiod := time.Now().Add(10 * time.Second)
 	for {
@@ -123,27 +160,47 @@ func InitializeDatabase() error {
 	return initializeUUID()
 }
 
+// tables - every table created and used by the server
+var tables = []string{
+	NETWORKS_TABLE_NAME,
+	NODES_TABLE_NAME,
+	CERTS_TABLE_NAME,
+	DELETED_NODES_TABLE_NAME,
+	USERS_TABLE_NAME,
+	DNS_TABLE_NAME,
+	EXT_CLIENT_TABLE_NAME,
+	PEERS_TABLE_NAME,
+	SERVERCONF_TABLE_NAME,
+	SERVER_UUID_TABLE_NAME,
+	GENERATED_TABLE_NAME,
+	NODE_ACLS_TABLE_NAME,
+	SSO_STATE_CACHE,
+	METRICS_TABLE_NAME,
+	NETWORK_USER_TABLE_NAME,
+	USER_GROUPS_TABLE_NAME,
+	CACHE_TABLE_NAME,
+	HOSTS_TABLE_NAME,
+	ENROLLMENT_KEYS_TABLE_NAME,
+	HOST_ACTIONS_TABLE_NAME,
+	REVISIONS_TABLE_NAME,
+	EXPIRATIONS_TABLE_NAME,
+	TRASH_TABLE_NAME,
+	IPAM_TABLE_NAME,
+	NETWORK_TEMPLATES_TABLE_NAME,
+	NETWORK_PEERINGS_TABLE_NAME,
+	BULK_JOBS_TABLE_NAME,
+	NODE_APPROVALS_TABLE_NAME,
+}
+
+// Tables - returns the names of every table used by the server
+func Tables() []string {
+	return append([]string{}, tables...)
+}
+
 func createTables() {
-	createTable(NETWORKS_TABLE_NAME)
-	createTable(NODES_TABLE_NAME)
-	createTable(CERTS_TABLE_NAME)
-	createTable(DELETED_NODES_TABLE_NAME)
-	createTable(USERS_TABLE_NAME)
-	createTable(DNS_TABLE_NAME)
-	createTable(EXT_CLIENT_TABLE_NAME)
-	createTable(PEERS_TABLE_NAME)
-	createTable(SERVERCONF_TABLE_NAME)
-	createTable(SERVER_UUID_TABLE_NAME)
-	createTable(GENERATED_TABLE_NAME)
-	createTable(NODE_ACLS_TABLE_NAME)
-	createTable(SSO_STATE_CACHE)
-	createTable(METRICS_TABLE_NAME)
-	createTable(NETWORK_USER_TABLE_NAME)
-	createTable(USER_GROUPS_TABLE_NAME)
-	createTable(CACHE_TABLE_NAME)
-	createTable(HOSTS_TABLE_NAME)
-	createTable(ENROLLMENT_KEYS_TABLE_NAME)
-	createTable(HOST_ACTIONS_TABLE_NAME)
+	for _, table := range tables {
+		createTable(table)
+	}
 }
 
 func createTable(tableName string) error {
@@ -162,6 +219,13 @@ func Insert(key string, value string, tableName string) error {
 	dbMutex.Lock()
 	defer dbMutex.Unlock()
 	if key != "" && value != "" && IsJSONString(value) {
+		value, err := encryptRecord(tableName, key, value)
+		if err != nil {
+			return err
+		}
+		if revisionedTables[tableName] || expiringTables[tableName] {
+			return commitWithRevisions([]TxOp{{Action: INSERT, TableName: tableName, Key: key, Value: value}})
+		}
 		return getCurrentDB()[INSERT].(func(string, string, string) error)(key, value, tableName)
 	} else {
 		return errors.New("invalid insert " + key + " : " + value)
@@ -183,6 +247,9 @@ func InsertPeer(key string, value string) error {
 func DeleteRecord(tableName string, key string) error {
 	dbMutex.Lock()
 	defer dbMutex.Unlock()
+	if revisionedTables[tableName] || expiringTables[tableName] {
+		return commitWithRevisions([]TxOp{{Action: DELETE, TableName: tableName, Key: key}})
+	}
 	return getCurrentDB()[DELETE].(func(string, string) error)(tableName, key)
 }
 
@@ -190,6 +257,10 @@ func DeleteRecord(tableName string, key string) error {
 func DeleteAllRecords(tableName string) error {
 	dbMutex.Lock()
 	defer dbMutex.Unlock()
+	if revisionedTables[tableName] || expiringTables[tableName] {
+		// the revisions and expirations of the table's records are deleted along with them
+		return commitWithRevisions([]TxOp{{Action: DELETE_ALL, TableName: tableName}})
+	}
 	err := getCurrentDB()[DELETE_ALL].(func(string) error)(tableName)
 	if err != nil {
 		return err
@@ -203,21 +274,56 @@ func DeleteAllRecords(tableName string) error {
 
 // FetchRecord - fetches a record
 func FetchRecord(tableName string, key string) (string, error) {
-	results, err := FetchRecords(tableName)
+	dbMutex.RLock()
+	defer dbMutex.RUnlock()
+	result, err := getCurrentDB()[FETCH_ONE].(func(string, string) (string, error))(tableName, key)
 	if err != nil {
 		return "", err
 	}
-	if results[key] == "" {
+	if result == "" {
 		return "", errors.New(NO_RECORD)
 	}
-	return results[key], nil
+	if expiringTables[tableName] {
+		expired, err := isExpired(tableName, key)
+		if err != nil {
+			return "", err
+		}
+		if expired {
+			return "", errors.New(NO_RECORD)
+		}
+	}
+	return decryptRecord(tableName, key, result)
+}
+
+// FetchRecordsByPrefix - fetches all records in given table whose key starts with prefix
+func FetchRecordsByPrefix(tableName string, prefix string) (map[string]string, error) {
+	if prefix == "" {
+		return FetchRecords(tableName)
+	}
+	dbMutex.RLock()
+	defer dbMutex.RUnlock()
+	records, err := getCurrentDB()[FETCH_PREFIX].(func(string, string) (map[string]string, error))(tableName, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if records, err = dropExpired(tableName, records); err != nil {
+		return nil, err
+	}
+	return decryptRecords(tableName, records)
 }
 
 // FetchRecords - fetches all records in given table
 func FetchRecords(tableName string) (map[string]string, error) {
 	dbMutex.RLock()
 	defer dbMutex.RUnlock()
-	return getCurrentDB()[FETCH_ALL].(func(string) (map[string]string, error))(tableName)
+	records, err := getCurrentDB()[FETCH_ALL].(func(string) (map[string]string, error))(tableName)
+	if err != nil {
+		return nil, err
+	}
+	if records, err = dropExpired(tableName, records); err != nil {
+		return nil, err
+	}
+	return decryptRecords(tableName, records)
 }
 
 // initializeUUID - create a UUID record for server if none exists
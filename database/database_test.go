@@ -0,0 +1,449 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	InitializeDatabase()
+	defer CloseDB()
+	os.Exit(m.Run())
+}
+
+func TestFetchRecord(t *testing.T) {
+	DeleteAllRecords(GENERATED_TABLE_NAME)
+	t.Run("MissingKey", func(t *testing.T) {
+		_, err := FetchRecord(GENERATED_TABLE_NAME, "missing")
+		assert.True(t, IsEmptyRecord(err))
+	})
+	t.Run("ExistingKey", func(t *testing.T) {
+		err := Insert("key1", `{"value":1}`, GENERATED_TABLE_NAME)
+		assert.Nil(t, err)
+		record, err := FetchRecord(GENERATED_TABLE_NAME, "key1")
+		assert.Nil(t, err)
+		assert.Equal(t, `{"value":1}`, record)
+	})
+}
+
+func TestFetchRecordsByPrefix(t *testing.T) {
+	DeleteAllRecords(GENERATED_TABLE_NAME)
+	assert.Nil(t, Insert("net1###a", `{"value":1}`, GENERATED_TABLE_NAME))
+	assert.Nil(t, Insert("net1###b", `{"value":2}`, GENERATED_TABLE_NAME))
+	assert.Nil(t, Insert("net2###a", `{"value":3}`, GENERATED_TABLE_NAME))
+	assert.Nil(t, Insert("NET1###c", `{"value":4}`, GENERATED_TABLE_NAME))
+	t.Run("MatchingPrefix", func(t *testing.T) {
+		records, err := FetchRecordsByPrefix(GENERATED_TABLE_NAME, "net1")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(records))
+		assert.Equal(t, `{"value":2}`, records["net1###b"])
+	})
+	t.Run("WildcardCharacters", func(t *testing.T) {
+		_, err := FetchRecordsByPrefix(GENERATED_TABLE_NAME, "net_")
+		assert.True(t, IsEmptyRecord(err))
+	})
+	t.Run("NoMatch", func(t *testing.T) {
+		_, err := FetchRecordsByPrefix(GENERATED_TABLE_NAME, "net3")
+		assert.True(t, IsEmptyRecord(err))
+	})
+}
+
+func TestTx(t *testing.T) {
+	DeleteAllRecords(GENERATED_TABLE_NAME)
+	DeleteAllRecords(CACHE_TABLE_NAME)
+	t.Run("CommitAcrossTables", func(t *testing.T) {
+		committed := false
+		tx := BeginTx()
+		assert.Nil(t, tx.Insert("key1", `{"value":1}`, GENERATED_TABLE_NAME))
+		assert.Nil(t, tx.Insert("key2", `{"value":2}`, CACHE_TABLE_NAME))
+		tx.OnCommit(func() { committed = true })
+		_, err := FetchRecord(GENERATED_TABLE_NAME, "key1")
+		assert.True(t, IsEmptyRecord(err))
+		assert.Nil(t, tx.Commit())
+		assert.True(t, committed)
+		_, err = FetchRecord(GENERATED_TABLE_NAME, "key1")
+		assert.Nil(t, err)
+		_, err = FetchRecord(CACHE_TABLE_NAME, "key2")
+		assert.Nil(t, err)
+		assert.NotNil(t, tx.Commit())
+	})
+	t.Run("InvalidInsert", func(t *testing.T) {
+		tx := BeginTx()
+		assert.NotNil(t, tx.Insert("key3", "not json", GENERATED_TABLE_NAME))
+	})
+	t.Run("RollbackOnFailure", func(t *testing.T) {
+		committed := false
+		tx := BeginTx()
+		tx.Delete(GENERATED_TABLE_NAME, "key1")
+		assert.Nil(t, tx.Insert("key4", `{"value":4}`, "missingtable"))
+		tx.OnCommit(func() { committed = true })
+		assert.NotNil(t, tx.Commit())
+		assert.False(t, committed)
+		_, err := FetchRecord(GENERATED_TABLE_NAME, "key1")
+		assert.Nil(t, err)
+	})
+}
+
+// memoryDB - an in memory backend used as a copy destination
+func memoryDB() map[string]interface{} {
+	store := map[string]map[string]string{}
+	return map[string]interface{}{
+		CREATE_TABLE: func(table string) error {
+			if store[table] == nil {
+				store[table] = map[string]string{}
+			}
+			return nil
+		},
+		FETCH_ALL: func(table string) (map[string]string, error) {
+			if len(store[table]) == 0 {
+				return nil, errors.New(NO_RECORDS)
+			}
+			records := map[string]string{}
+			for key, value := range store[table] {
+				records[key] = value
+			}
+			return records, nil
+		},
+		COMMIT_TX: func(ops []TxOp) error {
+			for _, op := range ops {
+				switch op.Action {
+				case INSERT:
+					store[op.TableName][op.Key] = op.Value
+				case DELETE_ALL:
+					store[op.TableName] = map[string]string{}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestCopyDatabase(t *testing.T) {
+	DeleteAllRecords(GENERATED_TABLE_NAME)
+	assert.Nil(t, Insert("key1", `{"value":1}`, GENERATED_TABLE_NAME))
+	assert.Nil(t, Insert("key2", `{"value":2}`, GENERATED_TABLE_NAME))
+	t.Run("InvalidBackends", func(t *testing.T) {
+		_, err := CopyDatabase("sqlite", "sqlite", false)
+		assert.NotNil(t, err)
+		_, err = CopyDatabase("sqlite", "mysql", false)
+		assert.NotNil(t, err)
+	})
+	t.Run("CopiesAllTables", func(t *testing.T) {
+		dst := memoryDB()
+		results, err := copyTables(SQLITE_FUNCTIONS, dst, false)
+		assert.Nil(t, err)
+		assert.Equal(t, len(tables), len(results))
+		for _, result := range results {
+			assert.Equal(t, result.Source, result.Destination)
+			if result.Table == GENERATED_TABLE_NAME {
+				assert.Equal(t, 2, result.Destination)
+			}
+		}
+		_, err = copyTables(SQLITE_FUNCTIONS, dst, false)
+		assert.NotNil(t, err)
+		_, err = copyTables(SQLITE_FUNCTIONS, dst, true)
+		assert.Nil(t, err)
+	})
+	t.Run("DetectsMismatch", func(t *testing.T) {
+		dst := memoryDB()
+		commit := dst[COMMIT_TX].(func([]TxOp) error)
+		dst[COMMIT_TX] = func(ops []TxOp) error {
+			for i := range ops {
+				if ops[i].Action == INSERT {
+					return commit(append(ops[:i], ops[i+1:]...))
+				}
+			}
+			return commit(ops)
+		}
+		_, err := copyTables(SQLITE_FUNCTIONS, dst, false)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRevisions(t *testing.T) {
+	DeleteAllRecords(NETWORKS_TABLE_NAME)
+	DeleteAllRecords(REVISIONS_TABLE_NAME)
+	t.Run("BumpedOnInsert", func(t *testing.T) {
+		revision, err := GetRevision(NETWORKS_TABLE_NAME, "net1")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), revision)
+		assert.Nil(t, Insert("net1", `{"netid":"net1"}`, NETWORKS_TABLE_NAME))
+		assert.Nil(t, Insert("net1", `{"netid":"net1"}`, NETWORKS_TABLE_NAME))
+		revision, err = GetRevision(NETWORKS_TABLE_NAME, "net1")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), revision)
+	})
+	t.Run("BumpedOnCommit", func(t *testing.T) {
+		tx := BeginTx()
+		assert.Nil(t, tx.Insert("net1", `{"netid":"net1"}`, NETWORKS_TABLE_NAME))
+		assert.Nil(t, tx.Insert("net1", `{"netid":"net1"}`, NETWORKS_TABLE_NAME))
+		assert.Nil(t, tx.Commit())
+		revision, err := GetRevision(NETWORKS_TABLE_NAME, "net1")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(4), revision)
+	})
+	t.Run("UntrackedTable", func(t *testing.T) {
+		assert.Nil(t, Insert("key1", `{"value":1}`, GENERATED_TABLE_NAME))
+		revision, err := GetRevision(GENERATED_TABLE_NAME, "key1")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), revision)
+	})
+	t.Run("ExpectedRevision", func(t *testing.T) {
+		tx := BeginTx()
+		tx.ExpectRevision(NETWORKS_TABLE_NAME, "net1", 3)
+		assert.Nil(t, tx.Insert("net1", `{"netid":"stale"}`, NETWORKS_TABLE_NAME))
+		assert.ErrorIs(t, tx.Commit(), ErrRevisionMismatch)
+		value, err := FetchRecord(NETWORKS_TABLE_NAME, "net1")
+		assert.Nil(t, err)
+		assert.Equal(t, `{"netid":"net1"}`, value)
+		tx = BeginTx()
+		tx.ExpectRevision(NETWORKS_TABLE_NAME, "net1", 4)
+		assert.Nil(t, tx.Insert("net1", `{"netid":"net1"}`, NETWORKS_TABLE_NAME))
+		assert.Nil(t, tx.Commit())
+		revision, err := GetRevision(NETWORKS_TABLE_NAME, "net1")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(5), revision)
+	})
+	t.Run("RemovedOnDelete", func(t *testing.T) {
+		assert.Nil(t, DeleteRecord(NETWORKS_TABLE_NAME, "net1"))
+		revision, err := GetRevision(NETWORKS_TABLE_NAME, "net1")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), revision)
+	})
+	t.Run("RemovedWithTable", func(t *testing.T) {
+		assert.Nil(t, Insert("net2", `{"netid":"net2"}`, NETWORKS_TABLE_NAME))
+		assert.Nil(t, DeleteAllRecords(NETWORKS_TABLE_NAME))
+		revision, err := GetRevision(NETWORKS_TABLE_NAME, "net2")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), revision)
+	})
+	t.Run("KeptWhenRewrittenAfterClear", func(t *testing.T) {
+		assert.Nil(t, Insert("net3", `{"netid":"net3"}`, NETWORKS_TABLE_NAME))
+		tx := BeginTx()
+		tx.DeleteAll(NETWORKS_TABLE_NAME)
+		assert.Nil(t, tx.Insert("net3", `{"netid":"net3"}`, NETWORKS_TABLE_NAME))
+		assert.Nil(t, tx.Commit())
+		revision, err := GetRevision(NETWORKS_TABLE_NAME, "net3")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), revision)
+		assert.Nil(t, DeleteAllRecords(NETWORKS_TABLE_NAME))
+	})
+}
+
+func TestInsertWithTTL(t *testing.T) {
+	DeleteAllRecords(SSO_STATE_CACHE)
+	DeleteAllRecords(EXPIRATIONS_TABLE_NAME)
+	expire := func(key string) {
+		assert.Nil(t, Insert(revisionKey(SSO_STATE_CACHE, key), `{"expires":1}`, EXPIRATIONS_TABLE_NAME))
+	}
+	t.Run("OnlyExpiringTables", func(t *testing.T) {
+		assert.NotNil(t, InsertWithTTL("net1", `{"netid":"net1"}`, NETWORKS_TABLE_NAME, time.Hour))
+		assert.NotNil(t, InsertWithTTL("state1", `{"value":"state1"}`, SSO_STATE_CACHE, 0))
+	})
+	t.Run("HiddenOnceExpired", func(t *testing.T) {
+		assert.Nil(t, InsertWithTTL("state1", `{"value":"state1"}`, SSO_STATE_CACHE, time.Hour))
+		assert.Nil(t, InsertWithTTL("state2", `{"value":"state2"}`, SSO_STATE_CACHE, time.Hour))
+		_, err := FetchRecord(SSO_STATE_CACHE, "state1")
+		assert.Nil(t, err)
+		expire("state1")
+		_, err = FetchRecord(SSO_STATE_CACHE, "state1")
+		assert.EqualError(t, err, NO_RECORD)
+		records, err := FetchRecords(SSO_STATE_CACHE)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"state2"}, keysOf(records))
+		expire("state2")
+		_, err = FetchRecordsByPrefix(SSO_STATE_CACHE, "state")
+		assert.EqualError(t, err, NO_RECORDS)
+	})
+	t.Run("ClearedByInsert", func(t *testing.T) {
+		assert.Nil(t, Insert("state1", `{"value":"state1"}`, SSO_STATE_CACHE))
+		_, err := FetchRecord(SSO_STATE_CACHE, "state1")
+		assert.Nil(t, err)
+	})
+	t.Run("Reaped", func(t *testing.T) {
+		reaped, err := ReapExpiredRecords()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, reaped)
+		records, err := FetchRecords(SSO_STATE_CACHE)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"state1"}, keysOf(records))
+		_, err = FetchRecords(EXPIRATIONS_TABLE_NAME)
+		assert.True(t, IsEmptyRecord(err))
+	})
+	t.Run("RemovedWithTable", func(t *testing.T) {
+		assert.Nil(t, InsertWithTTL("state3", `{"value":"state3"}`, SSO_STATE_CACHE, time.Hour))
+		assert.Nil(t, DeleteAllRecords(SSO_STATE_CACHE))
+		_, err := FetchRecords(EXPIRATIONS_TABLE_NAME)
+		assert.True(t, IsEmptyRecord(err))
+	})
+}
+
+func keysOf(records map[string]string) []string {
+	keys := []string{}
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestDiffRevisions(t *testing.T) {
+	changeMutex.Lock()
+	knownRevisions = nil
+	changeMutex.Unlock()
+	assert.Empty(t, diffRevisions(map[string]string{
+		"nodes/a": `{"revision":1}`,
+		"hosts/b": `{"revision":1}`,
+	}))
+	t.Run("Modified", func(t *testing.T) {
+		changes := diffRevisions(map[string]string{
+			"nodes/a": `{"revision":2}`,
+			"hosts/b": `{"revision":1}`,
+		})
+		assert.Equal(t, []Change{{Table: NODES_TABLE_NAME, Key: "a"}}, changes)
+	})
+	t.Run("Deleted", func(t *testing.T) {
+		changes := diffRevisions(map[string]string{
+			"nodes/a": `{"revision":2}`,
+		})
+		assert.Equal(t, []Change{{Table: HOSTS_TABLE_NAME, Key: "b"}}, changes)
+	})
+	t.Run("OwnWritesIgnored", func(t *testing.T) {
+		recordChanges([]TxOp{
+			{Action: INSERT, TableName: NODES_TABLE_NAME, Key: "a", Value: `{}`},
+			{Action: INSERT, TableName: REVISIONS_TABLE_NAME, Key: "nodes/a", Value: `{"revision":3}`},
+		})
+		assert.Empty(t, diffRevisions(map[string]string{
+			"nodes/a": `{"revision":3}`,
+		}))
+	})
+	t.Run("OwnNotificationsIgnored", func(t *testing.T) {
+		received := []Change{}
+		OnChange(func(change Change) { received = append(received, change) })
+		dispatchChange(Change{Instance: instanceID, Table: NODES_TABLE_NAME, Key: "a"})
+		dispatchChange(Change{Instance: "other", Table: NODES_TABLE_NAME, Key: "a"})
+		assert.Equal(t, 1, len(received))
+	})
+}
+
+func useEncryptionKeys(t *testing.T, raw string) {
+	keys, err := parseEncryptionKeys(raw)
+	assert.Nil(t, err)
+	encryptionKeysMutex.Lock()
+	encryptionKeys = keys
+	encryptionKeysMutex.Unlock()
+	t.Cleanup(func() {
+		encryptionKeysMutex.Lock()
+		encryptionKeys = nil
+		encryptionKeysMutex.Unlock()
+	})
+}
+
+func TestEncryption(t *testing.T) {
+	const (
+		oldKey = "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+		newKey = "YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXphYmNkZWY="
+	)
+	rawRecord := func(key string) string {
+		record, err := getCurrentDB()[FETCH_ONE].(func(string, string) (string, error))(EXT_CLIENT_TABLE_NAME, key)
+		assert.Nil(t, err)
+		return record
+	}
+	// only encrypt the records created by this test so no other table is left encrypted
+	original := sensitiveFields
+	sensitiveFields = []sensitiveField{{table: EXT_CLIENT_TABLE_NAME, field: "privatekey"}}
+	t.Cleanup(func() { sensitiveFields = original })
+	DeleteAllRecords(EXT_CLIENT_TABLE_NAME)
+	assert.Nil(t, Insert("plain###net", `{"clientid":"plain","privatekey":"secret1"}`, EXT_CLIENT_TABLE_NAME))
+	useEncryptionKeys(t, oldKey)
+	t.Run("EncryptsOnInsert", func(t *testing.T) {
+		assert.Nil(t, Insert("client###net", `{"clientid":"client","privatekey":"secret2"}`, EXT_CLIENT_TABLE_NAME))
+		assert.NotContains(t, rawRecord("client###net"), "secret2")
+		record, err := FetchRecord(EXT_CLIENT_TABLE_NAME, "client###net")
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"clientid":"client","privatekey":"secret2"}`, record)
+	})
+	t.Run("EncryptsExistingRecords", func(t *testing.T) {
+		assert.Contains(t, rawRecord("plain###net"), "secret1")
+		count, err := EncryptSensitiveRecords()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+		assert.NotContains(t, rawRecord("plain###net"), "secret1")
+		records, err := FetchRecords(EXT_CLIENT_TABLE_NAME)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"clientid":"plain","privatekey":"secret1"}`, records["plain###net"])
+	})
+	t.Run("RotatesKeys", func(t *testing.T) {
+		useEncryptionKeys(t, newKey+"\n"+oldKey)
+		count, err := EncryptSensitiveRecords()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+		useEncryptionKeys(t, newKey)
+		record, err := FetchRecord(EXT_CLIENT_TABLE_NAME, "plain###net")
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"clientid":"plain","privatekey":"secret1"}`, record)
+	})
+	t.Run("UnknownKey", func(t *testing.T) {
+		useEncryptionKeys(t, oldKey)
+		_, err := FetchRecord(EXT_CLIENT_TABLE_NAME, "plain###net")
+		assert.NotNil(t, err)
+	})
+	t.Run("InvalidKey", func(t *testing.T) {
+		_, err := parseEncryptionKeys("c2hvcnQ=")
+		assert.NotNil(t, err)
+	})
+	DeleteAllRecords(EXT_CLIENT_TABLE_NAME)
+}
+
+func TestSensitiveFields(t *testing.T) {
+	assert.Contains(t, sensitiveFieldsOf(SERVERCONF_TABLE_NAME, "netmaker-id-key-pair"), "private_key")
+	assert.NotContains(t, sensitiveFieldsOf(SERVERCONF_TABLE_NAME, "other"), "private_key")
+}
+
+func TestBoltBackend(t *testing.T) {
+	assert.Nil(t, initBoltDB())
+	defer boltCloseDB()
+	assert.True(t, boltConnected())
+	for _, table := range tables {
+		assert.Nil(t, boltCreateTable(table))
+		assert.Nil(t, boltDeleteAllRecords(table))
+	}
+	t.Run("InsertAndFetch", func(t *testing.T) {
+		_, err := boltFetchRecord(GENERATED_TABLE_NAME, "key1")
+		assert.Equal(t, NO_RECORDS, err.Error())
+		assert.Nil(t, boltInsert("net1###a", `{"value":1}`, GENERATED_TABLE_NAME))
+		assert.Nil(t, boltInsert("net2###a", `{"value":2}`, GENERATED_TABLE_NAME))
+		assert.NotNil(t, boltInsert("net3###a", "not json", GENERATED_TABLE_NAME))
+		_, err = boltFetchRecord(GENERATED_TABLE_NAME, "key1")
+		assert.Equal(t, NO_RECORD, err.Error())
+		record, err := boltFetchRecord(GENERATED_TABLE_NAME, "net1###a")
+		assert.Nil(t, err)
+		assert.Equal(t, `{"value":1}`, record)
+		records, err := boltFetchRecordsByPrefix(GENERATED_TABLE_NAME, "net2")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"net2###a": `{"value":2}`}, records)
+		assert.Nil(t, boltDeleteRecord(GENERATED_TABLE_NAME, "net2###a"))
+		records, err = boltFetchRecords(GENERATED_TABLE_NAME)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(records))
+	})
+	t.Run("RollbackOnFailure", func(t *testing.T) {
+		err := boltCommitTx([]TxOp{
+			{Action: DELETE, TableName: GENERATED_TABLE_NAME, Key: "net1###a"},
+			{Action: INSERT, TableName: "missingtable", Key: "key", Value: `{}`},
+		})
+		assert.NotNil(t, err)
+		_, err = boltFetchRecord(GENERATED_TABLE_NAME, "net1###a")
+		assert.Nil(t, err)
+	})
+	t.Run("CopyFromSqlite", func(t *testing.T) {
+		results, err := copyTables(SQLITE_FUNCTIONS, BBOLT_FUNCTIONS, true)
+		assert.Nil(t, err)
+		assert.Equal(t, len(tables), len(results))
+	})
+}
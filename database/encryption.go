@@ -0,0 +1,333 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/servercfg"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// ENCRYPTED_PREFIX - marks a field value that has been encrypted with a key-encryption key
+const ENCRYPTED_PREFIX = "enc:v1:"
+
+// sensitiveField - a json string field that is encrypted before it is stored
+// an empty key matches every record of the table, an empty field means the whole value is a json string
+type sensitiveField struct {
+	table string
+	key   string
+	field string
+}
+
+// sensitiveFields - the fields that never reach the database in plaintext when an encryption key is configured
+var sensitiveFields = []sensitiveField{
+	{table: EXT_CLIENT_TABLE_NAME, field: "privatekey"},
+	// jwt secret and server private keys
+	{table: SERVERCONF_TABLE_NAME, field: "privatekey"},
+	{table: SERVER_UUID_TABLE_NAME, key: SERVER_UUID_RECORD_KEY, field: "traffickeypriv"},
+	// key pair the license is validated with, see ee/license.go
+	{table: SERVERCONF_TABLE_NAME, key: "netmaker-id-key-pair", field: "private_key"},
+	// cached license validation response, see ee/types.go
+	{table: CACHE_TABLE_NAME, key: "license_response_cache", field: "body"},
+	// tls certificates and keys are stored as pem encoded json strings
+	{table: CERTS_TABLE_NAME},
+	// deleted objects keep their records, ie. ext client private keys, as one json string
+	{table: TRASH_TABLE_NAME, field: "records"},
+}
+
+// encryptionKey - a key-encryption key, the id lets records name the key they were encrypted with
+type encryptionKey struct {
+	id  string
+	key [32]byte
+}
+
+var (
+	encryptionKeysMutex sync.RWMutex
+	// encryptionKeys - the configured key-encryption keys, the first one is used to encrypt
+	encryptionKeys []encryptionKey
+)
+
+// loadEncryptionKeys - reads the key-encryption keys from the server config
+func loadEncryptionKeys() error {
+	raw, err := servercfg.GetDBEncryptionKeys()
+	if err != nil {
+		return fmt.Errorf("failed to read database encryption keys: %w", err)
+	}
+	keys, err := parseEncryptionKeys(raw)
+	if err != nil {
+		return err
+	}
+	encryptionKeysMutex.Lock()
+	encryptionKeys = keys
+	encryptionKeysMutex.Unlock()
+	if len(keys) > 0 {
+		logger.Log(0, "database encryption enabled with key", keys[0].id)
+	}
+	return nil
+}
+
+func parseEncryptionKeys(raw string) ([]encryptionKey, error) {
+	keys := []encryptionKey{}
+	for _, encoded := range strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' '
+	}) {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) != 32 {
+			return nil, errors.New("database encryption keys must be base64 encoded 32 byte keys")
+		}
+		var key encryptionKey
+		copy(key.key[:], decoded)
+		sum := sha256.Sum256(decoded)
+		key.id = hex.EncodeToString(sum[:4])
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func activeEncryptionKey() (encryptionKey, bool) {
+	encryptionKeysMutex.RLock()
+	defer encryptionKeysMutex.RUnlock()
+	if len(encryptionKeys) == 0 {
+		return encryptionKey{}, false
+	}
+	return encryptionKeys[0], true
+}
+
+func findEncryptionKey(id string) (encryptionKey, bool) {
+	encryptionKeysMutex.RLock()
+	defer encryptionKeysMutex.RUnlock()
+	for _, key := range encryptionKeys {
+		if key.id == id {
+			return key, true
+		}
+	}
+	return encryptionKey{}, false
+}
+
+func sealBox(plaintext []byte, key *[32]byte) ([]byte, error) {
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	return secretbox.Seal(nonce[:], plaintext, &nonce, key), nil
+}
+
+func openBox(sealed []byte, key *[32]byte) ([]byte, bool) {
+	if len(sealed) < 24 {
+		return nil, false
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed[:24])
+	return secretbox.Open(nil, sealed[24:], &nonce, key)
+}
+
+// wrapDataKey - seals a data key with a key-encryption key into the stored envelope
+func wrapDataKey(kek encryptionKey, dataKey *[32]byte, data []byte) (string, error) {
+	wrapped, err := sealBox(dataKey[:], &kek.key)
+	if err != nil {
+		return "", err
+	}
+	return ENCRYPTED_PREFIX + kek.id + ":" + base64.StdEncoding.EncodeToString(wrapped) + ":" + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// unwrapDataKey - splits an envelope and recovers its data key
+func unwrapDataKey(envelope string) (kekID string, dataKey *[32]byte, data []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(envelope, ENCRYPTED_PREFIX), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	kek, ok := findEncryptionKey(parts[0])
+	if !ok {
+		return "", nil, nil, fmt.Errorf("value is encrypted with unknown database encryption key %s", parts[0])
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if data, err = base64.StdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, err
+	}
+	key, ok := openBox(wrapped, &kek.key)
+	if !ok || len(key) != 32 {
+		return "", nil, nil, errors.New("failed to unwrap data key")
+	}
+	dataKey = new([32]byte)
+	copy(dataKey[:], key)
+	return kek.id, dataKey, data, nil
+}
+
+// encryptValue - encrypts a value with a fresh data key wrapped by the active key-encryption key
+// values encrypted with an older key only have their data key re-wrapped
+func encryptValue(value string) (string, error) {
+	kek, ok := activeEncryptionKey()
+	if !ok || value == "" {
+		return value, nil
+	}
+	if strings.HasPrefix(value, ENCRYPTED_PREFIX) {
+		kekID, dataKey, data, err := unwrapDataKey(value)
+		if err != nil {
+			return "", err
+		}
+		if kekID == kek.id {
+			return value, nil
+		}
+		return wrapDataKey(kek, dataKey, data)
+	}
+	dataKey := new([32]byte)
+	if _, err := rand.Read(dataKey[:]); err != nil {
+		return "", err
+	}
+	data, err := sealBox([]byte(value), dataKey)
+	if err != nil {
+		return "", err
+	}
+	return wrapDataKey(kek, dataKey, data)
+}
+
+// decryptValue - decrypts an encrypted value, plaintext values are returned as is
+func decryptValue(value string) (string, error) {
+	if !strings.HasPrefix(value, ENCRYPTED_PREFIX) {
+		return value, nil
+	}
+	_, dataKey, data, err := unwrapDataKey(value)
+	if err != nil {
+		return "", err
+	}
+	plaintext, ok := openBox(data, dataKey)
+	if !ok {
+		return "", errors.New("failed to decrypt value")
+	}
+	return string(plaintext), nil
+}
+
+func sensitiveFieldsOf(tableName, key string) []string {
+	fields := []string{}
+	for _, sensitive := range sensitiveFields {
+		if sensitive.table == tableName && (sensitive.key == "" || sensitive.key == key) {
+			fields = append(fields, sensitive.field)
+		}
+	}
+	return fields
+}
+
+// transformRecord - applies transform to the sensitive json string fields of a record
+func transformRecord(tableName, key, value string, transform func(string) (string, error)) (string, error) {
+	fields := sensitiveFieldsOf(tableName, key)
+	if len(fields) == 0 {
+		return value, nil
+	}
+	transformString := func(raw json.RawMessage) (json.RawMessage, error) {
+		var field string
+		if err := json.Unmarshal(raw, &field); err != nil {
+			// not a string, ie. null, nothing to protect
+			return raw, nil
+		}
+		transformed, err := transform(field)
+		if err != nil || transformed == field {
+			return raw, err
+		}
+		return json.Marshal(transformed)
+	}
+	var record map[string]json.RawMessage
+	for _, field := range fields {
+		if field == "" {
+			transformed, err := transformString(json.RawMessage(value))
+			return string(transformed), err
+		}
+		if record == nil {
+			if err := json.Unmarshal([]byte(value), &record); err != nil {
+				// not an object, so it has no fields to protect
+				return value, nil
+			}
+		}
+		raw, ok := record[field]
+		if !ok {
+			continue
+		}
+		transformed, err := transformString(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s field %s of %s: %w", tableName, field, key, err)
+		}
+		record[field] = transformed
+	}
+	if record == nil {
+		return value, nil
+	}
+	data, err := json.Marshal(record)
+	return string(data), err
+}
+
+// encryptRecord - encrypts the sensitive fields of a record before it is stored
+func encryptRecord(tableName, key, value string) (string, error) {
+	if _, ok := activeEncryptionKey(); !ok {
+		return value, nil
+	}
+	return transformRecord(tableName, key, value, encryptValue)
+}
+
+// decryptRecord - decrypts the sensitive fields of a stored record
+func decryptRecord(tableName, key, value string) (string, error) {
+	if !strings.Contains(value, ENCRYPTED_PREFIX) {
+		return value, nil
+	}
+	return transformRecord(tableName, key, value, decryptValue)
+}
+
+func decryptRecords(tableName string, records map[string]string) (map[string]string, error) {
+	for key, value := range records {
+		decrypted, err := decryptRecord(tableName, key, value)
+		if err != nil {
+			return nil, err
+		}
+		records[key] = decrypted
+	}
+	return records, nil
+}
+
+// EncryptSensitiveRecords - encrypts sensitive fields still stored in plaintext and re-wraps the data keys of
+// fields encrypted with an older key, returns the number of records rewritten
+// to rotate keys configure the new key first followed by the old one(s), run this, then drop the old keys
+func EncryptSensitiveRecords() (int, error) {
+	if _, ok := activeEncryptionKey(); !ok {
+		return 0, nil
+	}
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+	tablesDone := map[string]bool{}
+	ops := []TxOp{}
+	for _, sensitive := range sensitiveFields {
+		if tablesDone[sensitive.table] {
+			continue
+		}
+		tablesDone[sensitive.table] = true
+		records, err := getCurrentDB()[FETCH_ALL].(func(string) (map[string]string, error))(sensitive.table)
+		if err != nil {
+			if IsEmptyRecord(err) {
+				continue
+			}
+			return 0, err
+		}
+		for key, value := range records {
+			encrypted, err := encryptRecord(sensitive.table, key, value)
+			if err != nil {
+				return 0, err
+			}
+			if encrypted != value {
+				ops = append(ops, TxOp{Action: INSERT, TableName: sensitive.table, Key: key, Value: encrypted})
+			}
+		}
+	}
+	if len(ops) == 0 {
+		return 0, nil
+	}
+	// the logical content is unchanged, so no revisions are bumped and no changes are published
+	return len(ops), getCurrentDB()[COMMIT_TX].(func([]TxOp) error)(ops)
+}
@@ -1,12 +1,17 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
+	"unicode/utf8"
 
+	"github.com/gravitl/netmaker/logger"
 	"github.com/gravitl/netmaker/servercfg"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // PGDB - database object for PostGreSQL
@@ -14,15 +19,20 @@ var PGDB *sql.DB
 
 // PG_FUNCTIONS - map of db functions for PostGreSQL
 var PG_FUNCTIONS = map[string]interface{}{
-	INIT_DB:      initPGDB,
-	CREATE_TABLE: pgCreateTable,
-	INSERT:       pgInsert,
-	INSERT_PEER:  pgInsertPeer,
-	DELETE:       pgDeleteRecord,
-	DELETE_ALL:   pgDeleteAllRecords,
-	FETCH_ALL:    pgFetchRecords,
-	CLOSE_DB:     pgCloseDB,
-	isConnected:  pgIsConnected,
+	INIT_DB:         initPGDB,
+	CREATE_TABLE:    pgCreateTable,
+	INSERT:          pgInsert,
+	INSERT_PEER:     pgInsertPeer,
+	DELETE:          pgDeleteRecord,
+	DELETE_ALL:      pgDeleteAllRecords,
+	FETCH_ALL:       pgFetchRecords,
+	FETCH_ONE:       pgFetchRecord,
+	FETCH_PREFIX:    pgFetchRecordsByPrefix,
+	COMMIT_TX:       pgCommitTx,
+	PUBLISH_CHANGES: pgPublishChanges,
+	WATCH_CHANGES:   pgWatchChanges,
+	CLOSE_DB:        pgCloseDB,
+	isConnected:     pgIsConnected,
 }
 
 func getPGConnString() string {
@@ -133,6 +143,71 @@ func pgFetchRecords(tableName string) (map[string]string, error) {
 	return records, nil
 }
 
+func pgFetchRecord(tableName string, key string) (string, error) {
+	var value string
+	err := PGDB.QueryRow("SELECT value FROM "+tableName+" WHERE key = $1", key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if pgTableIsEmpty(tableName) {
+				return "", errors.New(NO_RECORDS)
+			}
+			return "", errors.New(NO_RECORD)
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+// pgTableIsEmpty - reports whether a table holds no records, keeps lookup errors consistent with FetchRecords
+func pgTableIsEmpty(tableName string) bool {
+	var one int
+	err := PGDB.QueryRow("SELECT 1 FROM " + tableName + " LIMIT 1").Scan(&one)
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+func pgFetchRecordsByPrefix(tableName string, prefix string) (map[string]string, error) {
+	row, err := PGDB.Query("SELECT key, value FROM "+tableName+" WHERE substr(key, 1, $1) = $2 ORDER BY key", utf8.RuneCountInString(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]string)
+	defer row.Close()
+	for row.Next() {
+		var key string
+		var value string
+		row.Scan(&key, &value)
+		records[key] = value
+	}
+	if len(records) == 0 {
+		return nil, errors.New(NO_RECORDS)
+	}
+	return records, nil
+}
+
+func pgCommitTx(ops []TxOp) error {
+	tx, err := PGDB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		switch op.Action {
+		case INSERT:
+			_, err = tx.Exec("INSERT INTO "+op.TableName+" (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value", op.Key, op.Value)
+		case DELETE_ALL:
+			_, err = tx.Exec("DELETE FROM " + op.TableName)
+		case DELETE:
+			_, err = tx.Exec("DELETE FROM "+op.TableName+" WHERE key = $1", op.Key)
+		default:
+			err = errors.New("unsupported transaction action " + op.Action)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func pgCloseDB() {
 	PGDB.Close()
 }
@@ -141,3 +216,51 @@ func pgIsConnected() bool {
 	stats := PGDB.Stats()
 	return stats.OpenConnections > 0
 }
+
+// pgChangesChannel - the postgres notification channel used to publish writes to other server instances
+const pgChangesChannel = "netmaker_changes"
+
+func pgPublishChanges(changes []Change) error {
+	for _, change := range changes {
+		payload, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		if _, err = PGDB.Exec("SELECT pg_notify($1, $2)", pgChangesChannel, string(payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pgWatchChanges(ctx context.Context, deliver func(Change)) error {
+	listener := pq.NewListener(getPGConnString(), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Log(0, "database change listener:", err.Error())
+		}
+	})
+	defer listener.Close()
+	if err := listener.Listen(pgChangesChannel); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			if notification == nil {
+				// the connection was re-established, any notifications sent in between are lost
+				for table := range revisionedTables {
+					tableChanged(deliver, table)
+				}
+				continue
+			}
+			var change Change
+			if err := json.Unmarshal([]byte(notification.Extra), &change); err != nil {
+				logger.Log(0, "invalid database change notification:", err.Error())
+				continue
+			}
+			deliver(change)
+		}
+	}
+}
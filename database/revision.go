@@ -0,0 +1,157 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrRevisionMismatch - returned when a transaction expected a record to be at a revision it no longer has
+var ErrRevisionMismatch = errors.New("record has been modified since it was read")
+
+// revisionedTables - tables whose records carry a revision counter, bumped on every write
+var revisionedTables = map[string]bool{
+	NETWORKS_TABLE_NAME:   true,
+	NODES_TABLE_NAME:      true,
+	HOSTS_TABLE_NAME:      true,
+	EXT_CLIENT_TABLE_NAME: true,
+	NODE_ACLS_TABLE_NAME:  true,
+	// peerings are cached in memory, so other replicas need to hear of their changes
+	NETWORK_PEERINGS_TABLE_NAME: true,
+	// the allocator state is read, changed and written back, the revision lets a queued release detect a change made
+	// in between
+	IPAM_TABLE_NAME: true,
+}
+
+type revisionRecord struct {
+	Revision uint64 `json:"revision"`
+}
+
+func revisionKey(tableName, key string) string {
+	return tableName + "/" + key
+}
+
+// GetRevision - returns the current revision of a record, 0 if it has never been written with revisions enabled
+func GetRevision(tableName string, key string) (uint64, error) {
+	dbMutex.RLock()
+	defer dbMutex.RUnlock()
+	return fetchRevision(revisionKey(tableName, key))
+}
+
+// fetchRevision - reads a revision, caller must hold dbMutex
+func fetchRevision(revKey string) (uint64, error) {
+	data, err := getCurrentDB()[FETCH_ONE].(func(string, string) (string, error))(REVISIONS_TABLE_NAME, revKey)
+	if err != nil {
+		if IsEmptyRecord(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var record revisionRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return 0, err
+	}
+	return record.Revision, nil
+}
+
+// withRevisions - adds the revision bumps for any writes to revisioned tables, caller must hold dbMutex
+// a deleted record keeps counting from its last revision if it is written again in the same transaction
+func withRevisions(ops []TxOp) ([]TxOp, error) {
+	revisions := map[string]uint64{}
+	result := make([]TxOp, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, op)
+		if !revisionedTables[op.TableName] {
+			continue
+		}
+		if op.Action == DELETE_ALL {
+			// the revisions of every record of the table are deleted with it
+			prefix := revisionKey(op.TableName, "")
+			records, err := getCurrentDB()[FETCH_PREFIX].(func(string, string) (map[string]string, error))(REVISIONS_TABLE_NAME, prefix)
+			if err != nil && !IsEmptyRecord(err) {
+				return nil, err
+			}
+			for revKey, value := range records {
+				if _, ok := revisions[revKey]; ok {
+					continue
+				}
+				if revisions[revKey], err = parseRevision(value); err != nil {
+					return nil, err
+				}
+			}
+			for revKey := range revisions {
+				if strings.HasPrefix(revKey, prefix) {
+					result = append(result, TxOp{Action: DELETE, TableName: REVISIONS_TABLE_NAME, Key: revKey})
+				}
+			}
+			continue
+		}
+		if op.Action != INSERT && op.Action != DELETE {
+			continue
+		}
+		revKey := revisionKey(op.TableName, op.Key)
+		current, ok := revisions[revKey]
+		if !ok {
+			var err error
+			if current, err = fetchRevision(revKey); err != nil {
+				return nil, err
+			}
+		}
+		if op.Action == DELETE {
+			revisions[revKey] = current
+			result = append(result, TxOp{Action: DELETE, TableName: REVISIONS_TABLE_NAME, Key: revKey})
+			continue
+		}
+		revisions[revKey] = current + 1
+		result = append(result, TxOp{
+			Action:    INSERT,
+			TableName: REVISIONS_TABLE_NAME,
+			Key:       revKey,
+			Value:     `{"revision":` + strconv.FormatUint(current+1, 10) + `}`,
+		})
+	}
+	return result, nil
+}
+
+// checkRevisions - verifies the revisions a transaction expects and returns its remaining writes, caller must hold dbMutex
+func checkRevisions(ops []TxOp) ([]TxOp, error) {
+	writes := make([]TxOp, 0, len(ops))
+	for _, op := range ops {
+		if op.Action != CHECK_REVISION {
+			writes = append(writes, op)
+			continue
+		}
+		current, err := fetchRevision(revisionKey(op.TableName, op.Key))
+		if err != nil {
+			return nil, err
+		}
+		if strconv.FormatUint(current, 10) != op.Value {
+			return nil, ErrRevisionMismatch
+		}
+	}
+	return writes, nil
+}
+
+// commitWithRevisions - checks the expected revisions of a set of writes and applies them along with their revision
+// bumps and expiration updates, caller must hold dbMutex
+func commitWithRevisions(ops []TxOp) error {
+	ops, err := checkRevisions(ops)
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if ops, err = withExpirations(ops); err != nil {
+		return err
+	}
+	if ops, err = withRevisions(ops); err != nil {
+		return err
+	}
+	if err = getCurrentDB()[COMMIT_TX].(func([]TxOp) error)(ops); err != nil {
+		return err
+	}
+	recordChanges(ops)
+	return nil
+}
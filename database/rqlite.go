@@ -1,7 +1,11 @@
 package database
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gravitl/netmaker/servercfg"
 	"github.com/rqlite/gorqlite"
@@ -12,15 +16,20 @@ var RQliteDatabase gorqlite.Connection
 
 // RQLITE_FUNCTIONS - all the functions to run with rqlite
 var RQLITE_FUNCTIONS = map[string]interface{}{
-	INIT_DB:      initRqliteDatabase,
-	CREATE_TABLE: rqliteCreateTable,
-	INSERT:       rqliteInsert,
-	INSERT_PEER:  rqliteInsertPeer,
-	DELETE:       rqliteDeleteRecord,
-	DELETE_ALL:   rqliteDeleteAllRecords,
-	FETCH_ALL:    rqliteFetchRecords,
-	CLOSE_DB:     rqliteCloseDB,
-	isConnected:  rqliteConnected,
+	INIT_DB:         initRqliteDatabase,
+	CREATE_TABLE:    rqliteCreateTable,
+	INSERT:          rqliteInsert,
+	INSERT_PEER:     rqliteInsertPeer,
+	DELETE:          rqliteDeleteRecord,
+	DELETE_ALL:      rqliteDeleteAllRecords,
+	FETCH_ALL:       rqliteFetchRecords,
+	FETCH_ONE:       rqliteFetchRecord,
+	FETCH_PREFIX:    rqliteFetchRecordsByPrefix,
+	COMMIT_TX:       rqliteCommitTx,
+	PUBLISH_CHANGES: publishNoop,
+	WATCH_CHANGES:   rqliteWatchChanges,
+	CLOSE_DB:        rqliteCloseDB,
+	isConnected:     rqliteConnected,
 }
 
 func initRqliteDatabase() error {
@@ -102,6 +111,76 @@ func rqliteFetchRecords(tableName string) (map[string]string, error) {
 	return records, nil
 }
 
+func rqliteFetchRecord(tableName string, key string) (string, error) {
+	row, err := RQliteDatabase.QueryOne("SELECT value FROM " + tableName + " WHERE key = '" + rqliteEscape(key) + "'")
+	if err != nil {
+		return "", err
+	}
+	if !row.Next() {
+		if rqliteTableIsEmpty(tableName) {
+			return "", errors.New(NO_RECORDS)
+		}
+		return "", errors.New(NO_RECORD)
+	}
+	var value string
+	if err = row.Scan(&value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// rqliteTableIsEmpty - reports whether a table holds no records, keeps lookup errors consistent with FetchRecords
+func rqliteTableIsEmpty(tableName string) bool {
+	row, err := RQliteDatabase.QueryOne("SELECT 1 FROM " + tableName + " LIMIT 1")
+	return err == nil && row.NumRows() == 0
+}
+
+func rqliteFetchRecordsByPrefix(tableName string, prefix string) (map[string]string, error) {
+	row, err := RQliteDatabase.QueryOne("SELECT key, value FROM " + tableName +
+		" WHERE substr(key, 1, " + strconv.Itoa(utf8.RuneCountInString(prefix)) + ") = '" + rqliteEscape(prefix) + "' ORDER BY key")
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]string)
+	for row.Next() {
+		var key string
+		var value string
+		row.Scan(&key, &value)
+		records[key] = value
+	}
+	if len(records) == 0 {
+		return nil, errors.New(NO_RECORDS)
+	}
+	return records, nil
+}
+
+// rqliteEscape - escapes single quotes so a value can be embedded in an rqlite statement
+func rqliteEscape(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+func rqliteCommitTx(ops []TxOp) error {
+	statements := []string{}
+	for _, op := range ops {
+		switch op.Action {
+		case INSERT:
+			statements = append(statements, "INSERT OR REPLACE INTO "+op.TableName+" (key, value) VALUES ('"+rqliteEscape(op.Key)+"', '"+rqliteEscape(op.Value)+"')")
+		case DELETE_ALL:
+			statements = append(statements, "DELETE FROM "+op.TableName)
+		case DELETE:
+			statements = append(statements, "DELETE FROM "+op.TableName+" WHERE key = '"+rqliteEscape(op.Key)+"'")
+		default:
+			return errors.New("unsupported transaction action " + op.Action)
+		}
+	}
+	if err := RQliteDatabase.SetExecutionWithTransaction(true); err != nil {
+		return err
+	}
+	defer RQliteDatabase.SetExecutionWithTransaction(false)
+	_, err := RQliteDatabase.Write(statements)
+	return err
+}
+
 func rqliteCloseDB() {
 	RQliteDatabase.Close()
 }
@@ -110,3 +189,7 @@ func rqliteConnected() bool {
 	leader, err := RQliteDatabase.Leader()
 	return err == nil && len(leader) > 0
 }
+
+func rqliteWatchChanges(ctx context.Context, deliver func(Change)) error {
+	return pollChanges(ctx, rqliteFetchRecords, deliver)
+}
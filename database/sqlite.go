@@ -1,10 +1,12 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"os"
 	"path/filepath"
+	"unicode/utf8"
 
 	_ "github.com/mattn/go-sqlite3" // need to blank import this package
 )
@@ -17,15 +19,20 @@ var SqliteDB *sql.DB
 
 // SQLITE_FUNCTIONS - contains a map of the functions for sqlite
 var SQLITE_FUNCTIONS = map[string]interface{}{
-	INIT_DB:      initSqliteDB,
-	CREATE_TABLE: sqliteCreateTable,
-	INSERT:       sqliteInsert,
-	INSERT_PEER:  sqliteInsertPeer,
-	DELETE:       sqliteDeleteRecord,
-	DELETE_ALL:   sqliteDeleteAllRecords,
-	FETCH_ALL:    sqliteFetchRecords,
-	CLOSE_DB:     sqliteCloseDB,
-	isConnected:  sqliteConnected,
+	INIT_DB:         initSqliteDB,
+	CREATE_TABLE:    sqliteCreateTable,
+	INSERT:          sqliteInsert,
+	INSERT_PEER:     sqliteInsertPeer,
+	DELETE:          sqliteDeleteRecord,
+	DELETE_ALL:      sqliteDeleteAllRecords,
+	FETCH_ALL:       sqliteFetchRecords,
+	FETCH_ONE:       sqliteFetchRecord,
+	FETCH_PREFIX:    sqliteFetchRecordsByPrefix,
+	COMMIT_TX:       sqliteCommitTx,
+	PUBLISH_CHANGES: publishNoop,
+	WATCH_CHANGES:   sqliteWatchChanges,
+	CLOSE_DB:        sqliteCloseDB,
+	isConnected:     sqliteConnected,
 }
 
 func initSqliteDB() error {
@@ -133,6 +140,71 @@ func sqliteFetchRecords(tableName string) (map[string]string, error) {
 	return records, nil
 }
 
+func sqliteFetchRecord(tableName string, key string) (string, error) {
+	var value string
+	err := SqliteDB.QueryRow("SELECT value FROM "+tableName+" WHERE key = ?", key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if sqliteTableIsEmpty(tableName) {
+				return "", errors.New(NO_RECORDS)
+			}
+			return "", errors.New(NO_RECORD)
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+// sqliteTableIsEmpty - reports whether a table holds no records, keeps lookup errors consistent with FetchRecords
+func sqliteTableIsEmpty(tableName string) bool {
+	var one int
+	err := SqliteDB.QueryRow("SELECT 1 FROM " + tableName + " LIMIT 1").Scan(&one)
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+func sqliteFetchRecordsByPrefix(tableName string, prefix string) (map[string]string, error) {
+	row, err := SqliteDB.Query("SELECT key, value FROM "+tableName+" WHERE substr(key, 1, ?) = ? ORDER BY key", utf8.RuneCountInString(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]string)
+	defer row.Close()
+	for row.Next() {
+		var key string
+		var value string
+		row.Scan(&key, &value)
+		records[key] = value
+	}
+	if len(records) == 0 {
+		return nil, errors.New(NO_RECORDS)
+	}
+	return records, nil
+}
+
+func sqliteCommitTx(ops []TxOp) error {
+	tx, err := SqliteDB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		switch op.Action {
+		case INSERT:
+			_, err = tx.Exec("INSERT OR REPLACE INTO "+op.TableName+" (key, value) VALUES (?, ?)", op.Key, op.Value)
+		case DELETE_ALL:
+			_, err = tx.Exec("DELETE FROM " + op.TableName)
+		case DELETE:
+			_, err = tx.Exec("DELETE FROM "+op.TableName+" WHERE key = ?", op.Key)
+		default:
+			err = errors.New("unsupported transaction action " + op.Action)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func sqliteCloseDB() {
 	SqliteDB.Close()
 }
@@ -141,3 +213,7 @@ func sqliteConnected() bool {
 	stats := SqliteDB.Stats()
 	return stats.OpenConnections > 0
 }
+
+func sqliteWatchChanges(ctx context.Context, deliver func(Change)) error {
+	return pollChanges(ctx, sqliteFetchRecords, deliver)
+}
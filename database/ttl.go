@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gravitl/netmaker/logger"
+)
+
+// REAP_INTERVAL - how often expired records are removed from the database
+const REAP_INTERVAL = time.Minute
+
+// expiringTables - tables whose records may be written with a time to live
+var expiringTables = map[string]bool{
+	SSO_STATE_CACHE:            true,
+	CACHE_TABLE_NAME:           true,
+	HOST_ACTIONS_TABLE_NAME:    true,
+	ENROLLMENT_KEYS_TABLE_NAME: true,
+	TRASH_TABLE_NAME:           true,
+	BULK_JOBS_TABLE_NAME:       true,
+}
+
+type expirationRecord struct {
+	Expires int64 `json:"expires"`
+}
+
+// InsertWithTTL - inserts a record that is no longer returned once ttl has passed and is then removed by the reaper
+// inserting the same key again without a ttl keeps the record forever
+func InsertWithTTL(key string, value string, tableName string, ttl time.Duration) error {
+	ops, err := insertWithTTLOps(key, value, tableName, ttl)
+	if err != nil {
+		return err
+	}
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+	return commitWithRevisions(ops)
+}
+
+// Tx.InsertWithTTL - queues an insert of a record that expires once ttl has passed
+func (tx *Tx) InsertWithTTL(key string, value string, tableName string, ttl time.Duration) error {
+	ops, err := insertWithTTLOps(key, value, tableName, ttl)
+	if err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.ops = append(tx.ops, ops...)
+	return nil
+}
+
+// insertWithTTLOps - validates and encrypts a record and returns the writes storing it with its expiration
+func insertWithTTLOps(key string, value string, tableName string, ttl time.Duration) ([]TxOp, error) {
+	if !expiringTables[tableName] {
+		return nil, errors.New("table " + tableName + " does not support expiring records")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("invalid ttl " + ttl.String())
+	}
+	if key == "" || value == "" || !IsJSONString(value) {
+		return nil, errors.New("invalid insert " + key + " : " + value)
+	}
+	value, err := encryptRecord(tableName, key, value)
+	if err != nil {
+		return nil, err
+	}
+	return []TxOp{
+		{Action: INSERT, TableName: tableName, Key: key, Value: value},
+		{
+			Action:    INSERT,
+			TableName: EXPIRATIONS_TABLE_NAME,
+			Key:       revisionKey(tableName, key),
+			Value:     `{"expires":` + strconv.FormatInt(time.Now().Add(ttl).Unix(), 10) + `}`,
+		},
+	}, nil
+}
+
+// withExpirations - clears the expiration of any record on an expiring table that is written without one, and of
+// every record of an expiring table that is cleared, caller must hold dbMutex
+func withExpirations(ops []TxOp) ([]TxOp, error) {
+	expiring := map[string]bool{}
+	for _, op := range ops {
+		if op.TableName == EXPIRATIONS_TABLE_NAME && op.Action == INSERT {
+			expiring[op.Key] = true
+		}
+	}
+	result := make([]TxOp, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, op)
+		if !expiringTables[op.TableName] {
+			continue
+		}
+		if op.Action == DELETE_ALL {
+			expirations, err := fetchExpirations(op.TableName)
+			if err != nil {
+				return nil, err
+			}
+			for expKey := range expirations {
+				result = append(result, TxOp{Action: DELETE, TableName: EXPIRATIONS_TABLE_NAME, Key: expKey})
+			}
+			continue
+		}
+		if op.Action != INSERT && op.Action != DELETE {
+			continue
+		}
+		if expKey := revisionKey(op.TableName, op.Key); !expiring[expKey] {
+			result = append(result, TxOp{Action: DELETE, TableName: EXPIRATIONS_TABLE_NAME, Key: expKey})
+		}
+	}
+	return result, nil
+}
+
+// fetchExpirations - reads the expiration times of a table's records, caller must hold dbMutex
+func fetchExpirations(tableName string) (map[string]time.Time, error) {
+	records, err := getCurrentDB()[FETCH_PREFIX].(func(string, string) (map[string]string, error))(EXPIRATIONS_TABLE_NAME, tableName+"/")
+	if err != nil {
+		if IsEmptyRecord(err) {
+			return map[string]time.Time{}, nil
+		}
+		return nil, err
+	}
+	return parseExpirations(records)
+}
+
+func parseExpirations(records map[string]string) (map[string]time.Time, error) {
+	expirations := make(map[string]time.Time, len(records))
+	for key, value := range records {
+		var record expirationRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			return nil, err
+		}
+		expirations[key] = time.Unix(record.Expires, 0)
+	}
+	return expirations, nil
+}
+
+// isExpired - checks if a record has expired, caller must hold dbMutex
+func isExpired(tableName string, key string) (bool, error) {
+	data, err := getCurrentDB()[FETCH_ONE].(func(string, string) (string, error))(EXPIRATIONS_TABLE_NAME, revisionKey(tableName, key))
+	if err != nil {
+		if IsEmptyRecord(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	var record expirationRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return false, err
+	}
+	return !time.Now().Before(time.Unix(record.Expires, 0)), nil
+}
+
+// dropExpired - removes expired records from a fetch result, caller must hold dbMutex
+// expired records still exist until they are reaped but are never returned
+func dropExpired(tableName string, records map[string]string) (map[string]string, error) {
+	if !expiringTables[tableName] {
+		return records, nil
+	}
+	expirations, err := fetchExpirations(tableName)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for key, expires := range expirations {
+		if !now.Before(expires) {
+			delete(records, strings.TrimPrefix(key, tableName+"/"))
+		}
+	}
+	if len(records) == 0 {
+		return nil, errors.New(NO_RECORDS)
+	}
+	return records, nil
+}
+
+// ReapExpiredRecords - deletes every expired record, returns the number of records removed
+func ReapExpiredRecords() (int, error) {
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+	records, err := getCurrentDB()[FETCH_ALL].(func(string) (map[string]string, error))(EXPIRATIONS_TABLE_NAME)
+	if err != nil {
+		if IsEmptyRecord(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	expirations, err := parseExpirations(records)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	ops := []TxOp{}
+	for expKey, expires := range expirations {
+		if now.Before(expires) {
+			continue
+		}
+		tableName, key, found := strings.Cut(expKey, "/")
+		if !found || !expiringTables[tableName] {
+			ops = append(ops, TxOp{Action: DELETE, TableName: EXPIRATIONS_TABLE_NAME, Key: expKey})
+			continue
+		}
+		// the expiration entry is removed along with the record by withExpirations
+		ops = append(ops, TxOp{Action: DELETE, TableName: tableName, Key: key})
+	}
+	if len(ops) == 0 {
+		return 0, nil
+	}
+	return len(ops), commitWithRevisions(ops)
+}
+
+// StartReaper - periodically removes expired records until ctx is done
+// every server may run the reaper, deleting an already reaped record is a no-op
+func StartReaper(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(REAP_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reaped, err := ReapExpiredRecords()
+				if err != nil {
+					logger.Log(0, "failed to reap expired records:", err.Error())
+					continue
+				}
+				if reaped > 0 {
+					logger.Log(3, "reaped", strconv.Itoa(reaped), "expired records")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// TxOp - a single write queued in a transaction
+type TxOp struct {
+	Action    string
+	TableName string
+	Key       string
+	Value     string
+}
+
+// Tx - a set of writes across one or more tables that is committed atomically
+// writes are buffered in memory until Commit, reads are not affected by pending writes
+type Tx struct {
+	mu        sync.Mutex
+	ops       []TxOp
+	onCommit  []func()
+	committed bool
+}
+
+// BeginTx - starts a new transaction
+func BeginTx() *Tx {
+	return &Tx{}
+}
+
+// Tx.Insert - queues an insert (or replace) of a record
+func (tx *Tx) Insert(key string, value string, tableName string) error {
+	if key == "" || value == "" || !IsJSONString(value) {
+		return errors.New("invalid insert " + key + " : " + value)
+	}
+	value, err := encryptRecord(tableName, key, value)
+	if err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.ops = append(tx.ops, TxOp{Action: INSERT, TableName: tableName, Key: key, Value: value})
+	return nil
+}
+
+// Tx.Delete - queues the deletion of a record
+func (tx *Tx) Delete(tableName string, key string) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.ops = append(tx.ops, TxOp{Action: DELETE, TableName: tableName, Key: key})
+}
+
+// Tx.DeleteAll - queues the deletion of every record in a table
+func (tx *Tx) DeleteAll(tableName string) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.ops = append(tx.ops, TxOp{Action: DELETE_ALL, TableName: tableName})
+}
+
+// Tx.ExpectRevision - makes the commit fail with ErrRevisionMismatch unless a record of a revisioned table is still
+// at the given revision, the check is made under the same lock as the writes
+func (tx *Tx) ExpectRevision(tableName string, key string, revision uint64) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.ops = append(tx.ops, TxOp{Action: CHECK_REVISION, TableName: tableName, Key: key, Value: strconv.FormatUint(revision, 10)})
+}
+
+// Tx.OnCommit - registers a func to run once the transaction has been committed, ie. cache updates
+func (tx *Tx) OnCommit(f func()) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.onCommit = append(tx.onCommit, f)
+}
+
+// Tx.Commit - applies all queued writes atomically, either all are stored or none are
+func (tx *Tx) Commit() error {
+	tx.mu.Lock()
+	if tx.committed {
+		tx.mu.Unlock()
+		return errors.New("transaction already committed")
+	}
+	if len(tx.ops) > 0 {
+		dbMutex.Lock()
+		err := commitWithRevisions(tx.ops)
+		dbMutex.Unlock()
+		if err != nil {
+			tx.mu.Unlock()
+			return err
+		}
+	}
+	tx.committed = true
+	onCommit := tx.onCommit
+	tx.mu.Unlock()
+	for _, f := range onCommit {
+		f()
+	}
+	return nil
+}
@@ -44,6 +44,7 @@ require (
 	github.com/matryer/is v1.4.1
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/spf13/cobra v1.7.0
+	go.etcd.io/bbolt v1.3.7
 )
 
 require (
@@ -33,6 +33,13 @@ func DeleteAclFromCache(containerID ContainerID) {
 	aclCacheMutex.Unlock()
 }
 
+// ClearAclCache - drops all cached ACL containers, they are reloaded from the db on next use
+func ClearAclCache() {
+	aclCacheMutex.Lock()
+	aclCacheMap = make(map[ContainerID]ACLContainer)
+	aclCacheMutex.Unlock()
+}
+
 // == type functions ==
 
 // ACL.Allow - allows access by ID in memory
@@ -112,6 +119,52 @@ func (aclContainer ACLContainer) Save(containerID ContainerID) (ACLContainer, er
 	return upsertACLContainer(containerID, aclContainer)
 }
 
+// ACLContainer.SaveAtRevision - saves the state of a ACLContainer only if its record is still at the given revision,
+// a nil revision always saves
+func (aclContainer ACLContainer) SaveAtRevision(containerID ContainerID, revision *uint64) (ACLContainer, error) {
+	if revision == nil {
+		return aclContainer.Save(containerID)
+	}
+	if aclContainer == nil {
+		aclContainer = make(ACLContainer)
+	}
+	tx := database.BeginTx()
+	tx.ExpectRevision(database.NODE_ACLS_TABLE_NAME, string(containerID), *revision)
+	if err := aclContainer.SaveTx(tx, containerID); err != nil {
+		return aclContainer, err
+	}
+	return aclContainer, tx.Commit()
+}
+
+// ACLContainer.SaveTx - queues the state of a ACLContainer in a db transaction, the cache is updated on commit
+func (aclContainer ACLContainer) SaveTx(tx *database.Tx, containerID ContainerID) error {
+	aclMutex.RLock()
+	data := convertNetworkACLtoACLJson(aclContainer)
+	aclMutex.RUnlock()
+	if err := tx.Insert(string(containerID), string(data), database.NODE_ACLS_TABLE_NAME); err != nil {
+		return err
+	}
+	tx.OnCommit(func() {
+		storeAclContainerInCache(containerID, aclContainer)
+	})
+	return nil
+}
+
+// ACLContainer.Copy - returns a deep copy of the ACLContainer so it can be modified without touching the cache
+func (aclContainer ACLContainer) Copy() ACLContainer {
+	aclMutex.RLock()
+	defer aclMutex.RUnlock()
+	newContainer := make(ACLContainer, len(aclContainer))
+	for id, acl := range aclContainer {
+		newACL := make(ACL, len(acl))
+		for peerID, value := range acl {
+			newACL[peerID] = value
+		}
+		newContainer[id] = newACL
+	}
+	return newContainer
+}
+
 // ACLContainer.New - saves the state of a ACLContainer to the db
 func (aclContainer ACLContainer) New(containerID ContainerID) (ACLContainer, error) {
 	return upsertACLContainer(containerID, nil)
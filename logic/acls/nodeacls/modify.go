@@ -7,31 +7,40 @@ import (
 
 // CreateNodeACL - inserts or updates a node ACL on given network and adds to state
 func CreateNodeACL(networkID NetworkID, nodeID NodeID, defaultVal byte) (acls.ACL, error) {
+	tx := database.BeginTx()
+	nodeACL, err := CreateNodeACLTx(tx, networkID, nodeID, defaultVal)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return nodeACL, nil
+}
+
+// CreateNodeACLTx - same as CreateNodeACL but queues the write in given db transaction
+func CreateNodeACLTx(tx *database.Tx, networkID NetworkID, nodeID NodeID, defaultVal byte) (acls.ACL, error) {
 	if defaultVal != acls.NotAllowed && defaultVal != acls.Allowed {
 		defaultVal = acls.NotAllowed
 	}
 	var currentNetworkACL, err = FetchAllACLs(networkID)
 	if err != nil {
-		if database.IsEmptyRecord(err) {
-			currentNetworkACL, err = currentNetworkACL.New(acls.ContainerID(networkID))
-			if err != nil {
-				return nil, err
-			}
-		} else {
+		if !database.IsEmptyRecord(err) {
 			return nil, err
 		}
+		currentNetworkACL = make(acls.ACLContainer)
 	}
+	currentNetworkACL = currentNetworkACL.Copy()
 	var newNodeACL = make(acls.ACL)
 	for existingNodeID := range currentNetworkACL {
 		currentNetworkACL[existingNodeID][acls.AclID(nodeID)] = defaultVal // set the old nodes to default value for new node
 		newNodeACL[existingNodeID] = defaultVal                            // set the old nodes in new node ACL to default value
 	}
-	currentNetworkACL[acls.AclID(nodeID)] = newNodeACL                        // append the new node's ACL
-	retNetworkACL, err := currentNetworkACL.Save(acls.ContainerID(networkID)) // insert into db
-	if err != nil {
+	currentNetworkACL[acls.AclID(nodeID)] = newNodeACL // append the new node's ACL
+	if err = currentNetworkACL.SaveTx(tx, acls.ContainerID(networkID)); err != nil {
 		return nil, err
 	}
-	return retNetworkACL[acls.AclID(nodeID)], nil
+	return newNodeACL, nil
 }
 
 // AllowNode - allow access between two nodes in memory
@@ -68,17 +77,34 @@ func UpdateNodeACL(networkID NetworkID, nodeID NodeID, acl acls.ACL) (acls.ACL,
 
 // RemoveNodeACL - removes a specific Node's ACL, returns the NetworkACL and error
 func RemoveNodeACL(networkID NetworkID, nodeID NodeID) (acls.ACLContainer, error) {
+	tx := database.BeginTx()
+	currentNetworkACL, err := RemoveNodeACLTx(tx, networkID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return currentNetworkACL, nil
+}
+
+// RemoveNodeACLTx - same as RemoveNodeACL but queues the write in given db transaction
+func RemoveNodeACLTx(tx *database.Tx, networkID NetworkID, nodeID NodeID) (acls.ACLContainer, error) {
 	var currentNetworkACL, err = FetchAllACLs(networkID)
 	if err != nil {
 		return nil, err
 	}
+	currentNetworkACL = currentNetworkACL.Copy()
 	for currentNodeID := range currentNetworkACL {
 		if NodeID(currentNodeID) != nodeID {
-			currentNetworkACL[currentNodeID].Remove(acls.AclID(nodeID))
+			delete(currentNetworkACL[currentNodeID], acls.AclID(nodeID))
 		}
 	}
 	delete(currentNetworkACL, acls.AclID(nodeID))
-	return currentNetworkACL.Save(acls.ContainerID(networkID))
+	if err = currentNetworkACL.SaveTx(tx, acls.ContainerID(networkID)); err != nil {
+		return nil, err
+	}
+	return currentNetworkACL, nil
 }
 
 // DeleteACLContainer - removes an ACLContainer state from db
@@ -90,3 +116,41 @@ func DeleteACLContainer(network NetworkID) error {
 	acls.DeleteAclFromCache(acls.ContainerID(network))
 	return nil
 }
+
+// DeleteACLContainerTx - same as DeleteACLContainer but queues the delete in given db transaction
+func DeleteACLContainerTx(tx *database.Tx, network NetworkID) {
+	tx.Delete(database.NODE_ACLS_TABLE_NAME, string(network))
+	tx.OnCommit(func() {
+		acls.DeleteAclFromCache(acls.ContainerID(network))
+	})
+}
+
+// RestoreNodeACLTx - re-adds a removed node's ACL in given db transaction, access to nodes that joined after it was
+// removed is set to defaultVal
+func RestoreNodeACLTx(tx *database.Tx, networkID NetworkID, nodeID NodeID, nodeACL acls.ACL, defaultVal byte) error {
+	if defaultVal != acls.NotAllowed && defaultVal != acls.Allowed {
+		defaultVal = acls.NotAllowed
+	}
+	var currentNetworkACL, err = FetchAllACLs(networkID)
+	if err != nil {
+		if !database.IsEmptyRecord(err) {
+			return err
+		}
+		currentNetworkACL = make(acls.ACLContainer)
+	}
+	currentNetworkACL = currentNetworkACL.Copy()
+	var restoredACL = make(acls.ACL)
+	for existingNodeID := range currentNetworkACL {
+		if existingNodeID == acls.AclID(nodeID) {
+			continue
+		}
+		value, ok := nodeACL[existingNodeID]
+		if !ok {
+			value = defaultVal
+		}
+		currentNetworkACL[existingNodeID][acls.AclID(nodeID)] = value
+		restoredACL[existingNodeID] = value
+	}
+	currentNetworkACL[acls.AclID(nodeID)] = restoredACL
+	return currentNetworkACL.SaveTx(tx, acls.ContainerID(networkID))
+}
@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/models"
+)
+
+// RegisterHostNetwork - adds a host that registered itself to a network, its node is pending approval when the
+// enrollment key it registered with or the network requires approval
+func RegisterHostNetwork(h *models.Host, network string, keyRequiresApproval bool) (*models.Node, error) {
+	pendingApproval := keyRequiresApproval
+	if !pendingApproval {
+		net, err := GetNetwork(network)
+		if err != nil {
+			return nil, err
+		}
+		pendingApproval = net.RequiresApproval
+	}
+	node, err := updateHostNetwork(h, network, true, pendingApproval)
+	if err == nil && pendingApproval {
+		logger.Log(1, "node", node.ID.String(), "of host", h.Name, "in network", network, "is pending approval")
+	}
+	return node, err
+}
+
+// SetApprovalPolicy - sets whether hosts registering themselves into a network need their nodes approved
+// the network is only saved if it is still at the given revision, see updateNetworkAtRevision
+func SetApprovalPolicy(networkName string, policy models.ApprovalPolicy, revision *uint64) (models.Network, error) {
+	return updateNetworkAtRevision(networkName, revision, func(network *models.Network) error {
+		network.RequiresApproval = policy.RequiresApproval
+		return nil
+	})
+}
+
+// ApproveNode - lets a pending node get its peers and records who approved it, both in one db transaction
+func ApproveNode(node *models.Node, user string) error {
+	if !node.IsPending {
+		return errors.New("node is not pending approval")
+	}
+	node.IsPending = false
+	tx := database.BeginTx()
+	err := upsertNodeTx(tx, node)
+	if err == nil {
+		err = recordNodeApprovalTx(tx, node, models.NodeApproved, user)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		node.IsPending = true
+	}
+	return err
+}
+
+// RecordNodeApproval - adds an approval or rejection of a node to the audit log of its network
+func RecordNodeApproval(node *models.Node, decision models.ApprovalDecision, user string) error {
+	tx := database.BeginTx()
+	if err := recordNodeApprovalTx(tx, node, decision, user); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordNodeApprovalTx - queues the audit record of an approval or rejection of a node in given db transaction
+func recordNodeApprovalTx(tx *database.Tx, node *models.Node, decision models.ApprovalDecision, user string) error {
+	approval := models.NodeApproval{
+		ID:       uuid.New().String(),
+		NodeID:   node.ID.String(),
+		HostID:   node.HostID.String(),
+		Network:  node.Network,
+		Decision: decision,
+		User:     user,
+		Time:     time.Now(),
+	}
+	if host, err := GetHost(node.HostID.String()); err == nil {
+		approval.HostName = host.Name
+	}
+	data, err := json.Marshal(&approval)
+	if err != nil {
+		return err
+	}
+	if err = tx.Insert(approval.ID, string(data), database.NODE_APPROVALS_TABLE_NAME); err != nil {
+		return err
+	}
+	tx.OnCommit(func() {
+		logger.Log(1, user, string(decision), "node", approval.NodeID, "of host", approval.HostName, "in network", approval.Network)
+	})
+	return nil
+}
+
+// GetNodeApprovals - returns the approvals and rejections of nodes in a network, newest first
+func GetNodeApprovals(network string) ([]models.NodeApproval, error) {
+	approvals := []models.NodeApproval{}
+	records, err := database.FetchRecords(database.NODE_APPROVALS_TABLE_NAME)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return approvals, err
+	}
+	for _, value := range records {
+		var approval models.NodeApproval
+		if err := json.Unmarshal([]byte(value), &approval); err != nil {
+			continue
+		}
+		if approval.Network == network {
+			approvals = append(approvals, approval)
+		}
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].Time.After(approvals[j].Time)
+	})
+	return approvals, nil
+}
@@ -0,0 +1,81 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeApproval(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("approvalnet")
+	})
+	CreateNetwork(models.Network{NetID: "approvalnet", AddressRange: "10.85.0.0/24"})
+	pending := models.Host{ID: uuid.New(), Name: "approvalpending", HostPass: "password", OS: "linux"}
+	peer := models.Host{ID: uuid.New(), Name: "approvalpeer", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&pending))
+	assert.Nil(t, CreateHost(&peer))
+	t.Cleanup(func() {
+		RemoveHost(&pending, true)
+		RemoveHost(&peer, true)
+	})
+	peerNode, err := RegisterHostNetwork(&peer, "approvalnet", false)
+	assert.Nil(t, err)
+	assert.False(t, peerNode.IsPending)
+	node, err := RegisterHostNetwork(&pending, "approvalnet", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(peerNode, true)
+		DeleteNode(node, true)
+	})
+
+	t.Run("PendingNodeHasNoPeers", func(t *testing.T) {
+		assert.True(t, node.IsPending)
+		allNodes, err := GetAllNodes()
+		assert.Nil(t, err)
+		update, err := GetPeerUpdateForHost("", &peer, allNodes, nil, nil)
+		assert.Nil(t, err)
+		for _, p := range update.Peers {
+			assert.Empty(t, p.AllowedIPs)
+		}
+		update, err = GetPeerUpdateForHost("", &pending, allNodes, nil, nil)
+		assert.Nil(t, err)
+		assert.Empty(t, update.Peers)
+	})
+	t.Run("NetworkRequiresApproval", func(t *testing.T) {
+		_, err := SetApprovalPolicy("approvalnet", models.ApprovalPolicy{RequiresApproval: true}, nil)
+		assert.Nil(t, err)
+		other := models.Host{ID: uuid.New(), Name: "approvalother", HostPass: "password", OS: "linux"}
+		assert.Nil(t, CreateHost(&other))
+		defer RemoveHost(&other, true)
+		otherNode, err := RegisterHostNetwork(&other, "approvalnet", false)
+		assert.Nil(t, err)
+		defer DeleteNode(otherNode, true)
+		assert.True(t, otherNode.IsPending)
+		revision, err := database.GetRevision(database.NETWORKS_TABLE_NAME, "approvalnet")
+		assert.Nil(t, err)
+		stale := revision - 1
+		_, err = SetApprovalPolicy("approvalnet", models.ApprovalPolicy{}, &stale)
+		assert.ErrorIs(t, err, database.ErrRevisionMismatch)
+		_, err = SetApprovalPolicy("approvalnet", models.ApprovalPolicy{}, nil)
+		assert.Nil(t, err)
+	})
+	t.Run("Approve", func(t *testing.T) {
+		assert.Nil(t, ApproveNode(node, "admin"))
+		saved, err := GetNodeByID(node.ID.String())
+		assert.Nil(t, err)
+		assert.False(t, saved.IsPending)
+		assert.NotNil(t, ApproveNode(&saved, "admin"))
+		approvals, err := GetNodeApprovals("approvalnet")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, approvals)
+		assert.Equal(t, models.NodeApproved, approvals[0].Decision)
+		assert.Equal(t, "admin", approvals[0].User)
+		assert.Equal(t, pending.Name, approvals[0].HostName)
+	})
+}
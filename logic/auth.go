@@ -390,7 +390,7 @@ func SetState(state string) error {
 		return err
 	}
 
-	return database.Insert(state, string(data), database.SSO_STATE_CACHE)
+	return database.InsertWithTTL(state, string(data), database.SSO_STATE_CACHE, models.DefaultExpDuration)
 }
 
 // IsStateValid - checks if given state is valid or not
@@ -0,0 +1,207 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+)
+
+// bulkJobRetention - how long finished and running bulk jobs are kept for their results to be read
+const bulkJobRetention = time.Hour * 24 * 7
+
+// FilterHosts - returns the hosts matching every field of a filter
+func FilterHosts(filter models.HostFilter) ([]models.Host, error) {
+	selector, err := models.ParseLabelSelector(filter.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Network != "" {
+		if _, err := GetNetwork(filter.Network); err != nil {
+			return nil, fmt.Errorf("network %s: %w", filter.Network, err)
+		}
+	}
+	hosts, err := GetAllHosts()
+	if err != nil {
+		return nil, err
+	}
+	filtered := []models.Host{}
+	for _, host := range SelectHosts(hosts, selector) {
+		if filter.OS != "" && host.OS != filter.OS {
+			continue
+		}
+		if filter.Version != "" && host.Version != filter.Version {
+			continue
+		}
+		if filter.Network != "" && !StringSliceContains(GetHostNetworks(host.ID.String()), filter.Network) {
+			continue
+		}
+		filtered = append(filtered, host)
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Name < filtered[j].Name
+	})
+	return filtered, nil
+}
+
+// CreateBulkJob - checks a bulk request and saves a pending job with a result for every host matching its filter,
+// returns the job along with the hosts to run it against
+func CreateBulkJob(request models.BulkHostRequest, user string) (*models.BulkJob, []models.Host, error) {
+	if err := validateBulkRequest(&request); err != nil {
+		return nil, nil, err
+	}
+	hosts, err := FilterHosts(request.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("no hosts match the filter")
+	}
+	job := &models.BulkJob{
+		ID:        uuid.New().String(),
+		Request:   request,
+		Status:    models.BulkPending,
+		Total:     len(hosts),
+		Results:   make([]models.BulkHostResult, 0, len(hosts)),
+		CreatedBy: user,
+		CreatedAt: time.Now(),
+	}
+	for _, host := range hosts {
+		job.Results = append(job.Results, models.BulkHostResult{
+			HostID:   host.ID.String(),
+			HostName: host.Name,
+			Status:   models.BulkPending,
+		})
+	}
+	if err := SaveBulkJob(job); err != nil {
+		return nil, nil, err
+	}
+	return job, hosts, nil
+}
+
+// validateBulkRequest - checks that a bulk request has everything its operation needs
+func validateBulkRequest(request *models.BulkHostRequest) error {
+	switch request.Operation {
+	case models.BulkAddToNetwork, models.BulkRemoveFromNetwork:
+		if request.Network == "" {
+			return fmt.Errorf("operation %s needs a network", request.Operation)
+		}
+		if _, err := GetNetwork(request.Network); err != nil {
+			return fmt.Errorf("network %s: %w", request.Network, err)
+		}
+	case models.BulkUpdateSettings:
+		if request.Verbosity == nil && request.MTU == nil && request.AutoUpdate == nil {
+			return errors.New("operation update needs a verbosity, mtu or autoupdate setting")
+		}
+		if request.Verbosity != nil && (*request.Verbosity < 0 || *request.Verbosity > 4) {
+			return errors.New("verbosity must be between 0 and 4")
+		}
+		if request.MTU != nil && *request.MTU <= 0 {
+			return errors.New("mtu must be positive")
+		}
+	case models.BulkRefreshKeys, models.BulkDelete:
+	default:
+		return fmt.Errorf("unknown bulk operation %q", request.Operation)
+	}
+	return nil
+}
+
+// SetBulkResult - records the outcome of a job on one of its hosts and saves the job
+func SetBulkResult(job *models.BulkJob, index int, err error) error {
+	result := &job.Results[index]
+	if err != nil {
+		result.Status = models.BulkFailed
+		result.Error = err.Error()
+		job.Failed++
+	} else {
+		result.Status = models.BulkSucceeded
+		job.Succeeded++
+	}
+	return SaveBulkJob(job)
+}
+
+// FinishBulkJob - marks a job as done, failed if any of its hosts failed
+func FinishBulkJob(job *models.BulkJob) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = models.BulkSucceeded
+	if job.Failed > 0 {
+		job.Status = models.BulkFailed
+	}
+	return SaveBulkJob(job)
+}
+
+// FailInterruptedBulkJobs - marks the jobs that were pending or running when the server stopped as failed, along with
+// the hosts they had not got to, returns how many jobs were marked
+func FailInterruptedBulkJobs() (int, error) {
+	jobs, err := GetBulkJobs()
+	if err != nil {
+		return 0, err
+	}
+	interrupted := 0
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Status != models.BulkPending && job.Status != models.BulkRunning {
+			continue
+		}
+		for j := range job.Results {
+			result := &job.Results[j]
+			if result.Status != models.BulkPending {
+				continue
+			}
+			result.Status = models.BulkFailed
+			result.Error = "interrupted by a server restart"
+			job.Failed++
+		}
+		if err := FinishBulkJob(job); err != nil {
+			return interrupted, err
+		}
+		interrupted++
+	}
+	return interrupted, nil
+}
+
+// SaveBulkJob - stores a bulk job, jobs expire a week after their last change
+func SaveBulkJob(job *models.BulkJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return database.InsertWithTTL(job.ID, string(data), database.BULK_JOBS_TABLE_NAME, bulkJobRetention)
+}
+
+// GetBulkJob - returns a bulk job by id
+func GetBulkJob(id string) (models.BulkJob, error) {
+	var job models.BulkJob
+	value, err := database.FetchRecord(database.BULK_JOBS_TABLE_NAME, id)
+	if err != nil {
+		return job, err
+	}
+	err = json.Unmarshal([]byte(value), &job)
+	return job, err
+}
+
+// GetBulkJobs - returns all bulk jobs, newest first
+func GetBulkJobs() ([]models.BulkJob, error) {
+	jobs := []models.BulkJob{}
+	records, err := database.FetchRecords(database.BULK_JOBS_TABLE_NAME)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return jobs, err
+	}
+	for _, value := range records {
+		var job models.BulkJob
+		if err := json.Unmarshal([]byte(value), &job); err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
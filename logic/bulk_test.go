@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkJobs(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("bulknet")
+	})
+	CreateNetwork(models.Network{NetID: "bulknet", AddressRange: "10.84.0.0/24"})
+	linux := models.Host{ID: uuid.New(), Name: "bulklinux", HostPass: "password", OS: "linux", Version: "v0.20.0",
+		Labels: map[string]string{"fleet": "bulk"}}
+	windows := models.Host{ID: uuid.New(), Name: "bulkwindows", HostPass: "password", OS: "windows", Version: "v0.19.0",
+		Labels: map[string]string{"fleet": "bulk"}}
+	assert.Nil(t, CreateHost(&linux))
+	assert.Nil(t, CreateHost(&windows))
+	t.Cleanup(func() {
+		RemoveHost(&linux, true)
+		RemoveHost(&windows, true)
+	})
+	node, err := UpdateHostNetwork(&linux, "bulknet", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(node, true)
+	})
+
+	t.Run("FilterHosts", func(t *testing.T) {
+		hosts, err := FilterHosts(models.HostFilter{Selector: "fleet=bulk"})
+		assert.Nil(t, err)
+		assert.Len(t, hosts, 2)
+		hosts, err = FilterHosts(models.HostFilter{Selector: "fleet=bulk", OS: "windows"})
+		assert.Nil(t, err)
+		assert.Len(t, hosts, 1)
+		assert.Equal(t, windows.ID, hosts[0].ID)
+		hosts, err = FilterHosts(models.HostFilter{Selector: "fleet=bulk", Network: "bulknet"})
+		assert.Nil(t, err)
+		assert.Len(t, hosts, 1)
+		assert.Equal(t, linux.ID, hosts[0].ID)
+		hosts, err = FilterHosts(models.HostFilter{Selector: "fleet=bulk", Version: "v0.21.0"})
+		assert.Nil(t, err)
+		assert.Empty(t, hosts)
+		_, err = FilterHosts(models.HostFilter{Network: "nosuchnet"})
+		assert.NotNil(t, err)
+	})
+	t.Run("InvalidRequests", func(t *testing.T) {
+		filter := models.HostFilter{Selector: "fleet=bulk"}
+		_, _, err := CreateBulkJob(models.BulkHostRequest{Filter: filter, Operation: models.BulkAddToNetwork}, "admin")
+		assert.NotNil(t, err)
+		_, _, err = CreateBulkJob(models.BulkHostRequest{Filter: filter, Operation: models.BulkUpdateSettings}, "admin")
+		assert.NotNil(t, err)
+		_, _, err = CreateBulkJob(models.BulkHostRequest{Filter: filter, Operation: "reboot"}, "admin")
+		assert.NotNil(t, err)
+		_, _, err = CreateBulkJob(models.BulkHostRequest{Filter: models.HostFilter{Selector: "fleet=none"},
+			Operation: models.BulkRefreshKeys}, "admin")
+		assert.NotNil(t, err)
+	})
+	t.Run("Results", func(t *testing.T) {
+		job, hosts, err := CreateBulkJob(models.BulkHostRequest{Filter: models.HostFilter{Selector: "fleet=bulk"},
+			Operation: models.BulkRefreshKeys}, "admin")
+		assert.Nil(t, err)
+		assert.Len(t, hosts, 2)
+		assert.Equal(t, models.BulkPending, job.Status)
+		assert.Nil(t, SetBulkResult(job, 0, nil))
+		assert.Nil(t, SetBulkResult(job, 1, errors.New("host is offline")))
+		assert.Nil(t, FinishBulkJob(job))
+		saved, err := GetBulkJob(job.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, models.BulkFailed, saved.Status)
+		assert.Equal(t, 1, saved.Succeeded)
+		assert.Equal(t, 1, saved.Failed)
+		assert.Equal(t, "host is offline", saved.Results[1].Error)
+		assert.NotNil(t, saved.FinishedAt)
+		jobs, err := GetBulkJobs()
+		assert.Nil(t, err)
+		assert.NotEmpty(t, jobs)
+	})
+	t.Run("Interrupted", func(t *testing.T) {
+		job, _, err := CreateBulkJob(models.BulkHostRequest{Filter: models.HostFilter{Selector: "fleet=bulk"},
+			Operation: models.BulkRefreshKeys}, "admin")
+		assert.Nil(t, err)
+		job.Status = models.BulkRunning
+		assert.Nil(t, SetBulkResult(job, 0, nil))
+		interrupted, err := FailInterruptedBulkJobs()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, interrupted)
+		saved, err := GetBulkJob(job.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, models.BulkFailed, saved.Status)
+		assert.Equal(t, models.BulkSucceeded, saved.Results[0].Status)
+		assert.Equal(t, models.BulkFailed, saved.Results[1].Status)
+		assert.NotEmpty(t, saved.Results[1].Error)
+		assert.NotNil(t, saved.FinishedAt)
+		interrupted, err = FailInterruptedBulkJobs()
+		assert.Nil(t, err)
+		assert.Zero(t, interrupted)
+	})
+}
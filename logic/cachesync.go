@@ -0,0 +1,94 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic/acls"
+	"github.com/gravitl/netmaker/models"
+)
+
+// StartCacheSync - keeps the in memory caches in line with writes made by other server instances
+func StartCacheSync(ctx context.Context) {
+	database.OnChange(syncCache)
+	go func() {
+		if err := database.WatchChanges(ctx); err != nil {
+			logger.Log(0, "stopped watching database changes, caches may serve stale data:", err.Error())
+		}
+	}()
+}
+
+// syncCache - refreshes the cached copy of a changed record, or drops the whole cache if the key is unknown
+func syncCache(change database.Change) {
+	logger.Log(3, "database change from another instance:", change.Table, change.Key)
+	switch change.Table {
+	case database.NODES_TABLE_NAME:
+		// a changed or deleted address can not be told from the change, so the pools are reloaded on next use
+		clearAddressPools()
+		if change.Key == "" {
+			ClearNodeCache()
+			return
+		}
+		var node models.Node
+		if refreshRecord(change, &node) {
+			if nodeCacheLoaded() {
+				storeNodeInCache(node)
+			}
+		} else {
+			deleteNodeFromCache(change.Key)
+		}
+	case database.HOSTS_TABLE_NAME:
+		if change.Key == "" {
+			clearHostCache()
+			return
+		}
+		var host models.Host
+		if refreshRecord(change, &host) {
+			if hostCacheLoaded() {
+				storeHostInCache(host)
+			}
+		} else {
+			deleteHostFromCache(change.Key)
+		}
+	case database.EXT_CLIENT_TABLE_NAME:
+		clearAddressPools()
+		if change.Key == "" {
+			clearExtClientCache()
+			return
+		}
+		var extClient models.ExtClient
+		if refreshRecord(change, &extClient) {
+			if extClientCacheLoaded() {
+				storeExtClientInCache(change.Key, extClient)
+			}
+		} else {
+			deleteExtClientFromCache(change.Key)
+		}
+	case database.NODE_ACLS_TABLE_NAME:
+		if change.Key == "" {
+			acls.ClearAclCache()
+			return
+		}
+		acls.DeleteAclFromCache(acls.ContainerID(change.Key))
+	case database.NETWORK_PEERINGS_TABLE_NAME:
+		clearPeeringCache()
+	}
+}
+
+// refreshRecord - reads the latest version of a changed record, false if it no longer exists or can not be read
+func refreshRecord(change database.Change, v any) bool {
+	record, err := database.FetchRecord(change.Table, change.Key)
+	if err != nil {
+		if !database.IsEmptyRecord(err) {
+			logger.Log(0, "failed to refresh", change.Table, change.Key, err.Error())
+		}
+		return false
+	}
+	if err = json.Unmarshal([]byte(record), v); err != nil {
+		logger.Log(0, "failed to refresh", change.Table, change.Key, err.Error())
+		return false
+	}
+	return true
+}
@@ -28,7 +28,12 @@ func SetDNS() error {
 			return err
 		}
 		for _, entry := range dns {
-			hostfile.AddHost(entry.Address, entry.Name+"."+entry.Network)
+			if entry.Address != "" {
+				hostfile.AddHost(entry.Address, entry.Name+"."+entry.Network)
+			}
+			if entry.Address6 != "" {
+				hostfile.AddHost(entry.Address6, entry.Name+"."+entry.Network)
+			}
 		}
 	}
 	if corefilestring == "" {
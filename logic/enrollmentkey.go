@@ -29,35 +29,38 @@ var EnrollmentErrors = struct {
 	FailedToDeTokenize: fmt.Errorf("failed to detokenize"),
 }
 
-// CreateEnrollmentKey - creates a new enrollment key in db
-func CreateEnrollmentKey(uses int, expiration time.Time, networks, tags []string, unlimited bool) (k *models.EnrollmentKey, err error) {
+// CreateEnrollmentKey - creates a new enrollment key in db from the requested uses, unix expiration, networks, tags and
+// options of the key
+func CreateEnrollmentKey(request models.APIEnrollmentKey) (k *models.EnrollmentKey, err error) {
 	newKeyID, err := getUniqueEnrollmentID()
 	if err != nil {
 		return nil, err
 	}
 	k = &models.EnrollmentKey{
-		Value:         newKeyID,
-		Expiration:    time.Time{},
-		UsesRemaining: 0,
-		Unlimited:     unlimited,
-		Networks:      []string{},
-		Tags:          []string{},
-		Type:          models.Undefined,
-	}
-	if uses > 0 {
-		k.UsesRemaining = uses
+		Value:            newKeyID,
+		Expiration:       time.Time{},
+		UsesRemaining:    0,
+		Unlimited:        request.Unlimited,
+		Networks:         []string{},
+		Tags:             []string{},
+		Type:             models.Undefined,
+		RequiresApproval: request.RequiresApproval,
+		Ephemeral:        request.Ephemeral,
+	}
+	if request.UsesRemaining > 0 {
+		k.UsesRemaining = request.UsesRemaining
 		k.Type = models.Uses
-	} else if !expiration.IsZero() {
-		k.Expiration = expiration
+	} else if request.Expiration > 0 {
+		k.Expiration = time.Unix(request.Expiration, 0)
 		k.Type = models.TimeExpiration
 	} else if k.Unlimited {
 		k.Type = models.Unlimited
 	}
-	if len(networks) > 0 {
-		k.Networks = networks
+	if len(request.Networks) > 0 {
+		k.Networks = request.Networks
 	}
-	if len(tags) > 0 {
-		k.Tags = tags
+	if len(request.Tags) > 0 {
+		k.Tags = request.Tags
 	}
 	if ok := k.Validate(); !ok {
 		return nil, EnrollmentErrors.InvalidCreate
@@ -187,6 +190,14 @@ func upsertEnrollmentKey(k *models.EnrollmentKey) error {
 	if err != nil {
 		return err
 	}
+	if k.Type == models.TimeExpiration && k.UsesRemaining == 0 && !k.Unlimited {
+		// the key can never become valid again once it has expired, so let the database remove it
+		ttl := time.Until(k.Expiration)
+		if ttl < time.Second {
+			ttl = time.Second
+		}
+		return database.InsertWithTTL(k.Value, string(data), database.ENROLLMENT_KEYS_TABLE_NAME, ttl)
+	}
 	return database.Insert(k.Value, string(data), database.ENROLLMENT_KEYS_TABLE_NAME)
 }
 
@@ -23,6 +23,10 @@ func FormatError(err error, errType string) models.ErrorResponse {
 		status = http.StatusUnauthorized
 	case "forbidden":
 		status = http.StatusForbidden
+	case "preconditionfailed":
+		status = http.StatusPreconditionFailed
+	case "conflict":
+		status = http.StatusConflict
 	default:
 		status = http.StatusInternalServerError
 	}
@@ -3,11 +3,13 @@ package logic
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"reflect"
 	"sync"
 	"time"
 
 	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
 	"github.com/gravitl/netmaker/models"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
@@ -45,6 +47,19 @@ func storeExtClientInCache(key string, extclient models.ExtClient) {
 	extClientCacheMutex.Unlock()
 }
 
+// extClientCacheLoaded - a non empty cache is served as the full list of ext clients
+func extClientCacheLoaded() bool {
+	extClientCacheMutex.RLock()
+	defer extClientCacheMutex.RUnlock()
+	return len(extClientCacheMap) > 0
+}
+
+func clearExtClientCache() {
+	extClientCacheMutex.Lock()
+	extClientCacheMap = make(map[string]models.ExtClient)
+	extClientCacheMutex.Unlock()
+}
+
 // ExtClient.GetEgressRangesOnNetwork - returns the egress ranges on network of ext client
 func GetEgressRangesOnNetwork(client *models.ExtClient) ([]string, error) {
 
@@ -69,18 +84,41 @@ func GetEgressRangesOnNetwork(client *models.ExtClient) ([]string, error) {
 
 // DeleteExtClient - deletes an existing ext client
 func DeleteExtClient(network string, clientid string) error {
-	key, err := GetRecordKey(clientid, network)
-	if err != nil {
+	tx := database.BeginTx()
+	if err := deleteExtClientTx(tx, network, clientid); err != nil {
 		return err
 	}
-	err = database.DeleteRecord(database.EXT_CLIENT_TABLE_NAME, key)
+	return tx.Commit()
+}
+
+// deleteExtClientTx - queues the deletion of an ext client in given db transaction, its addresses are released on commit
+func deleteExtClientTx(tx *database.Tx, network string, clientid string) error {
+	key, err := GetRecordKey(clientid, network)
 	if err != nil {
 		return err
 	}
-	deleteExtClientFromCache(key)
+	client, getErr := GetExtClient(clientid, network)
+	removeExtClientRecordTx(tx, key)
+	tx.OnCommit(func() {
+		if getErr != nil {
+			return
+		}
+		if err := ReleaseAddresses(network, net.ParseIP(client.Address), net.ParseIP(client.Address6)); err != nil {
+			logger.Log(1, "failed to release addresses of ext client", clientid, err.Error())
+		}
+	})
 	return nil
 }
 
+// removeExtClientRecordTx - queues the deletion of the record of an ext client in given db transaction without
+// releasing its addresses
+func removeExtClientRecordTx(tx *database.Tx, key string) {
+	tx.Delete(database.EXT_CLIENT_TABLE_NAME, key)
+	tx.OnCommit(func() {
+		deleteExtClientFromCache(key)
+	})
+}
+
 // GetNetworkExtClients - gets the ext clients of given network
 func GetNetworkExtClients(network string) ([]models.ExtClient, error) {
 	var extclients []models.ExtClient
@@ -158,6 +196,28 @@ func CreateExtClient(extclient *models.ExtClient) error {
 	addressLock.Lock()
 	defer addressLock.Unlock()
 
+	allocate4, allocate6 := extclient.Address == "", extclient.Address6 == ""
+	tx := database.BeginTx()
+	err := createExtClientTx(tx, extclient)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		// the addresses handed out for the client go back to the pool as it was never stored
+		if allocate4 {
+			freeAddresses(extclient.Network, net.ParseIP(extclient.Address))
+		}
+		if allocate6 {
+			freeAddresses(extclient.Network, net.ParseIP(extclient.Address6))
+		}
+		return err
+	}
+	return SetNetworkNodesLastModified(extclient.Network)
+}
+
+// createExtClientTx - fills in the keys and addresses of an ext client and queues it in given db transaction
+// callers must hold addressLock until the transaction is committed
+func createExtClientTx(tx *database.Tx, extclient *models.ExtClient) error {
 	if len(extclient.PublicKey) == 0 {
 		privateKey, err := wgtypes.GeneratePrivateKey()
 		if err != nil {
@@ -198,11 +258,23 @@ func CreateExtClient(extclient *models.ExtClient) error {
 	}
 
 	extclient.LastModified = time.Now().Unix()
-	return SaveExtClient(extclient)
+	return saveExtClientTx(tx, extclient)
 }
 
 // SaveExtClient - saves an ext client to database
 func SaveExtClient(extclient *models.ExtClient) error {
+	tx := database.BeginTx()
+	if err := saveExtClientTx(tx, extclient); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return SetNetworkNodesLastModified(extclient.Network)
+}
+
+// saveExtClientTx - queues the upsert of an ext client in given db transaction, the cache is updated on commit
+func saveExtClientTx(tx *database.Tx, extclient *models.ExtClient) error {
 	key, err := GetRecordKey(extclient.ClientID, extclient.Network)
 	if err != nil {
 		return err
@@ -211,20 +283,37 @@ func SaveExtClient(extclient *models.ExtClient) error {
 	if err != nil {
 		return err
 	}
-	if err = database.Insert(key, string(data), database.EXT_CLIENT_TABLE_NAME); err != nil {
+	if err = tx.Insert(key, string(data), database.EXT_CLIENT_TABLE_NAME); err != nil {
 		return err
 	}
-	storeExtClientInCache(key, *extclient)
-	return SetNetworkNodesLastModified(extclient.Network)
+	client := *extclient
+	tx.OnCommit(func() {
+		storeExtClientInCache(key, client)
+		markAddressesUsed(client.Network, net.ParseIP(client.Address), net.ParseIP(client.Address6))
+	})
+	return nil
 }
 
 // UpdateExtClient - updates an ext client with new values
 func UpdateExtClient(old *models.ExtClient, update *models.CustomExtClient) (*models.ExtClient, error) {
+	return UpdateExtClientAtRevision(old, update, nil)
+}
+
+// UpdateExtClientAtRevision - updates an ext client only if its record is still at the given revision, a nil revision
+// always updates
+func UpdateExtClientAtRevision(old *models.ExtClient, update *models.CustomExtClient, revision *uint64) (*models.ExtClient, error) {
+	addressLock.Lock()
+	defer addressLock.Unlock()
 	new := old
-	err := DeleteExtClient(old.Network, old.ClientID)
+	// the old record is replaced in a single transaction so a failure never loses the client
+	tx := database.BeginTx()
+	key, err := GetRecordKey(old.ClientID, old.Network)
 	if err != nil {
 		return new, err
 	}
+	expectRevision(tx, database.EXT_CLIENT_TABLE_NAME, key, revision)
+	oldAddresses := []string{old.Address, old.Address6}
+	removeExtClientRecordTx(tx, key)
 	new.ClientID = update.ClientID
 	if update.PublicKey != "" && old.PublicKey != update.PublicKey {
 		new.PublicKey = update.PublicKey
@@ -241,7 +330,25 @@ func UpdateExtClient(old *models.ExtClient, update *models.CustomExtClient) (*mo
 	if update.DeniedACLs != nil && !reflect.DeepEqual(old.DeniedACLs, update.DeniedACLs) {
 		new.DeniedACLs = update.DeniedACLs
 	}
-	return new, CreateExtClient(new)
+	if err = createExtClientTx(tx, new); err != nil {
+		return new, err
+	}
+	// only the addresses the client no longer uses are released, an unchanged client keeps them without a cooldown
+	released := []net.IP{}
+	for _, address := range oldAddresses {
+		if address != "" && address != new.Address && address != new.Address6 {
+			released = append(released, net.ParseIP(address))
+		}
+	}
+	if len(released) > 0 {
+		if err = releaseAddressesTx(tx, new.Network, released...); err != nil {
+			return new, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return new, err
+	}
+	return new, SetNetworkNodesLastModified(new.Network)
 }
 
 // GetExtClientsByID - gets the clients of attached gateway
@@ -28,8 +28,8 @@ func CreateEgressGateway(gateway models.EgressGatewayRequest) (models.Node, erro
 		return models.Node{}, errors.New("firewall is not supported for egress gateways")
 	}
 	for i := len(gateway.Ranges) - 1; i >= 0; i-- {
-		if gateway.Ranges[i] == "::/0" {
-			logger.Log(0, "currently IPv6 internet gateways are not supported", gateway.Ranges[i])
+		if gateway.Ranges[i] == "::/0" && node.Address.IP != nil {
+			logger.Log(0, "currently IPv6 internet gateways are only supported on IPv6 only networks", gateway.Ranges[i])
 			gateway.Ranges = append(gateway.Ranges[:i], gateway.Ranges[i+1:]...)
 			continue
 		}
@@ -140,8 +140,9 @@ func DeleteIngressGateway(nodeid string) (models.Node, bool, []models.ExtClient,
 
 	removedClients = clients
 
-	// delete ext clients belonging to ingress gateway
-	if err = DeleteGatewayExtClients(node.ID.String(), node.Network); err != nil {
+	// delete ext clients belonging to ingress gateway together with the gateway update
+	tx := database.BeginTx()
+	if err = deleteGatewayExtClientsTx(tx, node.ID.String(), node.Network); err != nil {
 		return models.Node{}, false, removedClients, err
 	}
 	logger.Log(3, "deleting ingress gateway")
@@ -159,8 +160,10 @@ func DeleteIngressGateway(nodeid string) (models.Node, bool, []models.ExtClient,
 				node.EgressGatewayRequest.NodeID, node.EgressGatewayRequest.NetID, err))
 		}
 	}
-	err = UpsertNode(&node)
-	if err != nil {
+	if err = upsertNodeTx(tx, &node); err != nil {
+		return models.Node{}, wasFailover, removedClients, err
+	}
+	if err = tx.Commit(); err != nil {
 		return models.Node{}, wasFailover, removedClients, err
 	}
 	err = SetNetworkNodesLastModified(node.Network)
@@ -169,6 +172,15 @@ func DeleteIngressGateway(nodeid string) (models.Node, bool, []models.ExtClient,
 
 // DeleteGatewayExtClients - deletes ext clients based on gateway (mac) of ingress node and network
 func DeleteGatewayExtClients(gatewayID string, networkName string) error {
+	tx := database.BeginTx()
+	if err := deleteGatewayExtClientsTx(tx, gatewayID, networkName); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteGatewayExtClientsTx - queues the deletion of the ext clients of a gateway in given db transaction
+func deleteGatewayExtClientsTx(tx *database.Tx, gatewayID string, networkName string) error {
 	currentExtClients, err := GetNetworkExtClients(networkName)
 	if database.IsEmptyRecord(err) {
 		return nil
@@ -178,7 +190,7 @@ func DeleteGatewayExtClients(gatewayID string, networkName string) error {
 	}
 	for _, extClient := range currentExtClients {
 		if extClient.IngressGatewayID == gatewayID {
-			if err = DeleteExtClient(networkName, extClient.ClientID); err != nil {
+			if err = deleteExtClientTx(tx, networkName, extClient.ClientID); err != nil {
 				logger.Log(1, "failed to remove ext client", extClient.ClientID)
 				continue
 			}
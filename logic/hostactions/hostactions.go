@@ -2,11 +2,15 @@ package hostactions
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/gravitl/netmaker/database"
 	"github.com/gravitl/netmaker/models"
 )
 
+// actionTTL - how long a pending action waits for its host to check in before it is dropped
+const actionTTL = time.Hour * 24
+
 // AddAction - adds a host action to a host's list to be retrieved from broker update
 func AddAction(hu models.HostUpdate) {
 	hostID := hu.Host.ID.String()
@@ -17,7 +21,7 @@ func AddAction(hu models.HostUpdate) {
 			if err != nil {
 				return
 			}
-			_ = database.Insert(hostID, string(newEntry), database.HOST_ACTIONS_TABLE_NAME)
+			_ = database.InsertWithTTL(hostID, string(newEntry), database.HOST_ACTIONS_TABLE_NAME, actionTTL)
 		}
 		return
 	}
@@ -30,7 +34,7 @@ func AddAction(hu models.HostUpdate) {
 	if err != nil {
 		return
 	}
-	_ = database.Insert(hostID, string(newData), database.HOST_ACTIONS_TABLE_NAME)
+	_ = database.InsertWithTTL(hostID, string(newData), database.HOST_ACTIONS_TABLE_NAME, actionTTL)
 }
 
 // GetAction - gets an action if exists
@@ -45,11 +49,16 @@ func GetAction(id string) *models.HostUpdate {
 	}
 	if len(currentList) > 0 {
 		hu := currentList[0]
+		if len(currentList) == 1 {
+			_ = database.DeleteRecord(database.HOST_ACTIONS_TABLE_NAME, id)
+			return &hu
+		}
 		newData, err := json.Marshal(currentList[1:])
 		if err != nil {
-			newData, _ = json.Marshal([]models.HostUpdate{})
+			_ = database.DeleteRecord(database.HOST_ACTIONS_TABLE_NAME, id)
+			return &hu
 		}
-		_ = database.Insert(id, string(newData), database.HOST_ACTIONS_TABLE_NAME)
+		_ = database.InsertWithTTL(id, string(newData), database.HOST_ACTIONS_TABLE_NAME, actionTTL)
 		return &hu
 	}
 	return nil
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/devilcove/httpclient"
 	"github.com/google/uuid"
@@ -66,12 +67,25 @@ func deleteHostFromCache(hostID string) {
 	delete(hostsCacheMap, hostID)
 	hostCacheMutex.Unlock()
 }
+// hostCacheLoaded - a non empty cache is served as the full list of hosts
+func hostCacheLoaded() bool {
+	hostCacheMutex.RLock()
+	defer hostCacheMutex.RUnlock()
+	return len(hostsCacheMap) > 0
+}
+
 func loadHostsIntoCache(hMap map[string]models.Host) {
 	hostCacheMutex.Lock()
 	hostsCacheMap = hMap
 	hostCacheMutex.Unlock()
 }
 
+func clearHostCache() {
+	hostCacheMutex.Lock()
+	hostsCacheMap = make(map[string]models.Host)
+	hostCacheMutex.Unlock()
+}
+
 const (
 	maxPort = 1<<16 - 1
 	minPort = 1025
@@ -183,6 +197,9 @@ func CreateHost(h *models.Host) error {
 	}
 	h.HostPass = string(hash)
 	h.AutoUpdate = servercfg.AutoUpdateEnabled()
+	if h.KeysUpdatedAt.IsZero() {
+		h.KeysUpdatedAt = time.Now()
+	}
 	checkForZombieHosts(h)
 	return UpsertHost(h)
 }
@@ -200,6 +217,9 @@ func UpdateHost(newHost, currentHost *models.Host) {
 	newHost.PublicKey = currentHost.PublicKey
 	newHost.InternetGateway = currentHost.InternetGateway
 	newHost.TrafficKeyPublic = currentHost.TrafficKeyPublic
+	newHost.KeysUpdatedAt = currentHost.KeysUpdatedAt
+	newHost.KeyRotationRequestedAt = currentHost.KeyRotationRequestedAt
+	newHost.Ephemeral = currentHost.Ephemeral
 
 	// changeable fields
 	if len(newHost.Version) == 0 {
@@ -225,6 +245,8 @@ func UpdateHostFromClient(newHost, currHost *models.Host) (sendPeerUpdate bool)
 
 	if newHost.PublicKey != currHost.PublicKey {
 		currHost.PublicKey = newHost.PublicKey
+		currHost.KeysUpdatedAt = time.Now()
+		currHost.KeyRotationRequestedAt = time.Time{}
 		sendPeerUpdate = true
 	}
 	if newHost.ListenPort != 0 && currHost.ListenPort != newHost.ListenPort {
@@ -268,6 +290,32 @@ func UpsertHost(h *models.Host) error {
 	return nil
 }
 
+// UpsertHostAtRevision - upserts a host only if its record is still at the given revision, a nil revision always upserts
+func UpsertHostAtRevision(h *models.Host, revision *uint64) error {
+	tx := database.BeginTx()
+	expectRevision(tx, database.HOSTS_TABLE_NAME, h.ID.String(), revision)
+	if err := upsertHostTx(tx, h); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// upsertHostTx - queues the upsert of a given host in a db transaction, the cache is updated on commit
+func upsertHostTx(tx *database.Tx, h *models.Host) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err = tx.Insert(h.ID.String(), string(data), database.HOSTS_TABLE_NAME); err != nil {
+		return err
+	}
+	host := *h
+	tx.OnCommit(func() {
+		storeHostInCache(host)
+	})
+	return nil
+}
+
 // RemoveHost - removes a given host from server
 func RemoveHost(h *models.Host, forceDelete bool) error {
 	if !forceDelete && len(h.Nodes) > 0 {
@@ -307,8 +355,26 @@ func RemoveHostByID(hostID string) error {
 	return nil
 }
 
+// removeHostTx - queues the deletion of a host in given db transaction, its cache entry and turn registration are
+// removed once the transaction is committed
+func removeHostTx(tx *database.Tx, h *models.Host) {
+	hostID := h.ID.String()
+	tx.Delete(database.HOSTS_TABLE_NAME, hostID)
+	tx.OnCommit(func() {
+		if servercfg.IsUsingTurn() {
+			DeRegisterHostWithTurn(hostID)
+		}
+		deleteHostFromCache(hostID)
+	})
+}
+
 // UpdateHostNetwork - adds/deletes host from a network
 func UpdateHostNetwork(h *models.Host, network string, add bool) (*models.Node, error) {
+	return updateHostNetwork(h, network, add, false)
+}
+
+// updateHostNetwork - adds or removes a host from a network, a node that is added may start out pending approval
+func updateHostNetwork(h *models.Host, network string, add, pendingApproval bool) (*models.Node, error) {
 	for _, nodeID := range h.Nodes {
 		node, err := GetNodeByID(nodeID)
 		if err != nil || node.PendingDelete {
@@ -329,6 +395,13 @@ func UpdateHostNetwork(h *models.Host, network string, add bool) (*models.Node,
 		newNode.Server = servercfg.GetServer()
 		newNode.Network = network
 		newNode.HostID = h.ID
+		newNode.IsPending = pendingApproval
+		if net, err := GetNetwork(network); err == nil {
+			if violations := CheckPosture(h, net.PosturePolicy); len(violations) > 0 {
+				newNode.Quarantined = true
+				newNode.PostureViolations = violations
+			}
+		}
 		if err := AssociateNodeToHost(&newNode, h); err != nil {
 			return nil, err
 		}
@@ -343,7 +416,27 @@ func AssociateNodeToHost(n *models.Node, h *models.Host) error {
 		return ErrInvalidHostID
 	}
 	n.HostID = h.ID
-	err := createNode(n)
+	// lock because we need unique IPs and the new node is only visible to other joins once committed
+	addressLock.Lock()
+	defer addressLock.Unlock()
+	allocate4, allocate6 := n.Address.IP == nil, n.Address6.IP == nil
+	if err := storeNewNode(n, h); err != nil {
+		// the addresses handed out for the node go back to the pool as it was never stored
+		if allocate4 {
+			freeAddresses(n.Network, n.Address.IP)
+		}
+		if allocate6 {
+			freeAddresses(n.Network, n.Address6.IP)
+		}
+		return err
+	}
+	return nodeCreated(n)
+}
+
+// storeNewNode - creates a node and adds it to its host in one db transaction, caller must hold addressLock
+func storeNewNode(n *models.Node, h *models.Host) error {
+	tx := database.BeginTx()
+	err := createNode(tx, n)
 	if err != nil {
 		return err
 	}
@@ -353,7 +446,13 @@ func AssociateNodeToHost(n *models.Node, h *models.Host) error {
 	}
 	h.HostPass = currentHost.HostPass
 	h.Nodes = append(currentHost.Nodes, n.ID.String())
-	return UpsertHost(h)
+	if h.Labels == nil {
+		h.Labels = currentHost.Labels
+	}
+	if err = upsertHostTx(tx, h); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // DissasociateNodeFromHost - deletes a node and removes from host nodes
@@ -391,11 +490,14 @@ func DissasociateNodeFromHost(n *models.Node, h *models.Host) error {
 			}
 		}
 	}()
-	if err := deleteNodeByID(n); err != nil {
+	tx := database.BeginTx()
+	if err := deleteNodeByID(tx, n); err != nil {
 		return err
 	}
-
-	return UpsertHost(h)
+	if err := upsertHostTx(tx, h); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // DisassociateAllNodesFromHost - deletes all nodes of the host
@@ -0,0 +1,715 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
+)
+
+// ErrNoAddresses - every address of the network is in use, reserved or cooling down
+var ErrNoAddresses = errors.New("ERROR: No unique addresses available. Check network subnet")
+
+// ErrReservationConflict - the address, or the host, already has a reservation or the address is in use by someone else
+var ErrReservationConflict = errors.New("reservation conflict")
+
+// ErrReservationNotFound - the address is not reserved on the network
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// span - an inclusive range of address offsets from the network address
+type span struct {
+	lo, hi uint64
+}
+
+// spanSet - sorted spans that neither overlap nor touch
+type spanSet []span
+
+// spanSet.find - returns the index of the span containing x, or where it would be inserted
+func (s spanSet) find(x uint64) (int, bool) {
+	i := sort.Search(len(s), func(i int) bool { return s[i].hi >= x })
+	return i, i < len(s) && s[i].lo <= x
+}
+
+// spanSet.add - adds the offsets lo to hi, hi must be below math.MaxUint64
+func (s spanSet) add(lo, hi uint64) spanSet {
+	i := sort.Search(len(s), func(i int) bool { return s[i].hi+1 >= lo })
+	j := i
+	for ; j < len(s) && s[j].lo <= hi+1; j++ {
+		if s[j].lo < lo {
+			lo = s[j].lo
+		}
+		if s[j].hi > hi {
+			hi = s[j].hi
+		}
+	}
+	result := append(spanSet{}, s[:i]...)
+	result = append(result, span{lo: lo, hi: hi})
+	return append(result, s[j:]...)
+}
+
+// spanSet.remove - removes a single offset
+func (s spanSet) remove(x uint64) spanSet {
+	i, ok := s.find(x)
+	if !ok {
+		return s
+	}
+	current := s[i]
+	result := append(spanSet{}, s[:i]...)
+	if current.lo < x {
+		result = append(result, span{lo: current.lo, hi: x - 1})
+	}
+	if x < current.hi {
+		result = append(result, span{lo: x + 1, hi: current.hi})
+	}
+	return append(result, s[i+1:]...)
+}
+
+// addressPool - the in use addresses of one ip family of a network, kept as spans of offsets so finding a free
+// address does not depend on the number of nodes and ext clients
+type addressPool struct {
+	base        *big.Int
+	length      int
+	first, last uint64
+	used        spanSet
+}
+
+var (
+	addressPoolMutex sync.Mutex
+	// addressPools - pools by network and range, loaded from the nodes and ext clients of a network on first use
+	addressPools = map[string]*addressPool{}
+)
+
+func addressPoolKey(network string, cidr string) string {
+	return network + "/" + cidr
+}
+
+// networkAddressPools - returns the loaded pools of a network, caller must hold addressPoolMutex
+func networkAddressPools(network string) []*addressPool {
+	pools := []*addressPool{}
+	for key, pool := range addressPools {
+		if strings.HasPrefix(key, network+"/") {
+			pools = append(pools, pool)
+		}
+	}
+	return pools
+}
+
+// familyRanges - returns the primary range of an ip family of a network followed by its secondary ranges
+func familyRanges(network *models.Network, isIpv6 bool) []string {
+	ranges := []string{}
+	for _, addressRange := range network.AllAddressRanges() {
+		if ip, _, err := net.ParseCIDR(addressRange); err == nil && (ip.To4() == nil) == isIpv6 {
+			ranges = append(ranges, addressRange)
+		}
+	}
+	return ranges
+}
+
+// networkRangeOf - returns the primary or secondary range of a network that contains an address
+func networkRangeOf(network *models.Network, address net.IP) (*net.IPNet, bool) {
+	if address == nil {
+		return nil, false
+	}
+	for _, addressRange := range network.AllAddressRanges() {
+		if _, cidr, err := net.ParseCIDR(addressRange); err == nil && cidr.Contains(address) {
+			return cidr, true
+		}
+	}
+	return nil, false
+}
+
+// newAddressPool - creates an empty pool for a cidr, the network address and the last address are never handed out
+func newAddressPool(cidr string) (*addressPool, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	pool := addressPool{base: new(big.Int).SetBytes(ipNet.IP), length: len(ipNet.IP)}
+	ones, bits := ipNet.Mask.Size()
+	hostBits := bits - ones
+	if hostBits < 2 {
+		// point to point and single address networks use every address
+		pool.last = uint64(1)<<hostBits - 1
+		return &pool, nil
+	}
+	pool.first = 1
+	pool.last = math.MaxUint64 - 1
+	if hostBits < 64 {
+		pool.last = uint64(1)<<hostBits - 2
+	}
+	return &pool, nil
+}
+
+// addressPool.offset - returns the offset of an address, false if it is outside of the pool
+func (p *addressPool) offset(ip net.IP) (uint64, bool) {
+	if p.length == net.IPv4len {
+		ip = ip.To4()
+	} else {
+		ip = ip.To16()
+	}
+	if ip == nil {
+		return 0, false
+	}
+	offset := new(big.Int).Sub(new(big.Int).SetBytes(ip), p.base)
+	if offset.Sign() < 0 || !offset.IsUint64() || offset.Uint64() > p.last {
+		return 0, false
+	}
+	return offset.Uint64(), true
+}
+
+// addressPool.address - returns the address at an offset
+func (p *addressPool) address(offset uint64) net.IP {
+	ip := new(big.Int).Add(p.base, new(big.Int).SetUint64(offset)).Bytes()
+	address := make(net.IP, p.length)
+	copy(address[p.length-len(ip):], ip)
+	return address
+}
+
+// addressPool.markUsed - marks an address as in use
+func (p *addressPool) markUsed(ip net.IP) {
+	if offset, ok := p.offset(ip); ok {
+		p.used = p.used.add(offset, offset)
+	}
+}
+
+// addressPool.next - finds the first free address, the last one when reverse, skipping reserved and cooling offsets
+func (p *addressPool) next(reserved spanSet, cooling map[uint64]bool, reverse bool) (uint64, bool) {
+	if p.first > p.last {
+		return 0, false
+	}
+	x := p.first
+	if reverse {
+		x = p.last
+	}
+	for {
+		taken := false
+		for _, set := range []spanSet{p.used, reserved} {
+			if i, ok := set.find(x); ok {
+				taken = true
+				if reverse {
+					if set[i].lo <= p.first {
+						return 0, false
+					}
+					x = set[i].lo - 1
+				} else {
+					if set[i].hi >= p.last {
+						return 0, false
+					}
+					x = set[i].hi + 1
+				}
+			}
+		}
+		if taken {
+			continue
+		}
+		if !cooling[x] {
+			return x, true
+		}
+		if reverse {
+			if x == p.first {
+				return 0, false
+			}
+			x--
+		} else {
+			if x == p.last {
+				return 0, false
+			}
+			x++
+		}
+	}
+}
+
+// ipamRecord - the persisted allocator state of a network
+type ipamRecord struct {
+	Reserved []models.AddressRange `json:"reserved"`
+	// Reservations - addresses that are only handed out to the host they are bound to
+	Reservations []models.AddressReservation `json:"reservations"`
+	// Released - recently released addresses and when they were released, they are not handed out during the cooldown
+	Released map[string]int64 `json:"released"`
+}
+
+func fetchIPAMRecord(network string) (ipamRecord, error) {
+	record := ipamRecord{
+		Reserved:     []models.AddressRange{},
+		Reservations: []models.AddressReservation{},
+		Released:     map[string]int64{},
+	}
+	data, err := database.FetchRecord(database.IPAM_TABLE_NAME, network)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return record, nil
+		}
+		return record, err
+	}
+	if err = json.Unmarshal([]byte(data), &record); err != nil {
+		return record, err
+	}
+	if record.Reservations == nil {
+		record.Reservations = []models.AddressReservation{}
+	}
+	if record.Released == nil {
+		record.Released = map[string]int64{}
+	}
+	return record, nil
+}
+
+// saveIPAMRecord - stores the allocator state of a network, dropping released addresses whose cooldown has passed
+func saveIPAMRecord(network string, record ipamRecord) error {
+	tx := database.BeginTx()
+	if err := saveIPAMRecordTx(tx, network, record); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveIPAMRecordTx - queues the allocator state of a network in given db transaction, see saveIPAMRecord
+func saveIPAMRecordTx(tx *database.Tx, network string, record ipamRecord) error {
+	cutoff := time.Now().Add(-servercfg.GetAddressCooldown()).Unix()
+	for address, released := range record.Released {
+		if released <= cutoff {
+			delete(record.Released, address)
+		}
+	}
+	data, err := json.Marshal(&record)
+	if err != nil {
+		return err
+	}
+	return tx.Insert(network, string(data), database.IPAM_TABLE_NAME)
+}
+
+// getAddressPool - returns the pool of a range of a network, loading it from the network's nodes and ext clients if
+// needed, caller must hold addressPoolMutex
+func getAddressPool(network *models.Network, cidr string) (*addressPool, error) {
+	key := addressPoolKey(network.NetID, cidr)
+	if pool, ok := addressPools[key]; ok {
+		return pool, nil
+	}
+	pool, err := newAddressPool(cidr)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := GetNetworkNodes(network.NetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		pool.markUsed(node.Address.IP)
+		pool.markUsed(node.Address6.IP)
+	}
+	clients, err := GetNetworkExtClients(network.NetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, client := range clients {
+		pool.markUsed(net.ParseIP(client.Address))
+		pool.markUsed(net.ParseIP(client.Address6))
+	}
+	addressPools[key] = pool
+	return pool, nil
+}
+
+// allocateAddress - hands out a free address of a network and marks it as in use, the secondary ranges of the
+// network are used in order once its primary range is full
+// callers must give the address back with freeAddresses if the node or ext client it was handed out for is not stored
+func allocateAddress(networkName string, isIpv6 bool, reverse bool) (net.IP, error) {
+	network, err := GetParentNetwork(networkName)
+	if err != nil {
+		return nil, err
+	}
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	record, err := fetchIPAMRecord(networkName)
+	if err != nil {
+		return nil, err
+	}
+	for _, cidr := range familyRanges(&network, isIpv6) {
+		pool, err := getAddressPool(&network, cidr)
+		if err != nil {
+			return nil, err
+		}
+		if offset, ok := pool.next(record.reservedOffsets(pool), record.coolingOffsets(pool), reverse); ok {
+			pool.used = pool.used.add(offset, offset)
+			return pool.address(offset), nil
+		}
+	}
+	return nil, ErrNoAddresses
+}
+
+// ipamRecord.reservedOffsets - returns the offsets of a pool covered by reserved ranges and reservations
+func (record *ipamRecord) reservedOffsets(pool *addressPool) spanSet {
+	reserved := spanSet{}
+	for _, addressRange := range record.Reserved {
+		start, ok := pool.offset(net.ParseIP(addressRange.Start))
+		if !ok {
+			continue
+		}
+		end, ok := pool.offset(net.ParseIP(addressRange.End))
+		if !ok || end < start {
+			continue
+		}
+		reserved = reserved.add(start, end)
+	}
+	for _, reservation := range record.Reservations {
+		if offset, ok := pool.offset(net.ParseIP(reservation.Address)); ok {
+			reserved = reserved.add(offset, offset)
+		}
+	}
+	return reserved
+}
+
+// ipamRecord.coolingOffsets - returns the offsets of a pool whose addresses were released during the cooldown
+func (record *ipamRecord) coolingOffsets(pool *addressPool) map[uint64]bool {
+	cooling := map[uint64]bool{}
+	cutoff := time.Now().Add(-servercfg.GetAddressCooldown()).Unix()
+	for address, released := range record.Released {
+		if released <= cutoff {
+			continue
+		}
+		if offset, ok := pool.offset(net.ParseIP(address)); ok {
+			cooling[offset] = true
+		}
+	}
+	return cooling
+}
+
+// markAddressesUsed - keeps the pools of a network in line with addresses that were set without being allocated
+func markAddressesUsed(network string, addresses ...net.IP) {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	for _, address := range addresses {
+		if address == nil {
+			continue
+		}
+		for _, pool := range networkAddressPools(network) {
+			pool.markUsed(address)
+		}
+	}
+}
+
+// freeAddresses - gives back addresses that were handed out for a node or ext client that was never stored, unlike
+// ReleaseAddresses they can be handed out again right away
+func freeAddresses(network string, addresses ...net.IP) {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	for _, address := range addresses {
+		if address == nil {
+			continue
+		}
+		for _, pool := range networkAddressPools(network) {
+			if offset, ok := pool.offset(address); ok {
+				pool.used = pool.used.remove(offset)
+			}
+		}
+	}
+}
+
+// ReleaseAddresses - frees the addresses of a deleted node or ext client, they are not handed out again until the
+// cooldown has passed
+func ReleaseAddresses(network string, addresses ...net.IP) error {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	record, err := fetchIPAMRecord(network)
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	released := false
+	for _, address := range addresses {
+		if address == nil {
+			continue
+		}
+		for _, pool := range networkAddressPools(network) {
+			if offset, ok := pool.offset(address); ok {
+				pool.used = pool.used.remove(offset)
+			}
+		}
+		record.Released[address.String()] = now
+		released = true
+	}
+	if !released || servercfg.GetAddressCooldown() == 0 {
+		return nil
+	}
+	return saveIPAMRecord(network, record)
+}
+
+// releaseAddressesTx - queues the release of addresses a node no longer uses in given db transaction, see
+// ReleaseAddresses, the transaction fails if the allocator state of the network changes before it is committed
+func releaseAddressesTx(tx *database.Tx, network string, addresses ...net.IP) error {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	revision, err := database.GetRevision(database.IPAM_TABLE_NAME, network)
+	if err != nil {
+		return err
+	}
+	record, err := fetchIPAMRecord(network)
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	released := []net.IP{}
+	for _, address := range addresses {
+		if address == nil {
+			continue
+		}
+		record.Released[address.String()] = now
+		released = append(released, address)
+	}
+	if len(released) == 0 {
+		return nil
+	}
+	tx.OnCommit(func() {
+		addressPoolMutex.Lock()
+		defer addressPoolMutex.Unlock()
+		for _, address := range released {
+			for _, pool := range networkAddressPools(network) {
+				if offset, ok := pool.offset(address); ok {
+					pool.used = pool.used.remove(offset)
+				}
+			}
+		}
+	})
+	if servercfg.GetAddressCooldown() == 0 {
+		return nil
+	}
+	tx.ExpectRevision(database.IPAM_TABLE_NAME, network, revision)
+	return saveIPAMRecordTx(tx, network, record)
+}
+
+// GetReservedAddressRanges - returns the address ranges of a network that are never handed out
+func GetReservedAddressRanges(network string) ([]models.AddressRange, error) {
+	record, err := fetchIPAMRecord(network)
+	if err != nil {
+		return nil, err
+	}
+	return record.Reserved, nil
+}
+
+// ReserveAddressRange - keeps an address range of a network from being handed out, addresses already in use keep
+// their nodes and ext clients
+func ReserveAddressRange(networkName string, addressRange models.AddressRange) error {
+	network, err := GetParentNetwork(networkName)
+	if err != nil {
+		return err
+	}
+	start, end := net.ParseIP(addressRange.Start), net.ParseIP(addressRange.End)
+	if start == nil || end == nil || (start.To4() == nil) != (end.To4() == nil) {
+		return fmt.Errorf("invalid address range %s - %s", addressRange.Start, addressRange.End)
+	}
+	if cidr, ok := networkRangeOf(&network, start); !ok || !cidr.Contains(end) {
+		return fmt.Errorf("address range %s - %s is outside of network %s", addressRange.Start, addressRange.End, networkName)
+	}
+	if new(big.Int).SetBytes(start.To16()).Cmp(new(big.Int).SetBytes(end.To16())) > 0 {
+		return fmt.Errorf("invalid address range %s - %s", addressRange.Start, addressRange.End)
+	}
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	record, err := fetchIPAMRecord(networkName)
+	if err != nil {
+		return err
+	}
+	addressRange = models.AddressRange{Start: start.String(), End: end.String()}
+	for _, reserved := range record.Reserved {
+		if reserved == addressRange {
+			return nil
+		}
+	}
+	record.Reserved = append(record.Reserved, addressRange)
+	return saveIPAMRecord(networkName, record)
+}
+
+// UnreserveAddressRange - lets a reserved address range of a network be handed out again
+func UnreserveAddressRange(network string, addressRange models.AddressRange) error {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	record, err := fetchIPAMRecord(network)
+	if err != nil {
+		return err
+	}
+	start, end := net.ParseIP(addressRange.Start), net.ParseIP(addressRange.End)
+	reserved := []models.AddressRange{}
+	for _, current := range record.Reserved {
+		if net.ParseIP(current.Start).Equal(start) && net.ParseIP(current.End).Equal(end) {
+			continue
+		}
+		reserved = append(reserved, current)
+	}
+	if len(reserved) == len(record.Reserved) {
+		return fmt.Errorf("address range %s - %s on network %s: %w", addressRange.Start, addressRange.End, network, ErrReservationNotFound)
+	}
+	record.Reserved = reserved
+	return saveIPAMRecord(network, record)
+}
+
+// GetAddressReservations - returns the addresses of a network that are kept for specific hosts
+func GetAddressReservations(network string) ([]models.AddressReservation, error) {
+	record, err := fetchIPAMRecord(network)
+	if err != nil {
+		return nil, err
+	}
+	return record.Reservations, nil
+}
+
+// CreateAddressReservation - keeps an address of a network for a host, identified by its id or its name
+// the host is given the address when it joins the network and the address is never handed out to anyone else
+func CreateAddressReservation(networkName string, reservation models.AddressReservation) (models.AddressReservation, error) {
+	network, err := GetParentNetwork(networkName)
+	if err != nil {
+		return reservation, err
+	}
+	address := net.ParseIP(reservation.Address)
+	if address == nil {
+		return reservation, fmt.Errorf("invalid address %s", reservation.Address)
+	}
+	if (reservation.HostID == "") == (reservation.HostName == "") {
+		return reservation, errors.New("a reservation is bound to either a host id or a host name")
+	}
+	if reservation.HostID != "" {
+		if _, err := uuid.Parse(reservation.HostID); err != nil {
+			return reservation, fmt.Errorf("invalid host id %s", reservation.HostID)
+		}
+	}
+	isIpv6 := address.To4() == nil
+	cidr, ok := networkRangeOf(&network, address)
+	if !ok {
+		return reservation, fmt.Errorf("address %s is outside of network %s", reservation.Address, networkName)
+	}
+	pool, err := newAddressPool(cidr.String())
+	if err != nil {
+		return reservation, err
+	}
+	if offset, ok := pool.offset(address); !ok || offset < pool.first {
+		return reservation, fmt.Errorf("address %s can not be assigned on network %s", reservation.Address, networkName)
+	}
+	reservation.Address = address.String()
+	if err = checkReservedAddressUse(networkName, reservation, isIpv6); err != nil {
+		return reservation, err
+	}
+
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	record, err := fetchIPAMRecord(networkName)
+	if err != nil {
+		return reservation, err
+	}
+	for _, current := range record.Reservations {
+		if net.ParseIP(current.Address).Equal(address) {
+			return reservation, fmt.Errorf("%w: address %s is already reserved", ErrReservationConflict, reservation.Address)
+		}
+		if isReservedFor(current, reservation.HostID, reservation.HostName) && (net.ParseIP(current.Address).To4() == nil) == isIpv6 {
+			return reservation, fmt.Errorf("%w: host already has reserved address %s", ErrReservationConflict, current.Address)
+		}
+	}
+	record.Reservations = append(record.Reservations, reservation)
+	return reservation, saveIPAMRecord(networkName, record)
+}
+
+// checkReservedAddressUse - makes sure an address to reserve is free or used by a node of the host it is reserved for
+func checkReservedAddressUse(network string, reservation models.AddressReservation, isIpv6 bool) error {
+	if !IsIPUnique(network, reservation.Address, database.EXT_CLIENT_TABLE_NAME, isIpv6) {
+		return fmt.Errorf("%w: address %s is in use by an ext client", ErrReservationConflict, reservation.Address)
+	}
+	nodes, err := GetNetworkNodes(network)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		nodeAddress := node.Address.IP
+		if isIpv6 {
+			nodeAddress = node.Address6.IP
+		}
+		if nodeAddress == nil || nodeAddress.String() != reservation.Address {
+			continue
+		}
+		host, err := GetHost(node.HostID.String())
+		if err != nil || !isReservedFor(reservation, host.ID.String(), host.Name) {
+			return fmt.Errorf("%w: address %s is in use by node %s", ErrReservationConflict, reservation.Address, node.ID.String())
+		}
+	}
+	return nil
+}
+
+// DeleteAddressReservation - lets a reserved address be handed out again, a node already using it keeps it
+func DeleteAddressReservation(network string, address string) error {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	record, err := fetchIPAMRecord(network)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(address)
+	reservations := []models.AddressReservation{}
+	for _, current := range record.Reservations {
+		if net.ParseIP(current.Address).Equal(ip) {
+			continue
+		}
+		reservations = append(reservations, current)
+	}
+	if len(reservations) == len(record.Reservations) {
+		return fmt.Errorf("%w: address %s is not reserved on network %s", ErrReservationNotFound, address, network)
+	}
+	record.Reservations = reservations
+	return saveIPAMRecord(network, record)
+}
+
+// isReservedFor - checks if a reservation is bound to a host id or host name, host names are compared case insensitively
+func isReservedFor(reservation models.AddressReservation, hostID string, hostName string) bool {
+	if reservation.HostID != "" {
+		return reservation.HostID == hostID
+	}
+	return hostName != "" && strings.EqualFold(reservation.HostName, hostName)
+}
+
+// hostAddress - returns the address reserved for a host on a network if it is still free, otherwise a free address
+func hostAddress(network string, host *models.Host, isIpv6 bool) (net.IP, error) {
+	reservations, err := GetAddressReservations(network)
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range reservations {
+		address := net.ParseIP(reservation.Address)
+		if address == nil || (address.To4() == nil) != isIpv6 || !isReservedFor(reservation, host.ID.String(), host.Name) {
+			continue
+		}
+		if !isAddressTaken(network, address.String(), isIpv6) {
+			if !isIpv6 {
+				address = address.To4()
+			}
+			return address, nil
+		}
+		logger.Log(0, "reserved address", address.String(), "of host", host.Name, "is in use on network", network)
+	}
+	if isIpv6 {
+		return UniqueAddress6(network, false)
+	}
+	return UniqueAddress(network, false)
+}
+
+// resetAddressPools - drops the pools of a network so they are reloaded on next use, ie. after its range changed
+func resetAddressPools(network string) {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	for key := range addressPools {
+		if strings.HasPrefix(key, network+"/") {
+			delete(addressPools, key)
+		}
+	}
+}
+
+// clearAddressPools - drops the pools of every network
+func clearAddressPools() {
+	addressPoolMutex.Lock()
+	defer addressPoolMutex.Unlock()
+	addressPools = map[string]*addressPool{}
+}
@@ -0,0 +1,285 @@
+package logic
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpanSet(t *testing.T) {
+	set := spanSet{}
+	set = set.add(5, 5)
+	set = set.add(1, 3)
+	set = set.add(4, 4)
+	assert.Equal(t, spanSet{{lo: 1, hi: 5}}, set)
+	set = set.add(10, 20)
+	set = set.remove(15)
+	assert.Equal(t, spanSet{{lo: 1, hi: 5}, {lo: 10, hi: 14}, {lo: 16, hi: 20}}, set)
+	set = set.add(0, 30)
+	assert.Equal(t, spanSet{{lo: 0, hi: 30}}, set)
+	_, found := set.find(31)
+	assert.False(t, found)
+}
+
+func TestAddressPool(t *testing.T) {
+	t.Run("IPv4", func(t *testing.T) {
+		pool, err := newAddressPool("10.0.0.0/30")
+		assert.Nil(t, err)
+		pool.markUsed(net.ParseIP("10.0.0.1"))
+		offset, ok := pool.next(spanSet{}, nil, false)
+		assert.True(t, ok)
+		assert.Equal(t, "10.0.0.2", pool.address(offset).String())
+		_, ok = pool.next(spanSet{{lo: 2, hi: 2}}, nil, false)
+		assert.False(t, ok)
+	})
+	t.Run("IPv6", func(t *testing.T) {
+		pool, err := newAddressPool("fd00::/48")
+		assert.Nil(t, err)
+		offset, ok := pool.next(spanSet{}, nil, true)
+		assert.True(t, ok)
+		assert.Equal(t, "fd00::ffff:ffff:ffff:fffe", pool.address(offset).String())
+		pool.markUsed(net.ParseIP("fd00::1"))
+		offset, ok = pool.next(spanSet{}, map[uint64]bool{2: true}, false)
+		assert.True(t, ok)
+		assert.Equal(t, "fd00::3", pool.address(offset).String())
+	})
+}
+
+func TestAllocateAddress(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	t.Setenv("ADDRESS_COOLDOWN", "1h")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("ipamnet")
+	})
+	CreateNetwork(models.Network{NetID: "ipamnet", AddressRange: "10.98.0.0/29"})
+	assert.Nil(t, ReserveAddressRange("ipamnet", models.AddressRange{Start: "10.98.0.1", End: "10.98.0.2"}))
+	assert.NotNil(t, ReserveAddressRange("ipamnet", models.AddressRange{Start: "10.97.0.1", End: "10.97.0.2"}))
+
+	address, err := UniqueAddress("ipamnet", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.98.0.3", address.String())
+
+	client := models.ExtClient{ClientID: "ipamclient", Network: "ipamnet", IngressGatewayID: uuid.New().String()}
+	assert.Nil(t, CreateExtClient(&client))
+	assert.Equal(t, "10.98.0.6", client.Address)
+	// an update that keeps the address of the client does not release it
+	revision, err := database.GetRevision(database.IPAM_TABLE_NAME, "ipamnet")
+	assert.Nil(t, err)
+	updated, err := UpdateExtClient(&client, &models.CustomExtClient{ClientID: "ipamclient", DNS: "10.98.0.3", Enabled: client.Enabled})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.98.0.6", updated.Address)
+	current, err := database.GetRevision(database.IPAM_TABLE_NAME, "ipamnet")
+	assert.Nil(t, err)
+	assert.Equal(t, revision, current)
+	assert.Nil(t, DeleteExtClient("ipamnet", "ipamclient"))
+	// the released address is cooling down
+	address, err = UniqueAddress("ipamnet", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.98.0.5", address.String())
+
+	ranges, err := GetReservedAddressRanges("ipamnet")
+	assert.Nil(t, err)
+	assert.Equal(t, []models.AddressRange{{Start: "10.98.0.1", End: "10.98.0.2"}}, ranges)
+	assert.ErrorIs(t, UnreserveAddressRange("ipamnet", models.AddressRange{Start: "10.98.0.1", End: "10.98.0.3"}), ErrReservationNotFound)
+	assert.Nil(t, UnreserveAddressRange("ipamnet", models.AddressRange{Start: "10.98.0.1", End: "10.98.0.2"}))
+	address, err = UniqueAddress("ipamnet", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.98.0.1", address.String())
+	// an address that was handed out but never stored is free again right away
+	freeAddresses("ipamnet", address)
+	address, err = UniqueAddress("ipamnet", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.98.0.1", address.String())
+}
+
+func TestUpdateNodeAddress(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	t.Setenv("ADDRESS_COOLDOWN", "1h")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("movenet")
+	})
+	CreateNetwork(models.Network{NetID: "movenet", AddressRange: "10.79.0.0/29"})
+	host := models.Host{ID: uuid.New(), Name: "movehost", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&host))
+	t.Cleanup(func() {
+		RemoveHost(&host, true)
+	})
+	node, err := UpdateHostNetwork(&host, "movenet", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(node, true)
+	})
+	assert.Equal(t, "10.79.0.1", node.Address.IP.String())
+
+	newNode := *node
+	newNode.Address.IP = net.ParseIP("10.79.0.4")
+	assert.Nil(t, UpdateNode(node, &newNode))
+	// the replaced address is cooling down and the new one is in use
+	address, err := UniqueAddress("movenet", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.79.0.2", address.String())
+	address, err = UniqueAddress("movenet", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.79.0.6", address.String())
+	address, err = UniqueAddress("movenet", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.79.0.5", address.String())
+	address, err = UniqueAddress("movenet", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.79.0.3", address.String())
+}
+
+func TestAddressReservations(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("reservenet")
+	})
+	CreateNetwork(models.Network{NetID: "reservenet", AddressRange: "10.97.0.0/29"})
+
+	_, err := CreateAddressReservation("reservenet", models.AddressReservation{Address: "10.97.0.1"})
+	assert.NotNil(t, err)
+	_, err = CreateAddressReservation("reservenet", models.AddressReservation{Address: "10.96.0.1", HostName: "reservehost"})
+	assert.NotNil(t, err)
+	_, err = CreateAddressReservation("reservenet", models.AddressReservation{Address: "10.97.0.0", HostName: "reservehost"})
+	assert.NotNil(t, err)
+	reservation, err := CreateAddressReservation("reservenet", models.AddressReservation{Address: "10.97.0.5", HostName: "ReserveHost"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.97.0.5", reservation.Address)
+	_, err = CreateAddressReservation("reservenet", models.AddressReservation{Address: "10.97.0.5", HostName: "otherhost"})
+	assert.ErrorIs(t, err, ErrReservationConflict)
+
+	// the reserved address is skipped for everyone else
+	address, err := UniqueAddress("reservenet", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.97.0.6", address.String())
+	address, err = UniqueAddress("reservenet", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.97.0.4", address.String())
+
+	host := models.Host{ID: uuid.New(), Name: "reservehost", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&host))
+	t.Cleanup(func() {
+		RemoveHost(&host, true)
+	})
+	node, err := UpdateHostNetwork(&host, "reservenet", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.97.0.5", node.Address.IP.String())
+	assert.Equal(t, "10.97.0.5/29", node.Address.String())
+
+	reservations, err := GetAddressReservations("reservenet")
+	assert.Nil(t, err)
+	assert.Len(t, reservations, 1)
+	assert.ErrorIs(t, DeleteAddressReservation("reservenet", "10.97.0.6"), ErrReservationNotFound)
+	assert.Nil(t, DeleteAddressReservation("reservenet", "10.97.0.5"))
+	assert.Nil(t, DeleteNode(node, true))
+}
+
+func TestSecondaryRanges(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	t.Setenv("ADDRESS_COOLDOWN", "0")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("rangesnet")
+	})
+	_, err := CreateNetwork(models.Network{NetID: "rangesnet", AddressRange: "10.93.0.0/30", SecondaryRanges: []string{"10.93.0.1/30"}})
+	assert.NotNil(t, err)
+	_, err = CreateNetwork(models.Network{NetID: "rangesnet", AddressRange: "10.93.0.0/30", SecondaryRanges: []string{"fd93::/64"}})
+	assert.NotNil(t, err)
+	CreateNetwork(models.Network{NetID: "rangesnet", AddressRange: "10.93.0.0/30", SecondaryRanges: []string{"10.92.0.1/30"}})
+	network, err := GetNetwork("rangesnet")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.92.0.0/30"}, network.SecondaryRanges)
+	assert.Equal(t, []string{"10.93.0.0/30", "10.92.0.0/30"}, network.AllAddressRanges())
+
+	for _, expected := range []string{"10.93.0.1", "10.93.0.2"} {
+		address, err := UniqueAddress("rangesnet", false)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, address.String())
+	}
+	// the primary range is full, the client falls through to the secondary range
+	client := models.ExtClient{ClientID: "rangesclient", Network: "rangesnet", IngressGatewayID: uuid.New().String()}
+	assert.Nil(t, CreateExtClient(&client))
+	assert.Equal(t, "10.92.0.2", client.Address)
+
+	_, _, err = UpdateNetworkRanges("rangesnet", []string{"10.93.0.0/24"}, nil)
+	assert.NotNil(t, err)
+	_, _, err = UpdateNetworkRanges("rangesnet", []string{}, nil)
+	assert.NotNil(t, err)
+	revision, err := database.GetRevision(database.NETWORKS_TABLE_NAME, "rangesnet")
+	assert.Nil(t, err)
+	stale := revision - 1
+	_, _, err = UpdateNetworkRanges("rangesnet", []string{"10.92.0.0/30", "10.91.0.0/24"}, &stale)
+	assert.ErrorIs(t, err, database.ErrRevisionMismatch)
+	network, _, err = UpdateNetworkRanges("rangesnet", []string{"10.92.0.0/30", "10.91.0.0/24"}, &revision)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.92.0.0/30", "10.91.0.0/24"}, network.SecondaryRanges)
+	assert.Nil(t, DeleteExtClient("rangesnet", "rangesclient"))
+	network, _, err = UpdateNetworkRanges("rangesnet", []string{"10.91.0.0/24"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.91.0.0/24"}, network.SecondaryRanges)
+}
+
+func TestULANetwork(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	t.Setenv("ADDRESS_COOLDOWN", "0")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		for i := 1; i <= 6; i++ {
+			DeleteNetwork(fmt.Sprintf("ulanet%d", i))
+		}
+	})
+	CreateNetwork(models.Network{NetID: "ulanet1", AddressRange6: models.ULAAddressRange6})
+	CreateNetwork(models.Network{NetID: "ulanet2", AddressRange6: models.ULAAddressRange6})
+	first, err := GetNetwork("ulanet1")
+	assert.Nil(t, err)
+	second, err := GetNetwork("ulanet2")
+	assert.Nil(t, err)
+	assert.Equal(t, "no", first.IsIPv4)
+	assert.Equal(t, "yes", first.IsIPv6)
+	_, firstRange, err := net.ParseCIDR(first.AddressRange6)
+	assert.Nil(t, err)
+	_, secondRange, err := net.ParseCIDR(second.AddressRange6)
+	assert.Nil(t, err)
+	ones, _ := firstRange.Mask.Size()
+	assert.Equal(t, 64, ones)
+	assert.Equal(t, byte(0xfd), firstRange.IP[0])
+	// both ranges share the /48 of the server and differ in their subnet id
+	assert.Equal(t, firstRange.IP[:6], secondRange.IP[:6])
+	assert.NotEqual(t, firstRange.String(), secondRange.String())
+
+	client := models.ExtClient{ClientID: "ulaclient", Network: "ulanet1", IngressGatewayID: uuid.New().String()}
+	assert.Nil(t, CreateExtClient(&client))
+	assert.Empty(t, client.Address)
+	assert.True(t, firstRange.Contains(net.ParseIP(client.Address6)))
+	assert.Nil(t, DeleteExtClient("ulanet1", "ulaclient"))
+
+	assert.Nil(t, ValidateDNSCreate(models.DNSEntry{Name: "ulahost", Network: "ulanet1", Address6: "fd00::1"}))
+	assert.NotNil(t, ValidateDNSCreate(models.DNSEntry{Name: "ulahost", Network: "ulanet1"}))
+
+	// networks created at the same time are given different ranges
+	var wg sync.WaitGroup
+	for i := 3; i <= 6; i++ {
+		wg.Add(1)
+		go func(netID string) {
+			defer wg.Done()
+			CreateNetwork(models.Network{NetID: netID, AddressRange6: models.ULAAddressRange6})
+		}(fmt.Sprintf("ulanet%d", i))
+	}
+	wg.Wait()
+	ranges := map[string]bool{}
+	for i := 1; i <= 6; i++ {
+		network, err := GetNetwork(fmt.Sprintf("ulanet%d", i))
+		assert.Nil(t, err)
+		ranges[network.AddressRange6] = true
+	}
+	assert.Len(t, ranges, 6)
+}
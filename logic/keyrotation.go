@@ -0,0 +1,174 @@
+package logic
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/models"
+)
+
+const (
+	// KeyRotationCheckInterval - how often hosts are checked for keys that are due for rotation
+	KeyRotationCheckInterval = time.Minute * 5
+	// keyRotationBatchSize - the most hosts asked to rotate their keys per check, spreading the rotations of hosts
+	// whose keys were created together over several checks
+	keyRotationBatchSize = 20
+	// keyRotationRetryAfter - how long to wait for a host to rotate its key before asking again
+	keyRotationRetryAfter = time.Hour
+	// hostOnlineWindow - hosts with a node that checked in within this window are online
+	hostOnlineWindow = time.Minute * 5
+)
+
+// SetKeyRotationPolicy - sets how many days the hosts of a network keep their wireguard keys, 0 disables rotation
+// the network is only saved if it is still at the given revision, see updateNetworkAtRevision
+func SetKeyRotationPolicy(networkName string, policy models.KeyRotationPolicy, revision *uint64) (models.Network, error) {
+	return updateNetworkAtRevision(networkName, revision, func(network *models.Network) error {
+		network.KeyRotationDays = policy.IntervalDays
+		return ValidateNetwork(network, true)
+	})
+}
+
+// GetKeyRotationStatus - returns the key rotation policy of a network with the key age of each of its hosts
+func GetKeyRotationStatus(networkName string) (models.KeyRotationStatus, error) {
+	status := models.KeyRotationStatus{Network: networkName, Hosts: []models.HostKeyStatus{}}
+	network, err := GetNetwork(networkName)
+	if err != nil {
+		return status, err
+	}
+	status.IntervalDays = network.KeyRotationDays
+	nodes, err := GetNetworkNodes(networkName)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return status, err
+	}
+	now := time.Now()
+	networks := map[string]int32{}
+	for _, node := range nodes {
+		host, err := GetHost(node.HostID.String())
+		if err != nil {
+			continue
+		}
+		hostStatus := models.HostKeyStatus{
+			HostID:              host.ID.String(),
+			HostName:            host.Name,
+			KeysUpdatedAt:       host.KeysUpdatedAt,
+			KeyAgeDays:          -1,
+			IntervalDays:        hostKeyRotationDays(host, networks),
+			RotationRequestedAt: host.KeyRotationRequestedAt,
+		}
+		if !host.KeysUpdatedAt.IsZero() {
+			hostStatus.KeyAgeDays = int(now.Sub(host.KeysUpdatedAt).Hours() / 24)
+		}
+		if hostStatus.IntervalDays > 0 {
+			hostStatus.DueAt = keyRotationDueAt(host, hostStatus.IntervalDays)
+			hostStatus.Overdue = !now.Before(hostStatus.DueAt)
+		}
+		status.Hosts = append(status.Hosts, hostStatus)
+	}
+	sort.Slice(status.Hosts, func(i, j int) bool {
+		return status.Hosts[i].HostName < status.Hosts[j].HostName
+	})
+	return status, nil
+}
+
+// DueKeyRotations - returns the online hosts whose keys are older than the rotation policy of their networks and
+// that were not asked to rotate recently, longest overdue first and at most one batch
+func DueKeyRotations(now time.Time) ([]models.Host, error) {
+	hosts, err := GetAllHosts()
+	if err != nil {
+		return nil, err
+	}
+	networks := map[string]int32{}
+	dueAt := map[string]time.Time{}
+	due := []models.Host{}
+	for i := range hosts {
+		host := &hosts[i]
+		if !KeyRotationDue(host, now, networks) || !IsHostOnline(host, now) {
+			continue
+		}
+		dueAt[host.ID.String()] = keyRotationDueAt(host, hostKeyRotationDays(host, networks))
+		due = append(due, *host)
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return dueAt[due[i].ID.String()].Before(dueAt[due[j].ID.String()])
+	})
+	if len(due) > keyRotationBatchSize {
+		due = due[:keyRotationBatchSize]
+	}
+	return due, nil
+}
+
+// KeyRotationDue - checks if a host should be asked to rotate its key, networks caches the rotation policies of
+// networks by name and may be nil
+func KeyRotationDue(host *models.Host, now time.Time, networks map[string]int32) bool {
+	if networks == nil {
+		networks = map[string]int32{}
+	}
+	days := hostKeyRotationDays(host, networks)
+	if days <= 0 || now.Before(keyRotationDueAt(host, days)) {
+		return false
+	}
+	return host.KeyRotationRequestedAt.IsZero() || now.Sub(host.KeyRotationRequestedAt) >= keyRotationRetryAfter
+}
+
+// KeyRotationRequested - records that a host was asked to rotate its key, the request is cleared once the host sends
+// its new key
+func KeyRotationRequested(host *models.Host) error {
+	currentHost, err := GetHost(host.ID.String())
+	if err != nil {
+		return err
+	}
+	currentHost.KeyRotationRequestedAt = time.Now()
+	host.KeyRotationRequestedAt = currentHost.KeyRotationRequestedAt
+	logger.Log(1, "requested key rotation of host", host.Name, host.ID.String())
+	return UpsertHost(currentHost)
+}
+
+// IsHostOnline - checks if any node of a host checked in recently
+func IsHostOnline(host *models.Host, now time.Time) bool {
+	for _, nodeID := range host.Nodes {
+		node, err := GetNodeByID(nodeID)
+		if err != nil {
+			continue
+		}
+		if now.Sub(node.LastCheckIn) < hostOnlineWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// hostKeyRotationDays - returns the shortest rotation interval of the networks of a host, 0 if none of them rotates
+// keys, networks caches the policies of networks by name
+func hostKeyRotationDays(host *models.Host, networks map[string]int32) int32 {
+	var days int32
+	for _, nodeID := range host.Nodes {
+		node, err := GetNodeByID(nodeID)
+		if err != nil {
+			continue
+		}
+		networkDays, ok := networks[node.Network]
+		if !ok {
+			network, err := GetNetwork(node.Network)
+			if err != nil {
+				continue
+			}
+			networkDays = network.KeyRotationDays
+			networks[node.Network] = networkDays
+		}
+		if networkDays > 0 && (days == 0 || networkDays < days) {
+			days = networkDays
+		}
+	}
+	return days
+}
+
+// keyRotationDueAt - returns when the key of a host is due for rotation, hosts whose key age is not known are due
+// right away
+func keyRotationDueAt(host *models.Host, days int32) time.Time {
+	if host.KeysUpdatedAt.IsZero() {
+		return time.Time{}
+	}
+	return host.KeysUpdatedAt.Add(time.Hour * 24 * time.Duration(days))
+}
@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+func TestKeyRotation(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("rotatenet")
+	})
+	CreateNetwork(models.Network{NetID: "rotatenet", AddressRange: "10.85.0.0/24"})
+	host := models.Host{ID: uuid.New(), Name: "rotatehost", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&host))
+	t.Cleanup(func() {
+		RemoveHost(&host, true)
+	})
+	node, err := UpdateHostNetwork(&host, "rotatenet", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(node, true)
+	})
+	_, err = SetKeyRotationPolicy("rotatenet", models.KeyRotationPolicy{IntervalDays: -1}, nil)
+	assert.NotNil(t, err)
+	network, err := SetKeyRotationPolicy("rotatenet", models.KeyRotationPolicy{IntervalDays: 30}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(30), network.KeyRotationDays)
+	revision, err := database.GetRevision(database.NETWORKS_TABLE_NAME, "rotatenet")
+	assert.Nil(t, err)
+	stale := revision - 1
+	_, err = SetKeyRotationPolicy("rotatenet", models.KeyRotationPolicy{IntervalDays: 7}, &stale)
+	assert.ErrorIs(t, err, database.ErrRevisionMismatch)
+
+	now := time.Now()
+	current, err := GetHost(host.ID.String())
+	assert.Nil(t, err)
+	assert.False(t, current.KeysUpdatedAt.IsZero(), "new hosts start with fresh keys")
+	assert.False(t, KeyRotationDue(current, now, nil))
+
+	current.KeysUpdatedAt = now.Add(-time.Hour * 24 * 31)
+	assert.Nil(t, UpsertHost(current))
+	assert.True(t, KeyRotationDue(current, now, nil))
+	due, err := DueKeyRotations(now)
+	assert.Nil(t, err)
+	assert.Contains(t, hostIDs(due), host.ID.String())
+	due, err = DueKeyRotations(now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.NotContains(t, hostIDs(due), host.ID.String(), "offline hosts wait for their next check in")
+
+	status, err := GetKeyRotationStatus("rotatenet")
+	assert.Nil(t, err)
+	assert.Len(t, status.Hosts, 1)
+	assert.Equal(t, 31, status.Hosts[0].KeyAgeDays)
+	assert.True(t, status.Hosts[0].Overdue)
+
+	assert.Nil(t, KeyRotationRequested(current))
+	current, _ = GetHost(host.ID.String())
+	assert.False(t, KeyRotationDue(current, now, nil), "hosts are not asked again right away")
+	assert.True(t, KeyRotationDue(current, time.Now().Add(keyRotationRetryAfter), nil))
+
+	key, err := wgtypes.GeneratePrivateKey()
+	assert.Nil(t, err)
+	update := *current
+	update.PublicKey = key.PublicKey()
+	UpdateHostFromClient(&update, current)
+	assert.True(t, current.KeyRotationRequestedAt.IsZero())
+	assert.False(t, KeyRotationDue(current, now, nil))
+}
+
+func hostIDs(hosts []models.Host) []string {
+	ids := []string{}
+	for _, host := range hosts {
+		ids = append(ids, host.ID.String())
+	}
+	return ids
+}
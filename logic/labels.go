@@ -0,0 +1,94 @@
+package logic
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic/acls"
+	"github.com/gravitl/netmaker/models"
+)
+
+// NodeLabels - returns the labels of a node on top of the labels of its host
+func NodeLabels(node *models.Node) map[string]string {
+	host, err := GetHost(node.HostID.String())
+	if err != nil {
+		return models.MergeLabels(nil, node.Labels)
+	}
+	return models.MergeLabels(host.Labels, node.Labels)
+}
+
+// SelectNodes - returns the nodes whose labels, including those of their host, match a selector
+func SelectNodes(nodes []models.Node, selector models.LabelSelector) []models.Node {
+	selected := []models.Node{}
+	for _, node := range nodes {
+		if selector.Matches(NodeLabels(&node)) {
+			selected = append(selected, node)
+		}
+	}
+	return selected
+}
+
+// SelectHosts - returns the hosts whose labels match a selector
+func SelectHosts(hosts []models.Host, selector models.LabelSelector) []models.Host {
+	selected := []models.Host{}
+	for _, host := range hosts {
+		if selector.Matches(host.Labels) {
+			selected = append(selected, host)
+		}
+	}
+	return selected
+}
+
+// ApplyEnrollmentKeyLabels - adds the tags of an enrollment key to the labels of a host, tags that are not valid
+// labels are skipped
+func ApplyEnrollmentKeyLabels(host *models.Host, key *models.EnrollmentKey) {
+	labels, invalid := models.LabelsFromTags(key.Tags)
+	if len(invalid) > 0 {
+		logger.Log(1, "skipping enrollment key tags that are not valid labels for host", host.Name, ":", strings.Join(invalid, ", "))
+	}
+	if len(labels) == 0 {
+		return
+	}
+	host.Labels = models.MergeLabels(host.Labels, labels)
+}
+
+// UpdateACLsBySelector - allows or denies traffic between every node of a network matching one selector and every
+// node matching the other, returns the updated ACLs of the network
+// the ACLs are only saved if they are still at the given revision, a nil revision always saves
+func UpdateACLsBySelector(network string, request models.SelectorACLRequest, revision *uint64) (acls.ACLContainer, error) {
+	from, err := models.ParseLabelSelector(request.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := models.ParseLabelSelector(request.To)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := GetNetworkNodes(network)
+	if err != nil {
+		return nil, err
+	}
+	fromNodes, toNodes := SelectNodes(nodes, from), SelectNodes(nodes, to)
+	if len(fromNodes) == 0 || len(toNodes) == 0 {
+		return nil, errors.New("the selectors match no nodes of the network")
+	}
+	currentACL, err := (acls.ACLContainer{}).Get(acls.ContainerID(network))
+	if err != nil {
+		return nil, err
+	}
+	networkACL := currentACL.Copy()
+	value := acls.NotAllowed
+	if request.Allowed {
+		value = acls.Allowed
+	}
+	for _, fromNode := range fromNodes {
+		for _, toNode := range toNodes {
+			if fromNode.ID == toNode.ID {
+				continue
+			}
+			networkACL.ChangeAccess(acls.AclID(fromNode.ID.String()), acls.AclID(toNode.ID.String()), value)
+		}
+	}
+	return networkACL.SaveAtRevision(acls.ContainerID(network), revision)
+}
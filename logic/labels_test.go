@@ -0,0 +1,82 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logic/acls/nodeacls"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelSelector(t *testing.T) {
+	selector, err := models.ParseLabelSelector("env=prod, role!=db,backup,!legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, "env=prod,role!=db,backup,!legacy", selector.String())
+	assert.True(t, selector.Matches(map[string]string{"env": "prod", "backup": ""}))
+	assert.True(t, selector.Matches(map[string]string{"env": "prod", "role": "web", "backup": "daily"}))
+	assert.False(t, selector.Matches(map[string]string{"env": "prod", "role": "db", "backup": ""}))
+	assert.False(t, selector.Matches(map[string]string{"env": "prod", "backup": "", "legacy": "yes"}))
+	assert.False(t, selector.Matches(map[string]string{"env": "dev", "backup": ""}))
+	empty, err := models.ParseLabelSelector("")
+	assert.Nil(t, err)
+	assert.True(t, empty.Matches(nil))
+	_, err = models.ParseLabelSelector("env=prod=eu")
+	assert.NotNil(t, err)
+	_, err = models.ParseLabelSelector("=prod")
+	assert.NotNil(t, err)
+
+	labels, invalid := models.LabelsFromTags([]string{"env=prod", "db", "not a label"})
+	assert.Equal(t, map[string]string{"env": "prod", "db": ""}, labels)
+	assert.Equal(t, []string{"not a label"}, invalid)
+}
+
+func TestSelectorACLs(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("labelnet")
+	})
+	CreateNetwork(models.Network{NetID: "labelnet", AddressRange: "10.83.0.0/24"})
+	web := models.Host{ID: uuid.New(), Name: "labelweb", HostPass: "password", OS: "linux",
+		Labels: map[string]string{"env": "prod", "role": "web"}}
+	db := models.Host{ID: uuid.New(), Name: "labeldb", HostPass: "password", OS: "linux",
+		Labels: map[string]string{"env": "prod", "role": "db"}}
+	assert.Nil(t, CreateHost(&web))
+	assert.Nil(t, CreateHost(&db))
+	t.Cleanup(func() {
+		RemoveHost(&web, true)
+		RemoveHost(&db, true)
+	})
+	webNode, err := UpdateHostNetwork(&web, "labelnet", true)
+	assert.Nil(t, err)
+	dbNode, err := UpdateHostNetwork(&db, "labelnet", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(webNode, true)
+		DeleteNode(dbNode, true)
+	})
+	host, err := GetHost(web.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "web", host.Labels["role"], "labels survive joining a network")
+
+	// node labels take precedence over the labels of the host
+	dbNode.Labels = map[string]string{"env": "staging"}
+	assert.Nil(t, UpsertNode(dbNode))
+	nodes, err := GetNetworkNodes("labelnet")
+	assert.Nil(t, err)
+	selector, _ := models.ParseLabelSelector("env=prod")
+	selected := SelectNodes(nodes, selector)
+	assert.Len(t, selected, 1)
+	assert.Equal(t, webNode.ID, selected[0].ID)
+
+	_, err = UpdateACLsBySelector("labelnet", models.SelectorACLRequest{From: "role=web", To: "role=cache"}, nil)
+	assert.NotNil(t, err)
+	_, err = UpdateACLsBySelector("labelnet", models.SelectorACLRequest{From: "role=web", To: "role=db"}, nil)
+	assert.Nil(t, err)
+	assert.False(t, nodeacls.AreNodesAllowed("labelnet", nodeacls.NodeID(webNode.ID.String()), nodeacls.NodeID(dbNode.ID.String())))
+	_, err = UpdateACLsBySelector("labelnet", models.SelectorACLRequest{From: "role=web", To: "env=staging", Allowed: true}, nil)
+	assert.Nil(t, err)
+	assert.True(t, nodeacls.AreNodesAllowed("labelnet", nodeacls.NodeID(webNode.ID.String()), nodeacls.NodeID(dbNode.ID.String())))
+}
@@ -0,0 +1,243 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/models"
+)
+
+// GetNetworkTemplates - returns all network templates sorted by name
+func GetNetworkTemplates() ([]models.NetworkTemplate, error) {
+	templates := []models.NetworkTemplate{}
+	records, err := database.FetchRecords(database.NETWORK_TEMPLATES_TABLE_NAME)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return templates, nil
+		}
+		return templates, err
+	}
+	for _, value := range records {
+		var template models.NetworkTemplate
+		if err := json.Unmarshal([]byte(value), &template); err != nil {
+			continue
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// GetNetworkTemplate - returns a network template by name
+func GetNetworkTemplate(name string) (models.NetworkTemplate, error) {
+	var template models.NetworkTemplate
+	value, err := database.FetchRecord(database.NETWORK_TEMPLATES_TABLE_NAME, name)
+	if err != nil {
+		return template, err
+	}
+	err = json.Unmarshal([]byte(value), &template)
+	return template, err
+}
+
+// CreateNetworkTemplate - validates and stores a network template, a template of the same name is replaced
+func CreateNetworkTemplate(template models.NetworkTemplate) (models.NetworkTemplate, error) {
+	template.Network.NetID = ""
+	template.Network.SecondaryRanges = nil
+	template.Network.NodesLastModified = 0
+	template.Network.NetworkLastModified = 0
+	if err := validator.New().Var(template.Name, "required,max=32,excludesall=/ "); err != nil {
+		return template, fmt.Errorf("invalid template name %q: %w", template.Name, err)
+	}
+	for i := range template.DNS {
+		template.DNS[i].Network = ""
+		if template.DNS[i].Name == "" {
+			return template, errors.New("dns entries of a template need a name")
+		}
+		if template.DNS[i].Address == "" && template.DNS[i].Address6 == "" {
+			return template, fmt.Errorf("dns entry %s of the template has no address", template.DNS[i].Name)
+		}
+	}
+	for _, key := range template.EnrollmentKeys {
+		if key.UsesRemaining <= 0 && key.Validity <= 0 && !key.Unlimited {
+			return template, errors.New("enrollment keys of a template need uses, a validity or to be unlimited")
+		}
+	}
+	data, err := json.Marshal(&template)
+	if err != nil {
+		return template, err
+	}
+	return template, database.Insert(template.Name, string(data), database.NETWORK_TEMPLATES_TABLE_NAME)
+}
+
+// DeleteNetworkTemplate - deletes a network template, networks created from it are not affected
+func DeleteNetworkTemplate(name string) error {
+	if _, err := GetNetworkTemplate(name); err != nil {
+		return err
+	}
+	return database.DeleteRecord(database.NETWORK_TEMPLATES_TABLE_NAME, name)
+}
+
+// NetworkTemplateFromNetwork - captures the settings, custom DNS entries and valid enrollment keys of a network
+func NetworkTemplateFromNetwork(netID string, request models.NetworkTemplateRequest) (models.NetworkTemplate, error) {
+	network, err := GetParentNetwork(netID)
+	if err != nil {
+		return models.NetworkTemplate{}, err
+	}
+	template := models.NetworkTemplate{
+		Name:           request.Name,
+		Description:    request.Description,
+		Network:        network,
+		DNS:            []models.DNSEntry{},
+		EnrollmentKeys: []models.EnrollmentKeyTemplate{},
+	}
+	dns, err := GetCustomDNS(netID)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return template, err
+	}
+	template.DNS = append(template.DNS, dns...)
+	keys, err := GetAllEnrollmentKeys()
+	if err != nil {
+		return template, err
+	}
+	for _, key := range keys {
+		if !key.IsValid() || !StringSliceContains(key.Networks, netID) {
+			continue
+		}
+		keyTemplate := models.EnrollmentKeyTemplate{
+			UsesRemaining:    key.UsesRemaining,
+			Unlimited:        key.Unlimited,
+			Tags:             key.Tags,
+			RequiresApproval: key.RequiresApproval,
+			Ephemeral:        key.Ephemeral,
+		}
+		if key.Type == models.TimeExpiration {
+			keyTemplate.Validity = int64(time.Until(key.Expiration).Seconds())
+		}
+		template.EnrollmentKeys = append(template.EnrollmentKeys, keyTemplate)
+	}
+	return template, nil
+}
+
+// CreateNetworkFromTemplate - creates a network with the settings of a template, the name and address ranges come
+// from the given network, DNS entries of the template move into the new ranges at the same offset
+func CreateNetworkFromTemplate(template models.NetworkTemplate, request models.Network) (models.Network, error) {
+	network := template.Network
+	network.NetID = request.NetID
+	network.AddressRange = request.AddressRange
+	network.AddressRange6 = request.AddressRange6
+	network.SecondaryRanges = request.SecondaryRanges
+	// the ip families are derived from the new ranges
+	network.IsIPv4 = ""
+	network.IsIPv6 = ""
+	network.DefaultInterface = request.DefaultInterface
+	// the DNS entries are checked before the network is created so a bad template does not leave a half set up network
+	entries, err := templateDNSEntries(template, network)
+	if err != nil {
+		return models.Network{}, err
+	}
+	network, err = CreateNetwork(network)
+	if err != nil {
+		return network, err
+	}
+	// once the network exists anything failing removes it again, along with its DNS entries and enrollment keys
+	keys := []string{}
+	rollback := func(cause error) (models.Network, error) {
+		for _, value := range keys {
+			if err := DeleteEnrollmentKey(value); err != nil {
+				logger.Log(0, "failed to delete enrollment key of network", network.NetID, "created from template", template.Name, err.Error())
+			}
+		}
+		if err := DeleteNetwork(network.NetID); err != nil {
+			logger.Log(0, "failed to delete network", network.NetID, "created from template", template.Name, err.Error())
+		}
+		return models.Network{}, cause
+	}
+	if entries, err = templateDNSEntries(template, network); err != nil {
+		return rollback(err)
+	}
+	for _, entry := range entries {
+		if _, err := CreateDNS(entry); err != nil {
+			return rollback(fmt.Errorf("dns entry %s: %w", entry.Name, err))
+		}
+	}
+	for _, key := range template.EnrollmentKeys {
+		request := models.APIEnrollmentKey{
+			UsesRemaining:    key.UsesRemaining,
+			Networks:         []string{network.NetID},
+			Unlimited:        key.Unlimited,
+			Tags:             key.Tags,
+			RequiresApproval: key.RequiresApproval,
+			Ephemeral:        key.Ephemeral,
+		}
+		if key.Validity > 0 {
+			request.Expiration = time.Now().Add(time.Duration(key.Validity) * time.Second).Unix()
+		}
+		newKey, err := CreateEnrollmentKey(request)
+		if err != nil {
+			return rollback(err)
+		}
+		keys = append(keys, newKey.Value)
+	}
+	logger.Log(1, "created network", network.NetID, "from template", template.Name)
+	return network, nil
+}
+
+// CloneNetwork - creates a network with the settings, custom DNS entries and enrollment keys of an existing one
+func CloneNetwork(netID string, request models.NetworkCloneRequest) (models.Network, error) {
+	template, err := NetworkTemplateFromNetwork(netID, models.NetworkTemplateRequest{Name: netID})
+	if err != nil {
+		return models.Network{}, err
+	}
+	return CreateNetworkFromTemplate(template, models.Network{
+		NetID:         request.NetID,
+		AddressRange:  request.AddressRange,
+		AddressRange6: request.AddressRange6,
+	})
+}
+
+// templateDNSEntries - returns the DNS entries of a template for a network, addresses within the ranges of the
+// template keep their offset in the ranges of the network, other addresses are kept as they are
+func templateDNSEntries(template models.NetworkTemplate, network models.Network) ([]models.DNSEntry, error) {
+	entries := []models.DNSEntry{}
+	for _, entry := range template.DNS {
+		entry.Network = network.NetID
+		var err error
+		if entry.Address, err = templateAddress(entry.Address, template.Network.AddressRange, network.AddressRange); err != nil {
+			return nil, fmt.Errorf("dns entry %s: %w", entry.Name, err)
+		}
+		if entry.Address6, err = templateAddress(entry.Address6, template.Network.AddressRange6, network.AddressRange6); err != nil {
+			return nil, fmt.Errorf("dns entry %s: %w", entry.Name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// templateAddress - moves an address from the range of a template to the same offset in the range of a network
+func templateAddress(address, from, to string) (string, error) {
+	if address == "" || from == "" {
+		return address, nil
+	}
+	if to == "" || to == models.ULAAddressRange6 {
+		// the network has no range of this family, or its range is only generated when the network is created
+		if _, cidr, err := net.ParseCIDR(from); to == "" && err == nil && cidr.Contains(net.ParseIP(address)) {
+			return "", fmt.Errorf("%s needs an address range like %s", address, from)
+		}
+		return address, nil
+	}
+	move, err := newRangeMove(from, to)
+	if err != nil {
+		return "", err
+	}
+	moved, _, err := move.translate(address)
+	return moved, err
+}
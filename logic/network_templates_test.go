@@ -0,0 +1,109 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkTemplates(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		for _, network := range []string{"tmplsrc", "tmplclone", "tmplnew", "tmplv6", "tmplbad"} {
+			DeleteNetwork(network)
+		}
+		DeleteNetworkTemplate("site")
+		DeleteUser("tmpladmin")
+		keys, _ := GetAllEnrollmentKeys()
+		for _, key := range keys {
+			DeleteEnrollmentKey(key.Value)
+		}
+	})
+	// networks are only fully created once there is a user to add to them
+	assert.Nil(t, CreateUser(&models.User{UserName: "tmpladmin", Password: "password123", IsAdmin: true}))
+	CreateNetwork(models.Network{NetID: "tmplsrc", AddressRange: "10.60.0.0/24", DefaultACL: "no", DefaultKeepalive: 30})
+	_, err := CreateDNS(models.DNSEntry{Name: "printer", Network: "tmplsrc", Address: "10.60.0.50"})
+	assert.Nil(t, err)
+	_, err = CreateDNS(models.DNSEntry{Name: "resolver", Network: "tmplsrc", Address: "8.8.8.8"})
+	assert.Nil(t, err)
+	_, err = CreateEnrollmentKey(models.APIEnrollmentKey{UsesRemaining: 5, Networks: []string{"tmplsrc"}, Tags: []string{"site"}})
+	assert.Nil(t, err)
+
+	t.Run("Clone", func(t *testing.T) {
+		CloneNetwork("tmplsrc", models.NetworkCloneRequest{NetID: "tmplclone", AddressRange: "10.61.0.0/24"})
+		network, err := GetNetwork("tmplclone")
+		assert.Nil(t, err)
+		assert.Equal(t, "10.61.0.0/24", network.AddressRange)
+		assert.Equal(t, "no", network.DefaultACL)
+		assert.Equal(t, int32(30), network.DefaultKeepalive)
+		assert.Equal(t, "nm-tmplclone", network.DefaultInterface)
+		dns, err := GetCustomDNS("tmplclone")
+		assert.Nil(t, err)
+		addresses := map[string]string{}
+		for _, entry := range dns {
+			addresses[entry.Name] = entry.Address
+		}
+		assert.Equal(t, map[string]string{"printer": "10.61.0.50", "resolver": "8.8.8.8"}, addresses)
+		keys, err := GetAllEnrollmentKeys()
+		assert.Nil(t, err)
+		cloned := 0
+		for _, key := range keys {
+			if StringSliceContains(key.Networks, "tmplclone") {
+				cloned++
+				assert.Equal(t, 5, key.UsesRemaining)
+				assert.Equal(t, []string{"site"}, key.Tags)
+			}
+		}
+		assert.Equal(t, 1, cloned)
+	})
+	t.Run("FromTemplate", func(t *testing.T) {
+		template, err := NetworkTemplateFromNetwork("tmplsrc", models.NetworkTemplateRequest{Name: "site"})
+		assert.Nil(t, err)
+		_, err = CreateNetworkTemplate(template)
+		assert.Nil(t, err)
+		template, err = GetNetworkTemplate("site")
+		assert.Nil(t, err)
+		assert.Empty(t, template.Network.NetID)
+		CreateNetworkFromTemplate(template, models.Network{NetID: "tmplnew", AddressRange: "10.62.0.0/16"})
+		network, err := GetNetwork("tmplnew")
+		assert.Nil(t, err)
+		assert.Equal(t, "no", network.DefaultACL)
+		dns, err := GetCustomDNS("tmplnew")
+		assert.Nil(t, err)
+		assert.Contains(t, dns, models.DNSEntry{Name: "printer", Network: "tmplnew", Address: "10.62.0.50"})
+		// the printer entry needs an ipv4 range
+		_, err = CreateNetworkFromTemplate(template, models.Network{NetID: "tmplv6", AddressRange6: "fd62::/64"})
+		assert.NotNil(t, err)
+		_, err = GetNetwork("tmplv6")
+		assert.NotNil(t, err)
+	})
+	t.Run("RollbackOnError", func(t *testing.T) {
+		// the second enrollment key is invalid, so the network is removed along with what was already created
+		template := models.NetworkTemplate{
+			Name:           "badkey",
+			Network:        models.Network{AddressRange: "10.63.0.0/24"},
+			DNS:            []models.DNSEntry{{Name: "resolver", Address: "8.8.8.8"}},
+			EnrollmentKeys: []models.EnrollmentKeyTemplate{{UsesRemaining: 5}, {}},
+		}
+		_, err := CreateNetworkFromTemplate(template, models.Network{NetID: "tmplbad", AddressRange: "10.63.0.0/24"})
+		assert.NotNil(t, err)
+		_, err = GetNetwork("tmplbad")
+		assert.True(t, database.IsEmptyRecord(err))
+		dns, _ := GetCustomDNS("tmplbad")
+		assert.Empty(t, dns)
+		keys, err := GetAllEnrollmentKeys()
+		assert.Nil(t, err)
+		for _, key := range keys {
+			assert.NotContains(t, key.Networks, "tmplbad")
+		}
+	})
+	t.Run("InvalidTemplate", func(t *testing.T) {
+		_, err := CreateNetworkTemplate(models.NetworkTemplate{Name: "bad/name"})
+		assert.NotNil(t, err)
+		_, err = CreateNetworkTemplate(models.NetworkTemplate{Name: "site2", EnrollmentKeys: []models.EnrollmentKeyTemplate{{}}})
+		assert.NotNil(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package logic
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,7 +10,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/c-robinson/iplib"
 	validator "github.com/go-playground/validator/v10"
 	"github.com/gravitl/netmaker/database"
 	"github.com/gravitl/netmaker/logger"
@@ -43,25 +43,75 @@ func GetNetworks() ([]models.Network, error) {
 
 // DeleteNetwork - deletes a network
 func DeleteNetwork(network string) error {
-	// remove ACL for network
-	err := nodeacls.DeleteACLContainer(nodeacls.NetworkID(network))
-	if err != nil {
-		logger.Log(1, "failed to remove the node acls during network delete for network,", network)
+	tx := database.BeginTx()
+	if err := deleteNetworkTx(tx, network, nil); err != nil {
+		return err
 	}
+	return tx.Commit()
+}
+
+// deleteNetworkTx - queues the deletion of a network together with its ACLs, network users and custom DNS entries,
+// the deleted records are kept in trash when given
+func deleteNetworkTx(tx *database.Tx, network string, trash *trashEntry) error {
 	nodeCount, err := GetNetworkNonServerNodeCount(network)
-	if nodeCount == 0 || database.IsEmptyRecord(err) {
-		// delete server nodes first then db records
-		if err = pro.RemoveAllNetworkUsers(network); err != nil {
-			logger.Log(0, "failed to remove network users on network delete for network", network, err.Error())
+	if nodeCount != 0 && !database.IsEmptyRecord(err) {
+		return errors.New("node check failed. All nodes must be deleted before deleting network")
+	}
+	customDNS, err := GetCustomDNS(network)
+	if err != nil && !database.IsEmptyRecord(err) {
+		logger.Log(0, "failed to fetch custom dns entries on network delete for network", network, err.Error())
+	}
+	if trash != nil {
+		if err := trash.keep(database.NETWORKS_TABLE_NAME, network); err != nil {
+			return err
+		}
+		if err := trash.keep(database.NODE_ACLS_TABLE_NAME, network); err != nil {
+			return err
+		}
+		if err := trash.keep(database.NETWORK_USER_TABLE_NAME, network); err != nil {
+			return err
 		}
-		return database.DeleteRecord(database.NETWORKS_TABLE_NAME, network)
+		if err := trash.keep(database.IPAM_TABLE_NAME, network); err != nil {
+			return err
+		}
+	}
+	// peerings are not kept in trash, the other network may be peered elsewhere by the time this one is restored
+	if err := deleteNetworkPeeringsTx(tx, network, ""); err != nil {
+		return err
 	}
-	return errors.New("node check failed. All nodes must be deleted before deleting network")
+	nodeacls.DeleteACLContainerTx(tx, nodeacls.NetworkID(network))
+	pro.RemoveAllNetworkUsersTx(tx, network)
+	for _, entry := range customDNS {
+		if key, err := GetRecordKey(entry.Name, entry.Network); err == nil {
+			if trash != nil {
+				if err := trash.keep(database.DNS_TABLE_NAME, key); err != nil {
+					return err
+				}
+			}
+			tx.Delete(database.DNS_TABLE_NAME, key)
+		}
+	}
+	tx.Delete(database.IPAM_TABLE_NAME, network)
+	tx.Delete(database.NETWORKS_TABLE_NAME, network)
+	tx.OnCommit(func() {
+		resetAddressPools(network)
+	})
+	return nil
 }
 
 // CreateNetwork - creates a network in database
 func CreateNetwork(network models.Network) (models.Network, error) {
 
+	if network.AddressRange6 == models.ULAAddressRange6 {
+		// the generated range is only unique if no other network is created or changes its ranges until this one is stored
+		addressLock.Lock()
+		defer addressLock.Unlock()
+		ulaRange, err := generateULARange()
+		if err != nil {
+			return models.Network{}, err
+		}
+		network.AddressRange6 = ulaRange
+	}
 	if network.AddressRange != "" {
 		normalizedRange, err := NormalizeCIDR(network.AddressRange)
 		if err != nil {
@@ -76,6 +126,17 @@ func CreateNetwork(network models.Network) (models.Network, error) {
 		}
 		network.AddressRange6 = normalizedRange
 	}
+	secondaryRanges, err := normalizeSecondaryRanges(network.SecondaryRanges)
+	if err != nil {
+		return models.Network{}, err
+	}
+	network.SecondaryRanges = secondaryRanges
+	if network.IsIPv4 == "" && network.AddressRange == "" {
+		network.IsIPv4 = "no"
+	}
+	if network.IsIPv6 == "" && network.AddressRange6 != "" {
+		network.IsIPv6 = "yes"
+	}
 
 	network.SetDefaults()
 	network.SetNodesLastModified()
@@ -87,7 +148,7 @@ func CreateNetwork(network models.Network) (models.Network, error) {
 		network.ProSettings.AllowedGroups = []string{pro.DEFAULT_ALLOWED_GROUPS}
 	}
 
-	err := ValidateNetwork(&network, false)
+	err = ValidateNetwork(&network, false)
 	if err != nil {
 		//logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return models.Network{}, err
@@ -114,6 +175,47 @@ func CreateNetwork(network models.Network) (models.Network, error) {
 	return network, nil
 }
 
+// generateULARange - returns the first unique local /64 (RFC 4193) that no network uses yet, the 40 bit global id
+// is derived from the server uuid so all generated ranges of a server share one /48, caller must hold addressLock
+// until the network using the range is stored
+func generateULARange() (string, error) {
+	telemetry, err := fetchTelemetryRecord()
+	if err != nil {
+		return "", err
+	}
+	networks, err := GetNetworks()
+	if err != nil && !database.IsEmptyRecord(err) {
+		return "", err
+	}
+	used := []*net.IPNet{}
+	for _, network := range networks {
+		for _, addressRange := range network.AllAddressRanges() {
+			if _, cidr, err := net.ParseCIDR(addressRange); err == nil {
+				used = append(used, cidr)
+			}
+		}
+	}
+	globalID := sha256.Sum256([]byte(telemetry.UUID))
+	prefix := make(net.IP, net.IPv6len)
+	prefix[0] = 0xfd
+	copy(prefix[1:6], globalID[:5])
+	for subnet := 0; subnet <= 0xffff; subnet++ {
+		prefix[6], prefix[7] = byte(subnet>>8), byte(subnet)
+		candidate := net.IPNet{IP: prefix, Mask: net.CIDRMask(64, 128)}
+		free := true
+		for _, cidr := range used {
+			if cidr.Contains(candidate.IP) || candidate.Contains(cidr.IP) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return candidate.String(), nil
+		}
+	}
+	return "", errors.New("no unique local ipv6 range left for the server")
+}
+
 // GetNetworkNonServerNodeCount - get number of network non server nodes
 func GetNetworkNonServerNodeCount(networkName string) (int, error) {
 	nodes, err := GetNetworkNodes(networkName)
@@ -166,29 +268,7 @@ func UniqueAddress(networkName string, reverse bool) (net.IP, error) {
 		logger.Log(0, "UniqueAddress encountered  an error")
 		return add, err
 	}
-	net4 := iplib.Net4FromStr(network.AddressRange)
-	newAddrs := net4.FirstAddress()
-
-	if reverse {
-		newAddrs = net4.LastAddress()
-	}
-
-	for {
-		if IsIPUnique(networkName, newAddrs.String(), database.NODES_TABLE_NAME, false) &&
-			IsIPUnique(networkName, newAddrs.String(), database.EXT_CLIENT_TABLE_NAME, false) {
-			return newAddrs, nil
-		}
-		if reverse {
-			newAddrs, err = net4.PreviousIP(newAddrs)
-		} else {
-			newAddrs, err = net4.NextIP(newAddrs)
-		}
-		if err != nil {
-			break
-		}
-	}
-
-	return add, errors.New("ERROR: No unique addresses available. Check network subnet")
+	return allocateAddress(networkName, false, reverse)
 }
 
 // IsIPUnique - checks if an IP is unique
@@ -252,32 +332,7 @@ func UniqueAddress6(networkName string, reverse bool) (net.IP, error) {
 	if _, _, err := net.ParseCIDR(network.AddressRange6); err != nil {
 		return add, err
 	}
-	net6 := iplib.Net6FromStr(network.AddressRange6)
-
-	newAddrs, err := net6.NextIP(net6.FirstAddress())
-	if reverse {
-		newAddrs, err = net6.PreviousIP(net6.LastAddress())
-	}
-	if err != nil {
-		return add, err
-	}
-
-	for {
-		if IsIPUnique(networkName, newAddrs.String(), database.NODES_TABLE_NAME, true) &&
-			IsIPUnique(networkName, newAddrs.String(), database.EXT_CLIENT_TABLE_NAME, true) {
-			return newAddrs, nil
-		}
-		if reverse {
-			newAddrs, err = net6.PreviousIP(newAddrs)
-		} else {
-			newAddrs, err = net6.NextIP(newAddrs)
-		}
-		if err != nil {
-			break
-		}
-	}
-
-	return add, errors.New("ERROR: No unique IPv6 addresses available. Check network subnet")
+	return allocateAddress(networkName, true, reverse)
 }
 
 // IsNetworkNameUnique - checks to see if any other networks have the same name (id)
@@ -303,6 +358,12 @@ func IsNetworkNameUnique(network *models.Network) (bool, error) {
 
 // UpdateNetwork - updates a network with another network's fields
 func UpdateNetwork(currentNetwork *models.Network, newNetwork *models.Network) (bool, bool, bool, []string, []string, error) {
+	return UpdateNetworkAtRevision(currentNetwork, newNetwork, nil)
+}
+
+// UpdateNetworkAtRevision - updates a network only if its record is still at the given revision, a nil revision always
+// updates
+func UpdateNetworkAtRevision(currentNetwork *models.Network, newNetwork *models.Network, revision *uint64) (bool, bool, bool, []string, []string, error) {
 	if err := ValidateNetwork(newNetwork, true); err != nil {
 		return false, false, false, nil, nil, err
 	}
@@ -319,7 +380,14 @@ func UpdateNetwork(currentNetwork *models.Network, newNetwork *models.Network) (
 			return false, false, false, nil, nil, err
 		}
 		newNetwork.SetNetworkLastModified()
-		err = database.Insert(newNetwork.NetID, string(data), database.NETWORKS_TABLE_NAME)
+		tx := database.BeginTx()
+		expectRevision(tx, database.NETWORKS_TABLE_NAME, newNetwork.NetID, revision)
+		if err = tx.Insert(newNetwork.NetID, string(data), database.NETWORKS_TABLE_NAME); err == nil {
+			err = tx.Commit()
+		}
+		if hasrangeupdate4 || hasrangeupdate6 {
+			resetAddressPools(newNetwork.NetID)
+		}
 		return hasrangeupdate4, hasrangeupdate6, hasholepunchupdate, groupDelta, userDelta, err
 	}
 	// copy values
@@ -383,10 +451,145 @@ func ValidateNetwork(network *models.Network, isUpdate bool) error {
 			return fmt.Errorf("invalid node/client limit provided")
 		}
 	}
+	if err == nil {
+		err = validateAddressRanges(network)
+	}
 
 	return err
 }
 
+// normalizeSecondaryRanges - normalizes the secondary ranges of a network and drops duplicates
+func normalizeSecondaryRanges(ranges []string) ([]string, error) {
+	normalized := []string{}
+	for _, addressRange := range ranges {
+		normalizedRange, err := NormalizeCIDR(addressRange)
+		if err != nil {
+			return nil, err
+		}
+		if !StringSliceContains(normalized, normalizedRange) {
+			normalized = append(normalized, normalizedRange)
+		}
+	}
+	return normalized, nil
+}
+
+// validateAddressRanges - makes sure every secondary range of a network has a primary range of its ip family and
+// that no two ranges of the network overlap
+func validateAddressRanges(network *models.Network) error {
+	for _, secondaryRange := range network.SecondaryRanges {
+		ip, _, err := net.ParseCIDR(secondaryRange)
+		if err != nil {
+			return err
+		}
+		if ip.To4() != nil && network.AddressRange == "" {
+			return fmt.Errorf("secondary range %s needs an ipv4 address range", secondaryRange)
+		}
+		if ip.To4() == nil && network.AddressRange6 == "" {
+			return fmt.Errorf("secondary range %s needs an ipv6 address range", secondaryRange)
+		}
+	}
+	ranges := network.AllAddressRanges()
+	for i := range ranges {
+		_, a, err := net.ParseCIDR(ranges[i])
+		if err != nil {
+			return err
+		}
+		for j := i + 1; j < len(ranges); j++ {
+			_, b, err := net.ParseCIDR(ranges[j])
+			if err != nil {
+				return err
+			}
+			if a.Contains(b.IP) || b.Contains(a.IP) {
+				return fmt.Errorf("address ranges %s and %s overlap", ranges[i], ranges[j])
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateNetworkRanges - replaces the secondary ranges of a network, a range can only be removed once no node, ext
+// client or reservation uses it, returns the nodes of the network which now carry the new ranges
+func UpdateNetworkRanges(networkName string, ranges []string, revision *uint64) (models.Network, []models.Node, error) {
+	addressLock.Lock()
+	defer addressLock.Unlock()
+	network, err := GetParentNetwork(networkName)
+	if err != nil {
+		return network, nil, err
+	}
+	if network.SecondaryRanges, err = normalizeSecondaryRanges(ranges); err != nil {
+		return network, nil, err
+	}
+	if err = validateAddressRanges(&network); err != nil {
+		return network, nil, err
+	}
+	nodes, err := GetNetworkNodes(networkName)
+	if err != nil {
+		return network, nil, err
+	}
+	clients, err := GetNetworkExtClients(networkName)
+	if err != nil {
+		return network, nil, err
+	}
+	reservations, err := GetAddressReservations(networkName)
+	if err != nil {
+		return network, nil, err
+	}
+	inUse := func(address net.IP, user string) error {
+		if address == nil {
+			return nil
+		}
+		if _, ok := networkRangeOf(&network, address); !ok {
+			return fmt.Errorf("address %s of %s is outside of the remaining ranges of network %s", address, user, networkName)
+		}
+		return nil
+	}
+	for _, node := range nodes {
+		if err = inUse(node.Address.IP, "node "+node.ID.String()); err != nil {
+			return network, nil, err
+		}
+		if err = inUse(node.Address6.IP, "node "+node.ID.String()); err != nil {
+			return network, nil, err
+		}
+	}
+	for _, client := range clients {
+		if err = inUse(net.ParseIP(client.Address), "ext client "+client.ClientID); err != nil {
+			return network, nil, err
+		}
+		if err = inUse(net.ParseIP(client.Address6), "ext client "+client.ClientID); err != nil {
+			return network, nil, err
+		}
+	}
+	for _, reservation := range reservations {
+		if err = inUse(net.ParseIP(reservation.Address), "a reservation"); err != nil {
+			return network, nil, err
+		}
+	}
+
+	network.SetNetworkLastModified()
+	data, err := json.Marshal(&network)
+	if err != nil {
+		return network, nil, err
+	}
+	tx := database.BeginTx()
+	expectRevision(tx, database.NETWORKS_TABLE_NAME, network.NetID, revision)
+	if err = tx.Insert(network.NetID, string(data), database.NETWORKS_TABLE_NAME); err != nil {
+		return network, nil, err
+	}
+	for i := range nodes {
+		nodes[i].NetworkRanges = network.SecondaryNetworks()
+		if err = upsertNodeTx(tx, &nodes[i]); err != nil {
+			return network, nil, err
+		}
+	}
+	tx.OnCommit(func() {
+		resetAddressPools(networkName)
+	})
+	if err = tx.Commit(); err != nil {
+		return network, nil, err
+	}
+	return network, nodes, nil
+}
+
 // ParseNetwork - parses a network into a model
 func ParseNetwork(value string) (models.Network, error) {
 	var network models.Network
@@ -406,6 +609,36 @@ func SaveNetwork(network *models.Network) error {
 	return nil
 }
 
+// updateNetworkAtRevision - applies a change to a network and saves it only if the network is still at the given
+// revision, with a nil revision it must not have been modified since it was read here
+func updateNetworkAtRevision(networkName string, revision *uint64, change func(*models.Network) error) (models.Network, error) {
+	if revision == nil {
+		current, err := database.GetRevision(database.NETWORKS_TABLE_NAME, networkName)
+		if err != nil {
+			return models.Network{}, err
+		}
+		revision = &current
+	}
+	network, err := GetNetwork(networkName)
+	if err != nil {
+		return network, err
+	}
+	if err = change(&network); err != nil {
+		return network, err
+	}
+	network.SetNetworkLastModified()
+	data, err := json.Marshal(&network)
+	if err != nil {
+		return network, err
+	}
+	tx := database.BeginTx()
+	tx.ExpectRevision(database.NETWORKS_TABLE_NAME, networkName, *revision)
+	if err = tx.Insert(network.NetID, string(data), database.NETWORKS_TABLE_NAME); err != nil {
+		return network, err
+	}
+	return network, tx.Commit()
+}
+
 // NetworkExists - check if network exists
 func NetworkExists(name string) (bool, error) {
 
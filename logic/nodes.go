@@ -54,6 +54,13 @@ func storeNodeInCache(node models.Node) {
 	nodeCacheMutex.Unlock()
 }
 
+// nodeCacheLoaded - a non empty cache is served as the full list of nodes
+func nodeCacheLoaded() bool {
+	nodeCacheMutex.RLock()
+	defer nodeCacheMutex.RUnlock()
+	return len(nodesCacheMap) > 0
+}
+
 func loadNodesIntoCache(nMap map[string]models.Node) {
 	nodeCacheMutex.Lock()
 	nodesCacheMap = nMap
@@ -135,14 +142,38 @@ func UpsertNode(newNode *models.Node) error {
 		return err
 	}
 	storeNodeInCache(*newNode)
+	markAddressesUsed(newNode.Network, newNode.Address.IP, newNode.Address6.IP)
+	return nil
+}
+
+// upsertNodeTx - queues the upsert of a node in given db transaction, the cache is updated on commit
+func upsertNodeTx(tx *database.Tx, newNode *models.Node) error {
+	newNode.SetLastModified()
+	data, err := json.Marshal(newNode)
+	if err != nil {
+		return err
+	}
+	if err = tx.Insert(newNode.ID.String(), string(data), database.NODES_TABLE_NAME); err != nil {
+		return err
+	}
+	node := *newNode
+	tx.OnCommit(func() {
+		storeNodeInCache(node)
+		markAddressesUsed(node.Network, node.Address.IP, node.Address6.IP)
+	})
 	return nil
 }
 
 // UpdateNode - takes a node and updates another node with it's values
 func UpdateNode(currentNode *models.Node, newNode *models.Node) error {
+	return UpdateNodeAtRevision(currentNode, newNode, nil)
+}
+
+// UpdateNodeAtRevision - updates a node only if its record is still at the given revision, a nil revision always updates
+func UpdateNodeAtRevision(currentNode *models.Node, newNode *models.Node, revision *uint64) error {
 	if newNode.Address.IP.String() != currentNode.Address.IP.String() {
 		if network, err := GetParentNetwork(newNode.Network); err == nil {
-			if !IsAddressInCIDR(newNode.Address.IP, network.AddressRange) {
+			if _, ok := networkRangeOf(&network, newNode.Address.IP); !ok {
 				return fmt.Errorf("invalid address provided; out of network range for node %s", newNode.ID)
 			}
 		}
@@ -163,17 +194,25 @@ func UpdateNode(currentNode *models.Node, newNode *models.Node) error {
 			}
 		}
 
-		newNode.SetLastModified()
-		if data, err := json.Marshal(newNode); err != nil {
+		tx := database.BeginTx()
+		expectRevision(tx, database.NODES_TABLE_NAME, currentNode.ID.String(), revision)
+		if err := upsertNodeTx(tx, newNode); err != nil {
 			return err
-		} else {
-			err = database.Insert(newNode.ID.String(), string(data), database.NODES_TABLE_NAME)
-			if err != nil {
+		}
+		// a replaced address cools down from the moment the node stops using it
+		released := []net.IP{}
+		if currentNode.Address.IP != nil && !currentNode.Address.IP.Equal(newNode.Address.IP) {
+			released = append(released, currentNode.Address.IP)
+		}
+		if currentNode.Address6.IP != nil && !currentNode.Address6.IP.Equal(newNode.Address6.IP) {
+			released = append(released, currentNode.Address6.IP)
+		}
+		if len(released) > 0 {
+			if err := releaseAddressesTx(tx, currentNode.Network, released...); err != nil {
 				return err
 			}
-			storeNodeInCache(*newNode)
-			return nil
 		}
+		return tx.Commit()
 	}
 
 	return fmt.Errorf("failed to update node " + currentNode.ID.String() + ", cannot change ID.")
@@ -198,7 +237,12 @@ func DeleteNode(node *models.Node, purge bool) error {
 	host, err := GetHost(node.HostID.String())
 	if err != nil {
 		logger.Log(1, "no host found for node", node.ID.String(), "deleting..")
-		if delErr := deleteNodeByID(node); delErr != nil {
+		tx := database.BeginTx()
+		delErr := deleteNodeByID(tx, node)
+		if delErr == nil {
+			delErr = tx.Commit()
+		}
+		if delErr != nil {
 			logger.Log(0, "failed to delete node", node.ID.String(), delErr.Error())
 		}
 		return err
@@ -215,40 +259,42 @@ func DeleteNode(node *models.Node, purge bool) error {
 	return nil
 }
 
-// deleteNodeByID - deletes a node from database
-func deleteNodeByID(node *models.Node) error {
-	var err error
+// deleteNodeByID - queues the deletion of a node, its ext clients and its ACL in given db transaction
+// caches and dependent records are cleaned up once the transaction is committed
+func deleteNodeByID(tx *database.Tx, node *models.Node) error {
 	var key = node.ID.String()
 	//delete any ext clients as required
 	if node.IsIngressGateway {
-		if err := DeleteGatewayExtClients(node.ID.String(), node.Network); err != nil {
+		if err := deleteGatewayExtClientsTx(tx, node.ID.String(), node.Network); err != nil {
 			logger.Log(0, "failed to deleted ext clients", err.Error())
 		}
 	}
-	if err = database.DeleteRecord(database.NODES_TABLE_NAME, key); err != nil {
-		if !database.IsEmptyRecord(err) {
-			return err
-		}
-	}
-	deleteNodeFromCache(node.ID.String())
-	if servercfg.IsDNSMode() {
-		SetDNS()
-	}
-	if node.OwnerID != "" {
-		err = pro.DissociateNetworkUserNode(node.OwnerID, node.Network, node.ID.String())
-		if err != nil {
-			logger.Log(0, "failed to dissasociate", node.OwnerID, "from node", node.ID.String(), ":", err.Error())
-		}
+	if err := deleteNetworkPeeringsTx(tx, node.Network, node.ID.String()); err != nil {
+		logger.Log(0, "failed to remove the peerings of node", node.ID.String(), err.Error())
 	}
-	_, err = nodeacls.RemoveNodeACL(nodeacls.NetworkID(node.Network), nodeacls.NodeID(node.ID.String()))
-	if err != nil {
+	tx.Delete(database.NODES_TABLE_NAME, key)
+	if _, err := nodeacls.RemoveNodeACLTx(tx, nodeacls.NetworkID(node.Network), nodeacls.NodeID(node.ID.String())); err != nil {
 		// ignoring for now, could hit a nil pointer if delete called twice
 		logger.Log(2, "attempted to remove node ACL for node", node.ID.String())
 	}
-	// removeZombie <- node.ID
-	if err = DeleteMetrics(node.ID.String()); err != nil {
-		logger.Log(1, "unable to remove metrics from DB for node", node.ID.String(), err.Error())
-	}
+	tx.OnCommit(func() {
+		deleteNodeFromCache(node.ID.String())
+		if err := ReleaseAddresses(node.Network, node.Address.IP, node.Address6.IP); err != nil {
+			logger.Log(1, "failed to release addresses of node", node.ID.String(), err.Error())
+		}
+		if servercfg.IsDNSMode() {
+			SetDNS()
+		}
+		if node.OwnerID != "" {
+			if err := pro.DissociateNetworkUserNode(node.OwnerID, node.Network, node.ID.String()); err != nil {
+				logger.Log(0, "failed to dissasociate", node.OwnerID, "from node", node.ID.String(), ":", err.Error())
+			}
+		}
+		// removeZombie <- node.ID
+		if err := DeleteMetrics(node.ID.String()); err != nil {
+			logger.Log(1, "unable to remove metrics from DB for node", node.ID.String(), err.Error())
+		}
+	})
 	return nil
 }
 
@@ -351,6 +397,7 @@ func SetNodeDefaults(node *models.Node) {
 	if err == nil {
 		node.NetworkRange6 = *cidr
 	}
+	node.NetworkRanges = parentNetwork.SecondaryNetworks()
 
 	if node.DefaultACL == "" {
 		node.DefaultACL = parentNetwork.DefaultACL
@@ -458,12 +505,9 @@ func updateProNodeACLS(node *models.Node) error {
 	return nil
 }
 
-// createNode - creates a node in database
-func createNode(node *models.Node) error {
-	// lock because we need unique IPs and having it concurrent makes parallel calls result in same "unique" IPs
-	addressLock.Lock()
-	defer addressLock.Unlock()
-
+// createNode - creates a node, queuing the node and its ACL in given db transaction
+// callers must hold addressLock until the transaction is committed and then call nodeCreated
+func createNode(tx *database.Tx, node *models.Node) error {
 	host, err := GetHost(node.HostID.String())
 	if err != nil {
 		return err
@@ -493,12 +537,12 @@ func createNode(node *models.Node) error {
 
 	if node.Address.IP == nil {
 		if parentNetwork.IsIPv4 == "yes" {
-			if node.Address.IP, err = UniqueAddress(node.Network, false); err != nil {
+			if node.Address.IP, err = hostAddress(node.Network, host, false); err != nil {
 				return err
 			}
-			_, cidr, err := net.ParseCIDR(parentNetwork.AddressRange)
-			if err != nil {
-				return err
+			cidr, ok := networkRangeOf(&parentNetwork, node.Address.IP)
+			if !ok {
+				return fmt.Errorf("address %s is outside of network %s", node.Address.IP, node.Network)
 			}
 			node.Address.Mask = net.CIDRMask(cidr.Mask.Size())
 		}
@@ -507,12 +551,12 @@ func createNode(node *models.Node) error {
 	}
 	if node.Address6.IP == nil {
 		if parentNetwork.IsIPv6 == "yes" {
-			if node.Address6.IP, err = UniqueAddress6(node.Network, false); err != nil {
+			if node.Address6.IP, err = hostAddress(node.Network, host, true); err != nil {
 				return err
 			}
-			_, cidr, err := net.ParseCIDR(parentNetwork.AddressRange6)
-			if err != nil {
-				return err
+			cidr, ok := networkRangeOf(&parentNetwork, node.Address6.IP)
+			if !ok {
+				return fmt.Errorf("address %s is outside of network %s", node.Address6.IP, node.Network)
 			}
 			node.Address6.Mask = net.CIDRMask(cidr.Mask.Size())
 		}
@@ -532,35 +576,33 @@ func createNode(node *models.Node) error {
 	}
 	CheckZombies(node)
 
-	nodebytes, err := json.Marshal(&node)
-	if err != nil {
-		return err
-	}
-	err = database.Insert(node.ID.String(), string(nodebytes), database.NODES_TABLE_NAME)
-	if err != nil {
+	if err = upsertNodeTx(tx, node); err != nil {
 		return err
 	}
-	storeNodeInCache(*node)
-	_, err = nodeacls.CreateNodeACL(nodeacls.NetworkID(node.Network), nodeacls.NodeID(node.ID.String()), defaultACLVal)
+	_, err = nodeacls.CreateNodeACLTx(tx, nodeacls.NetworkID(node.Network), nodeacls.NodeID(node.ID.String()), defaultACLVal)
 	if err != nil {
 		logger.Log(1, "failed to create node ACL for node,", node.ID.String(), "err:", err.Error())
 		return err
 	}
+	return nil
+}
 
-	if err = updateProNodeACLS(node); err != nil {
+// nodeCreated - applies the follow up changes of a node creation once it has been committed
+func nodeCreated(node *models.Node) error {
+	if err := updateProNodeACLS(node); err != nil {
 		logger.Log(1, "failed to apply node level ACLs during creation of node", node.ID.String(), "-", err.Error())
 		return err
 	}
 
-	if err = UpdateMetrics(node.ID.String(), &models.Metrics{Connectivity: make(map[string]models.Metric)}); err != nil {
+	if err := UpdateMetrics(node.ID.String(), &models.Metrics{Connectivity: make(map[string]models.Metric)}); err != nil {
 		logger.Log(1, "failed to initialize metrics for node", node.ID.String(), err.Error())
 	}
 
 	SetNetworkNodesLastModified(node.Network)
 	if servercfg.IsDNSMode() {
-		err = SetDNS()
+		return SetDNS()
 	}
-	return err
+	return nil
 }
 
 // SortApiNodes - Sorts slice of ApiNodes by their ID alphabetically with numbers first
@@ -0,0 +1,246 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic/acls/nodeacls"
+	"github.com/gravitl/netmaker/models"
+)
+
+var (
+	peeringCacheMutex  = &sync.RWMutex{}
+	peeringCache       []models.NetworkPeering
+	peeringCacheLoaded bool
+)
+
+// clearPeeringCache - drops the cached peerings, they are read again on next use
+func clearPeeringCache() {
+	peeringCacheMutex.Lock()
+	peeringCache = nil
+	peeringCacheLoaded = false
+	peeringCacheMutex.Unlock()
+}
+
+// GetNetworkPeerings - returns all network peerings sorted by creation time
+func GetNetworkPeerings() ([]models.NetworkPeering, error) {
+	peeringCacheMutex.RLock()
+	if peeringCacheLoaded {
+		defer peeringCacheMutex.RUnlock()
+		return append([]models.NetworkPeering{}, peeringCache...), nil
+	}
+	peeringCacheMutex.RUnlock()
+	peerings := []models.NetworkPeering{}
+	records, err := database.FetchRecords(database.NETWORK_PEERINGS_TABLE_NAME)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return peerings, err
+	}
+	for _, value := range records {
+		var peering models.NetworkPeering
+		if err := json.Unmarshal([]byte(value), &peering); err != nil {
+			continue
+		}
+		peerings = append(peerings, peering)
+	}
+	sort.Slice(peerings, func(i, j int) bool {
+		return peerings[i].CreatedAt < peerings[j].CreatedAt
+	})
+	peeringCacheMutex.Lock()
+	peeringCache = peerings
+	peeringCacheLoaded = true
+	peeringCacheMutex.Unlock()
+	return append([]models.NetworkPeering{}, peerings...), nil
+}
+
+// GetNetworkPeering - returns a network peering by id
+func GetNetworkPeering(id string) (models.NetworkPeering, error) {
+	var peering models.NetworkPeering
+	value, err := database.FetchRecord(database.NETWORK_PEERINGS_TABLE_NAME, id)
+	if err != nil {
+		return peering, err
+	}
+	err = json.Unmarshal([]byte(value), &peering)
+	return peering, err
+}
+
+// CreateNetworkPeering - peers two networks through gateway nodes of the same host, the address ranges of the
+// networks may not overlap and two networks can only be peered once
+func CreateNetworkPeering(request models.NetworkPeeringRequest) (models.NetworkPeering, error) {
+	peering := models.NetworkPeering{
+		ID:        uuid.New().String(),
+		NetworkA:  request.NetworkA,
+		GatewayA:  request.GatewayA,
+		NetworkB:  request.NetworkB,
+		GatewayB:  request.GatewayB,
+		CreatedAt: time.Now().Unix(),
+	}
+	if peering.NetworkA == peering.NetworkB {
+		return peering, errors.New("a network can not be peered with itself")
+	}
+	networkA, err := GetParentNetwork(peering.NetworkA)
+	if err != nil {
+		return peering, fmt.Errorf("network %s: %w", peering.NetworkA, err)
+	}
+	networkB, err := GetParentNetwork(peering.NetworkB)
+	if err != nil {
+		return peering, fmt.Errorf("network %s: %w", peering.NetworkB, err)
+	}
+	gatewayA, err := peeringGateway(peering.GatewayA, peering.NetworkA)
+	if err != nil {
+		return peering, err
+	}
+	gatewayB, err := peeringGateway(peering.GatewayB, peering.NetworkB)
+	if err != nil {
+		return peering, err
+	}
+	if gatewayA.HostID != gatewayB.HostID {
+		return peering, errors.New("the gateway nodes of a peering must belong to the same host")
+	}
+	if err := rangesOverlap(networkA.AllAddressRanges(), networkB.AllAddressRanges()); err != nil {
+		return peering, err
+	}
+	peerings, err := GetNetworkPeerings()
+	if err != nil {
+		return peering, err
+	}
+	for _, existing := range peerings {
+		if _, remote := existing.Gateway(peering.NetworkA); remote == peering.NetworkB {
+			return peering, fmt.Errorf("networks %s and %s are already peered by %s", peering.NetworkA, peering.NetworkB, existing.ID)
+		}
+	}
+	data, err := json.Marshal(&peering)
+	if err != nil {
+		return peering, err
+	}
+	if err := database.Insert(peering.ID, string(data), database.NETWORK_PEERINGS_TABLE_NAME); err != nil {
+		return peering, err
+	}
+	clearPeeringCache()
+	logger.Log(1, "peered network", peering.NetworkA, "with", peering.NetworkB, "through host", gatewayA.HostID.String())
+	return peering, nil
+}
+
+// DeleteNetworkPeering - removes a network peering, the routes between the networks go away with the next peer update
+func DeleteNetworkPeering(id string) error {
+	if _, err := GetNetworkPeering(id); err != nil {
+		return err
+	}
+	if err := database.DeleteRecord(database.NETWORK_PEERINGS_TABLE_NAME, id); err != nil {
+		return err
+	}
+	clearPeeringCache()
+	return nil
+}
+
+// deleteNetworkPeeringsTx - queues the deletion of the peerings of a network, or of the peerings that use the given
+// node as a gateway when nodeID is set
+func deleteNetworkPeeringsTx(tx *database.Tx, network, nodeID string) error {
+	peerings, err := GetNetworkPeerings()
+	if err != nil {
+		return err
+	}
+	for _, peering := range peerings {
+		gateway, remote := peering.Gateway(network)
+		if remote == "" || (nodeID != "" && gateway != nodeID) {
+			continue
+		}
+		logger.Log(1, "removing peering", peering.ID, "of networks", peering.NetworkA, "and", peering.NetworkB)
+		tx.Delete(database.NETWORK_PEERINGS_TABLE_NAME, peering.ID)
+	}
+	tx.OnCommit(clearPeeringCache)
+	return nil
+}
+
+// peeringGateway - returns a node that can act as the gateway of a network in a peering
+func peeringGateway(nodeID, network string) (models.Node, error) {
+	node, err := GetNodeByID(nodeID)
+	if err != nil {
+		return node, fmt.Errorf("gateway %s: %w", nodeID, err)
+	}
+	if node.Network != network {
+		return node, fmt.Errorf("gateway %s is not a node of network %s", nodeID, network)
+	}
+	if node.IsRelayed {
+		return node, fmt.Errorf("gateway %s is relayed and can not route between networks", nodeID)
+	}
+	return node, nil
+}
+
+// rangesOverlap - returns an error if any range of a overlaps with a range of b
+func rangesOverlap(a, b []string) error {
+	for _, rangeA := range a {
+		_, cidrA, err := net.ParseCIDR(rangeA)
+		if err != nil {
+			return err
+		}
+		for _, rangeB := range b {
+			_, cidrB, err := net.ParseCIDR(rangeB)
+			if err != nil {
+				return err
+			}
+			if cidrA.Contains(cidrB.IP) || cidrB.Contains(cidrA.IP) {
+				return fmt.Errorf("address ranges %s and %s overlap", rangeA, rangeB)
+			}
+		}
+	}
+	return nil
+}
+
+// peeringRoutes - returns the address ranges of the remote networks a node is the peering gateway for, keyed by the
+// peering id
+func peeringRoutes(node *models.Node) map[string][]string {
+	routes := map[string][]string{}
+	peerings, err := GetNetworkPeerings()
+	if err != nil {
+		logger.Log(1, "failed to fetch network peerings", err.Error())
+		return routes
+	}
+	for _, peering := range peerings {
+		gateway, remote := peering.Gateway(node.Network)
+		if gateway != node.ID.String() {
+			continue
+		}
+		network, err := GetParentNetwork(remote)
+		if err != nil {
+			logger.Log(1, "failed to fetch peered network", remote, err.Error())
+			continue
+		}
+		routes[peering.ID] = network.AllAddressRanges()
+	}
+	return routes
+}
+
+// getPeeringRanges - returns the ranges a gateway routes into peered networks for a node, only if the ACLs of the
+// network let the node reach the gateway
+func getPeeringRanges(gateway, node *models.Node) []string {
+	ranges := []string{}
+	routes := peeringRoutes(gateway)
+	if len(routes) == 0 ||
+		!nodeacls.AreNodesAllowed(nodeacls.NetworkID(node.Network), nodeacls.NodeID(node.ID.String()), nodeacls.NodeID(gateway.ID.String())) {
+		return ranges
+	}
+	for _, remoteRanges := range routes {
+		ranges = append(ranges, remoteRanges...)
+	}
+	sort.Strings(ranges)
+	return ranges
+}
+
+// getPeeringIPs - returns the parsed ranges a gateway routes into peered networks for a node
+func getPeeringIPs(gateway, node *models.Node) []net.IPNet {
+	ips := []net.IPNet{}
+	for _, addressRange := range getPeeringRanges(gateway, node) {
+		if _, cidr, err := net.ParseCIDR(addressRange); err == nil {
+			ips = append(ips, *cidr)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkPeering(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("peernet1")
+		DeleteNetwork("peernet2")
+		DeleteNetwork("peernet3")
+	})
+	CreateNetwork(models.Network{NetID: "peernet1", AddressRange: "10.81.0.0/24"})
+	CreateNetwork(models.Network{NetID: "peernet2", AddressRange: "10.82.0.0/24"})
+	CreateNetwork(models.Network{NetID: "peernet3", AddressRange: "10.81.0.128/25"})
+	gatewayHost := models.Host{ID: uuid.New(), Name: "peergateway", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&gatewayHost))
+	otherHost := models.Host{ID: uuid.New(), Name: "peerother", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&otherHost))
+	t.Cleanup(func() {
+		RemoveHost(&gatewayHost, true)
+		RemoveHost(&otherHost, true)
+	})
+	gateway1, err := UpdateHostNetwork(&gatewayHost, "peernet1", true)
+	assert.Nil(t, err)
+	gateway2, err := UpdateHostNetwork(&gatewayHost, "peernet2", true)
+	assert.Nil(t, err)
+	gateway3, err := UpdateHostNetwork(&gatewayHost, "peernet3", true)
+	assert.Nil(t, err)
+	other, err := UpdateHostNetwork(&otherHost, "peernet1", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(gateway1, true)
+		DeleteNode(gateway3, true)
+		DeleteNode(other, true)
+	})
+
+	_, err = CreateNetworkPeering(models.NetworkPeeringRequest{
+		NetworkA: "peernet1", GatewayA: gateway1.ID.String(), NetworkB: "peernet1", GatewayB: gateway1.ID.String()})
+	assert.NotNil(t, err)
+	_, err = CreateNetworkPeering(models.NetworkPeeringRequest{
+		NetworkA: "peernet1", GatewayA: other.ID.String(), NetworkB: "peernet2", GatewayB: gateway2.ID.String()})
+	assert.NotNil(t, err, "gateways on different hosts")
+	_, err = CreateNetworkPeering(models.NetworkPeeringRequest{
+		NetworkA: "peernet1", GatewayA: gateway1.ID.String(), NetworkB: "peernet3", GatewayB: gateway3.ID.String()})
+	assert.NotNil(t, err, "overlapping ranges")
+
+	peering, err := CreateNetworkPeering(models.NetworkPeeringRequest{
+		NetworkA: "peernet1", GatewayA: gateway1.ID.String(), NetworkB: "peernet2", GatewayB: gateway2.ID.String()})
+	assert.Nil(t, err)
+	_, err = CreateNetworkPeering(models.NetworkPeeringRequest{
+		NetworkA: "peernet2", GatewayA: gateway2.ID.String(), NetworkB: "peernet1", GatewayB: gateway1.ID.String()})
+	assert.NotNil(t, err, "already peered")
+
+	assert.Equal(t, []string{"10.82.0.0/24"}, getPeeringRanges(gateway1, other))
+	assert.Empty(t, getPeeringRanges(other, gateway1))
+	_, peeredRange, _ := net.ParseCIDR("10.82.0.0/24")
+	assert.Contains(t, GetAllowedIPs(other, gateway1, nil), *peeredRange)
+
+	// the shared host forwards in both directions
+	allNodes, err := GetAllNodes()
+	assert.Nil(t, err)
+	update, err := GetPeerUpdateForHost("", &gatewayHost, allNodes, nil, nil)
+	assert.Nil(t, err)
+	assert.True(t, update.FwUpdate.IsEgressGw)
+	toB, ok := update.FwUpdate.EgressInfo[peering.ID+"/"+gateway1.ID.String()]
+	assert.True(t, ok)
+	assert.Equal(t, "peernet1", toB.EgressGWCfg.NetID)
+	assert.Equal(t, []string{"10.82.0.0/24"}, toB.EgressGWCfg.Ranges)
+	toA, ok := update.FwUpdate.EgressInfo[peering.ID+"/"+gateway2.ID.String()]
+	assert.True(t, ok)
+	assert.Equal(t, "peernet2", toA.EgressGWCfg.NetID)
+	assert.Equal(t, []string{"10.81.0.0/24"}, toA.EgressGWCfg.Ranges)
+
+	// deleting a gateway removes the peering
+	assert.Nil(t, DeleteNode(gateway2, true))
+	_, err = GetNetworkPeering(peering.ID)
+	assert.True(t, database.IsEmptyRecord(err))
+	assert.Empty(t, getPeeringRanges(gateway1, other))
+}
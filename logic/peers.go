@@ -48,7 +48,7 @@ func GetPeerUpdateForHost(network string, host *models.Host, allNodes []models.N
 		if err != nil {
 			continue
 		}
-		if !node.Connected || node.PendingDelete || node.Action == models.NODE_DELETE {
+		if !node.Connected || node.PendingDelete || node.IsPending || node.Quarantined || node.Action == models.NODE_DELETE {
 			continue
 		}
 		if host.OS == models.OS_Types.IoT {
@@ -131,6 +131,12 @@ func GetPeerUpdateForHost(network string, host *models.Host, allNodes []models.N
 					EgressRanges: peer.EgressGatewayRanges,
 				})
 			}
+			if peeringRanges := getPeeringRanges(&peer, &node); len(peeringRanges) > 0 {
+				hostPeerUpdate.EgressRoutes = append(hostPeerUpdate.EgressRoutes, models.EgressNetworkRoutes{
+					NodeAddr:     node.PrimaryAddressIPNet(),
+					EgressRanges: peeringRanges,
+				})
+			}
 			if (node.IsRelayed && node.RelayedBy != peer.ID.String()) || (peer.IsRelayed && peer.RelayedBy != node.ID.String()) {
 				// if node is relayed and peer is not the relay, set remove to true
 				if _, ok := peerIndexMap[peerHost.PublicKey.String()]; ok {
@@ -168,6 +174,8 @@ func GetPeerUpdateForHost(network string, host *models.Host, allNodes []models.N
 			if peer.Action != models.NODE_DELETE &&
 				!peer.PendingDelete &&
 				peer.Connected &&
+				!peer.IsPending &&
+				!peer.Quarantined &&
 				nodeacls.AreNodesAllowed(nodeacls.NetworkID(node.Network), nodeacls.NodeID(node.ID.String()), nodeacls.NodeID(peer.ID.String())) &&
 				(deletedNode == nil || (deletedNode != nil && peer.ID.String() != deletedNode.ID.String())) {
 				peerConfig.AllowedIPs = allowedips // only append allowed IPs if valid connection
@@ -235,6 +243,26 @@ func GetPeerUpdateForHost(network string, host *models.Host, allNodes []models.N
 				EgressGWCfg: node.EgressGatewayRequest,
 			}
 		}
+		// a peering gateway forwards the traffic of peered networks without nat, so each side sees the real addresses,
+		// both gateways of a peering sit on this host so each gets its own entry
+		for peeringID, ranges := range peeringRoutes(&node) {
+			egressID := peeringID + "/" + node.ID.String()
+			hostPeerUpdate.FwUpdate.IsEgressGw = true
+			hostPeerUpdate.FwUpdate.EgressInfo[egressID] = models.EgressInfo{
+				EgressID: egressID,
+				Network:  node.PrimaryNetworkRange(),
+				EgressGwAddr: net.IPNet{
+					IP:   net.ParseIP(node.PrimaryAddress()),
+					Mask: getCIDRMaskFromAddr(node.PrimaryAddress()),
+				},
+				EgressGWCfg: models.EgressGatewayRequest{
+					NodeID:     node.ID.String(),
+					NetID:      node.Network,
+					NatEnabled: "no",
+					Ranges:     ranges,
+				},
+			}
+		}
 	}
 	// == post peer calculations ==
 	// indicate removal if no allowed IPs were calculated
@@ -464,6 +492,7 @@ func getNodeAllowedIPs(peer, node *models.Node) []net.IPNet {
 		egressIPs := getEgressIPs(peer)
 		allowedips = append(allowedips, egressIPs...)
 	}
+	allowedips = append(allowedips, getPeeringIPs(peer, node)...)
 	if peer.IsRelay {
 		for _, relayedNodeID := range peer.RelayedNodes {
 			if node.ID.String() == relayedNodeID {
@@ -0,0 +1,155 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/models"
+	"github.com/hashicorp/go-version"
+	"golang.org/x/exp/slices"
+)
+
+// SetPosturePolicy - sets the requirements the hosts of a network must meet, an empty policy removes them
+// the network is only saved if it is still at the given revision, see updateNetworkAtRevision
+func SetPosturePolicy(networkName string, policy models.PosturePolicy, revision *uint64) (models.Network, error) {
+	if policy.MinVersion != "" {
+		if _, err := version.NewVersion(strings.TrimPrefix(policy.MinVersion, "v")); err != nil {
+			return models.Network{}, fmt.Errorf("invalid minimum version %s: %w", policy.MinVersion, err)
+		}
+	}
+	return updateNetworkAtRevision(networkName, revision, func(network *models.Network) error {
+		if policy.IsEmpty() {
+			network.PosturePolicy = nil
+		} else {
+			network.PosturePolicy = &policy
+		}
+		return nil
+	})
+}
+
+// CheckPosture - returns the requirements of a posture policy that a host fails
+func CheckPosture(host *models.Host, policy *models.PosturePolicy) []string {
+	violations := []string{}
+	if policy.IsEmpty() {
+		return violations
+	}
+	if policy.MinVersion != "" && !IsVersionAtLeast(host.Version, strings.TrimPrefix(policy.MinVersion, "v")) {
+		violations = append(violations, fmt.Sprintf("netclient version %s is older than %s", host.Version, policy.MinVersion))
+	}
+	if len(policy.AllowedOS) > 0 && !slices.Contains(policy.AllowedOS, host.OS) {
+		violations = append(violations, fmt.Sprintf("os %s is not one of %s", host.OS, strings.Join(policy.AllowedOS, ", ")))
+	}
+	if policy.Firewall != "" && host.FirewallInUse != policy.Firewall {
+		violations = append(violations, fmt.Sprintf("firewall %q is not %s", host.FirewallInUse, policy.Firewall))
+	}
+	if policy.DenyDocker && host.IsDocker {
+		violations = append(violations, "host runs in docker")
+	}
+	if policy.RequireDaemon && !host.DaemonInstalled {
+		violations = append(violations, "netclient is not installed as a daemon")
+	}
+	return violations
+}
+
+// EvaluateHostPosture - checks a host against the posture policies of the networks of its nodes, quarantining the
+// nodes whose network policy it fails and releasing the ones whose policy it meets again, returns whether any node
+// was quarantined or released
+func EvaluateHostPosture(host *models.Host) (bool, error) {
+	changed := false
+	policies := map[string]*models.PosturePolicy{}
+	for _, nodeID := range host.Nodes {
+		node, err := GetNodeByID(nodeID)
+		if err != nil {
+			continue
+		}
+		policy, ok := policies[node.Network]
+		if !ok {
+			network, err := GetNetwork(node.Network)
+			if err != nil {
+				continue
+			}
+			policy = network.PosturePolicy
+			policies[node.Network] = policy
+		}
+		violations := CheckPosture(host, policy)
+		quarantined := len(violations) > 0
+		if quarantined == node.Quarantined && slices.Equal(violations, node.PostureViolations) {
+			continue
+		}
+		if quarantined != node.Quarantined {
+			changed = true
+			if quarantined {
+				logger.Log(0, "quarantined node", node.ID.String(), "of host", host.Name, "in network", node.Network, "-", strings.Join(violations, "; "))
+			} else {
+				logger.Log(0, "released node", node.ID.String(), "of host", host.Name, "in network", node.Network, "from quarantine")
+			}
+		}
+		node.Quarantined = quarantined
+		node.PostureViolations = nil
+		if quarantined {
+			node.PostureViolations = violations
+		}
+		if err := UpsertNode(&node); err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
+
+// EvaluateNetworkPosture - checks every host of a network against the posture policies of its networks, returns
+// whether any node was quarantined or released
+func EvaluateNetworkPosture(networkName string) (bool, error) {
+	nodes, err := GetNetworkNodes(networkName)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return false, err
+	}
+	changed := false
+	for _, node := range nodes {
+		host, err := GetHost(node.HostID.String())
+		if err != nil {
+			continue
+		}
+		hostChanged, err := EvaluateHostPosture(host)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || hostChanged
+	}
+	return changed, nil
+}
+
+// GetPostureStatus - returns the posture policy of a network with the compliance of each of its nodes
+func GetPostureStatus(networkName string) (models.PostureStatus, error) {
+	status := models.PostureStatus{Network: networkName, Nodes: []models.NodePosture{}}
+	network, err := GetNetwork(networkName)
+	if err != nil {
+		return status, err
+	}
+	status.Policy = network.PosturePolicy
+	nodes, err := GetNetworkNodes(networkName)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return status, err
+	}
+	for _, node := range nodes {
+		nodePosture := models.NodePosture{
+			NodeID:      node.ID.String(),
+			HostID:      node.HostID.String(),
+			Quarantined: node.Quarantined,
+			Violations:  node.PostureViolations,
+		}
+		if nodePosture.Violations == nil {
+			nodePosture.Violations = []string{}
+		}
+		if host, err := GetHost(node.HostID.String()); err == nil {
+			nodePosture.HostName = host.Name
+		}
+		status.Nodes = append(status.Nodes, nodePosture)
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool {
+		return status.Nodes[i].HostName < status.Nodes[j].HostName
+	})
+	return status, nil
+}
@@ -0,0 +1,89 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPosture(t *testing.T) {
+	host := &models.Host{OS: "linux", Version: "v0.20.1", FirewallInUse: "iptables", IsDocker: true}
+	assert.Empty(t, CheckPosture(host, nil))
+	assert.Empty(t, CheckPosture(host, &models.PosturePolicy{MinVersion: "v0.20.0", AllowedOS: []string{"linux"}}))
+	assert.Len(t, CheckPosture(host, &models.PosturePolicy{MinVersion: "v0.21.0"}), 1)
+	assert.Len(t, CheckPosture(host, &models.PosturePolicy{AllowedOS: []string{"darwin", "windows"}}), 1)
+	assert.Len(t, CheckPosture(host, &models.PosturePolicy{Firewall: "nftables", DenyDocker: true, RequireDaemon: true}), 3)
+}
+
+func TestHostPosture(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("posturenet")
+	})
+	CreateNetwork(models.Network{NetID: "posturenet", AddressRange: "10.87.0.0/24"})
+	host := models.Host{ID: uuid.New(), Name: "posturehost", HostPass: "password", OS: "linux", Version: "v0.20.0",
+		FirewallInUse: "iptables"}
+	assert.Nil(t, CreateHost(&host))
+	t.Cleanup(func() {
+		RemoveHost(&host, true)
+	})
+	node, err := UpdateHostNetwork(&host, "posturenet", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(node, true)
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		_, err := SetPosturePolicy("posturenet", models.PosturePolicy{MinVersion: "latest"}, nil)
+		assert.NotNil(t, err)
+	})
+	t.Run("Quarantine", func(t *testing.T) {
+		_, err := SetPosturePolicy("posturenet", models.PosturePolicy{Firewall: "nftables"}, nil)
+		assert.Nil(t, err)
+		changed, err := EvaluateNetworkPosture("posturenet")
+		assert.Nil(t, err)
+		assert.True(t, changed)
+		saved, err := GetNodeByID(node.ID.String())
+		assert.Nil(t, err)
+		assert.True(t, saved.Quarantined)
+		assert.Len(t, saved.PostureViolations, 1)
+		status, err := GetPostureStatus("posturenet")
+		assert.Nil(t, err)
+		assert.Len(t, status.Nodes, 1)
+		assert.True(t, status.Nodes[0].Quarantined)
+		update, err := GetPeerUpdateForHost("", &host, []models.Node{saved}, nil, nil)
+		assert.Nil(t, err)
+		assert.Empty(t, update.Peers)
+	})
+	t.Run("Release", func(t *testing.T) {
+		current, err := GetHost(host.ID.String())
+		assert.Nil(t, err)
+		current.FirewallInUse = "nftables"
+		changed, err := EvaluateHostPosture(current)
+		assert.Nil(t, err)
+		assert.True(t, changed)
+		saved, err := GetNodeByID(node.ID.String())
+		assert.Nil(t, err)
+		assert.False(t, saved.Quarantined)
+		assert.Empty(t, saved.PostureViolations)
+		changed, err = EvaluateHostPosture(current)
+		assert.Nil(t, err)
+		assert.False(t, changed)
+	})
+	t.Run("ModifiedNetwork", func(t *testing.T) {
+		revision, err := database.GetRevision(database.NETWORKS_TABLE_NAME, "posturenet")
+		assert.Nil(t, err)
+		stale := revision - 1
+		_, err = SetPosturePolicy("posturenet", models.PosturePolicy{}, &stale)
+		assert.ErrorIs(t, err, database.ErrRevisionMismatch)
+	})
+	t.Run("ClearPolicy", func(t *testing.T) {
+		network, err := SetPosturePolicy("posturenet", models.PosturePolicy{}, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, network.PosturePolicy)
+	})
+}
@@ -11,6 +11,8 @@ import (
 
 const (
 	expirationTime = time.Minute * 5
+	// retentionTime - expired entries are kept a while longer so callbacks can still report ErrExpired
+	retentionTime = time.Hour
 )
 
 // CValue - the cache object for a network
@@ -34,7 +36,7 @@ func Set(k string, newValue *CValue) error {
 		return err
 	}
 
-	return database.Insert(k, string(newData), database.CACHE_TABLE_NAME)
+	return database.InsertWithTTL(k, string(newData), database.CACHE_TABLE_NAME, retentionTime)
 }
 
 // Get - gets a value from db, if expired, return err
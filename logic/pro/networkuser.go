@@ -173,6 +173,11 @@ func RemoveAllNetworkUsers(network string) error {
 	return database.DeleteRecord(database.NETWORK_USER_TABLE_NAME, network)
 }
 
+// RemoveAllNetworkUsersTx - queues the removal of all network users from given network in a db transaction
+func RemoveAllNetworkUsersTx(tx *database.Tx, network string) {
+	tx.Delete(database.NETWORK_USER_TABLE_NAME, network)
+}
+
 // IsUserNodeAllowed - given a list of nodes, determine if the user's node is allowed based on ID
 // Checks if node is in given nodes list as well as being in user's list
 func IsUserNodeAllowed(nodes []models.Node, network, userID, nodeID string) bool {
@@ -0,0 +1,493 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+)
+
+// rangeMove - moves the addresses of one ip family of a network from its current range to a new one
+type rangeMove struct {
+	from, to         *net.IPNet
+	fromPool, toPool *addressPool
+	// mapping - the new address of every address in use, keyed by the old address
+	mapping map[string]net.IP
+}
+
+func newRangeMove(fromCIDR, toCIDR string) (*rangeMove, error) {
+	_, from, err := net.ParseCIDR(fromCIDR)
+	if err != nil {
+		return nil, err
+	}
+	_, to, err := net.ParseCIDR(toCIDR)
+	if err != nil {
+		return nil, err
+	}
+	fromPool, err := newAddressPool(fromCIDR)
+	if err != nil {
+		return nil, err
+	}
+	toPool, err := newAddressPool(toCIDR)
+	if err != nil {
+		return nil, err
+	}
+	return &rangeMove{from: from, to: to, fromPool: fromPool, toPool: toPool, mapping: map[string]net.IP{}}, nil
+}
+
+// rangeMove.moveOffset - returns the address at the same offset in the new range, false if it does not fit
+func (m *rangeMove) moveOffset(ip net.IP) (net.IP, bool) {
+	if !m.from.Contains(ip) {
+		return nil, false
+	}
+	if m.fromPool.length == net.IPv4len {
+		ip = ip.To4()
+	} else {
+		ip = ip.To16()
+	}
+	offset := new(big.Int).Sub(new(big.Int).SetBytes(ip), m.fromPool.base)
+	moved := new(big.Int).Add(m.toPool.base, offset).Bytes()
+	if len(moved) > m.toPool.length {
+		return nil, false
+	}
+	address := make(net.IP, m.toPool.length)
+	copy(address[m.toPool.length-len(moved):], moved)
+	return address, m.to.Contains(address)
+}
+
+// rangeMove.mapAddresses - assigns a new address to each address of the old range, addresses keep their offset when
+// it is free in the new range, the others get the first free addresses in order
+func (m *rangeMove) mapAddresses(addresses []net.IP, reserved spanSet) error {
+	pending := []net.IP{}
+	for _, ip := range addresses {
+		if ip == nil || !m.from.Contains(ip) {
+			continue
+		}
+		if _, ok := m.mapping[ip.String()]; ok {
+			continue
+		}
+		if moved, ok := m.moveOffset(ip); ok {
+			if offset, ok := m.toPool.offset(moved); ok && offset >= m.toPool.first {
+				if _, used := m.toPool.used.find(offset); !used {
+					m.toPool.used = m.toPool.used.add(offset, offset)
+					m.mapping[ip.String()] = moved
+					continue
+				}
+			}
+		}
+		pending = append(pending, ip)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return bytes.Compare(pending[i].To16(), pending[j].To16()) < 0
+	})
+	for _, ip := range pending {
+		if _, ok := m.mapping[ip.String()]; ok {
+			continue
+		}
+		offset, ok := m.toPool.next(reserved, nil, false)
+		if !ok {
+			return fmt.Errorf("%s is too small for the addresses in use in %s", m.to.String(), m.from.String())
+		}
+		m.toPool.used = m.toPool.used.add(offset, offset)
+		m.mapping[ip.String()] = m.toPool.address(offset)
+	}
+	return nil
+}
+
+// rangeMove.translate - moves an address or a range that lies within the old range, returns false if it lies outside
+func (m *rangeMove) translate(value string) (string, bool, error) {
+	single := false
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return value, false, nil
+		}
+		single = true
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+	ones, bits := ipNet.Mask.Size()
+	fromOnes, fromBits := m.from.Mask.Size()
+	if bits != fromBits || ones < fromOnes || !m.from.Contains(ipNet.IP) {
+		return value, false, nil
+	}
+	if ones == fromOnes {
+		return m.to.String(), true, nil
+	}
+	moved, ok := m.mapping[ipNet.IP.String()]
+	if !ok || ones != bits {
+		toOnes, _ := m.to.Mask.Size()
+		if moved, ok = m.moveOffset(ipNet.IP); !ok || ones < toOnes {
+			return value, false, fmt.Errorf("%s does not fit in %s", value, m.to.String())
+		}
+	}
+	if single {
+		return moved.String(), true, nil
+	}
+	return (&net.IPNet{IP: moved, Mask: ipNet.Mask}).String(), true, nil
+}
+
+// renumbering - a network and the objects whose addresses change when it moves to new address ranges
+type renumbering struct {
+	plan    models.RenumberPlan
+	network models.Network
+	nodes   []models.Node
+	clients []models.ExtClient
+	dns     []models.DNSEntry
+	record  ipamRecord
+}
+
+// planRenumber - computes the new addresses of everything in a network for new address ranges
+func planRenumber(networkName string, request models.RenumberRequest) (*renumbering, error) {
+	network, err := GetParentNetwork(networkName)
+	if err != nil {
+		return nil, err
+	}
+	moves := map[bool]*rangeMove{}
+	for _, family := range []struct {
+		name      string
+		isIpv6    bool
+		current   string
+		requested string
+	}{
+		{name: "ipv4", isIpv6: false, current: network.AddressRange, requested: request.AddressRange},
+		{name: "ipv6", isIpv6: true, current: network.AddressRange6, requested: request.AddressRange6},
+	} {
+		if family.requested == "" {
+			continue
+		}
+		requested, err := NormalizeCIDR(family.requested)
+		if err != nil {
+			return nil, err
+		}
+		if ip, _, _ := net.ParseCIDR(requested); (ip.To4() == nil) != family.isIpv6 {
+			return nil, fmt.Errorf("%s is not an %s range", family.requested, family.name)
+		}
+		if requested == family.current {
+			continue
+		}
+		if family.current == "" {
+			return nil, fmt.Errorf("network %s has no %s range to renumber", networkName, family.name)
+		}
+		if moves[family.isIpv6], err = newRangeMove(family.current, requested); err != nil {
+			return nil, err
+		}
+	}
+	if len(moves) == 0 {
+		return nil, errors.New("no address range of network " + networkName + " changes")
+	}
+	renumbered := network
+	for isIpv6, move := range moves {
+		if isIpv6 {
+			renumbered.AddressRange6 = move.to.String()
+		} else {
+			renumbered.AddressRange = move.to.String()
+		}
+	}
+	if err := validateAddressRanges(&renumbered); err != nil {
+		return nil, err
+	}
+
+	nodes, err := GetNetworkNodes(networkName)
+	if err != nil {
+		return nil, err
+	}
+	clients, err := GetNetworkExtClients(networkName)
+	if err != nil {
+		return nil, err
+	}
+	customDNS, err := GetCustomDNS(networkName)
+	if err != nil && !database.IsEmptyRecord(err) {
+		return nil, err
+	}
+	record, err := fetchIPAMRecord(networkName)
+	if err != nil {
+		return nil, err
+	}
+	r := renumbering{
+		network: renumbered,
+		plan: models.RenumberPlan{
+			Network:          networkName,
+			AddressRange:     network.AddressRange,
+			AddressRange6:    network.AddressRange6,
+			NewAddressRange:  renumbered.AddressRange,
+			NewAddressRange6: renumbered.AddressRange6,
+			Changes:          []models.AddressChange{},
+		},
+		record: record,
+	}
+	change := func(kind models.AddressChangeKind, id, name, old, new string) {
+		r.plan.Changes = append(r.plan.Changes, models.AddressChange{Kind: kind, ID: id, Name: name, Old: old, New: new})
+	}
+
+	// reserved ranges keep their offsets and are dropped if they no longer fit
+	reserved := map[bool]spanSet{}
+	r.record.Reserved = []models.AddressRange{}
+	for _, addressRange := range record.Reserved {
+		start, end := net.ParseIP(addressRange.Start), net.ParseIP(addressRange.End)
+		isIpv6 := start.To4() == nil
+		move := moves[isIpv6]
+		if move == nil || !move.from.Contains(start) {
+			r.record.Reserved = append(r.record.Reserved, addressRange)
+			continue
+		}
+		id := addressRange.Start + "-" + addressRange.End
+		newStart, startOk := move.moveOffset(start)
+		newEnd, endOk := move.moveOffset(end)
+		if !startOk || !endOk {
+			change(models.AddressChangeReservation, id, "", id, "")
+			continue
+		}
+		moved := models.AddressRange{Start: newStart.String(), End: newEnd.String()}
+		r.record.Reserved = append(r.record.Reserved, moved)
+		change(models.AddressChangeReservation, id, "", id, moved.Start+"-"+moved.End)
+		lo, loOk := move.toPool.offset(newStart)
+		hi, hiOk := move.toPool.offset(newEnd)
+		if loOk && hiOk {
+			reserved[isIpv6] = reserved[isIpv6].add(lo, hi)
+		}
+	}
+
+	addresses := []net.IP{}
+	for _, reservation := range record.Reservations {
+		addresses = append(addresses, net.ParseIP(reservation.Address))
+	}
+	for _, node := range nodes {
+		addresses = append(addresses, node.Address.IP, node.Address6.IP)
+	}
+	for _, client := range clients {
+		addresses = append(addresses, net.ParseIP(client.Address), net.ParseIP(client.Address6))
+	}
+	for isIpv6, move := range moves {
+		// custom dns entries that point at no node or ext client keep their offset, nothing else may take it
+		avoid := reserved[isIpv6]
+		for _, entry := range customDNS {
+			for _, address := range []string{entry.Address, entry.Address6} {
+				if moved, ok := move.moveOffset(net.ParseIP(address)); ok {
+					if offset, ok := move.toPool.offset(moved); ok {
+						avoid = avoid.add(offset, offset)
+					}
+				}
+			}
+		}
+		if err := move.mapAddresses(addresses, avoid); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, node := range nodes {
+		hostName := ""
+		if host, err := GetHost(node.HostID.String()); err == nil {
+			hostName = host.Name
+		}
+		id := node.ID.String()
+		changed := false
+		// nodes may come from the cache, their slices must not be changed in place
+		node.EgressGatewayRanges = append([]string{}, node.EgressGatewayRanges...)
+		node.EgressGatewayRequest.Ranges = append([]string{}, node.EgressGatewayRequest.Ranges...)
+		for _, isIpv6 := range []bool{false, true} {
+			move := moves[isIpv6]
+			if move == nil {
+				continue
+			}
+			address, ingressRange := &node.Address, &node.IngressGatewayRange
+			if isIpv6 {
+				address, ingressRange = &node.Address6, &node.IngressGatewayRange6
+			}
+			if address.IP != nil {
+				if moved, ok := move.mapping[address.IP.String()]; ok {
+					change(models.AddressChangeNode, id, hostName, address.IP.String(), moved.String())
+					*address = net.IPNet{IP: moved, Mask: move.to.Mask}
+					changed = true
+				}
+			}
+			if *ingressRange == move.from.String() {
+				change(models.AddressChangeIngressRange, id, hostName, *ingressRange, move.to.String())
+				*ingressRange = move.to.String()
+				changed = true
+			}
+			for i, egressRange := range node.EgressGatewayRanges {
+				moved, ok, err := move.translate(egressRange)
+				if err != nil {
+					return nil, fmt.Errorf("egress range of node %s: %w", id, err)
+				}
+				if ok {
+					change(models.AddressChangeEgressRange, id, hostName, egressRange, moved)
+					node.EgressGatewayRanges[i] = moved
+					changed = true
+				}
+			}
+			for i, egressRange := range node.EgressGatewayRequest.Ranges {
+				if moved, ok, err := move.translate(egressRange); err == nil && ok {
+					node.EgressGatewayRequest.Ranges[i] = moved
+					changed = true
+				}
+			}
+		}
+		if changed {
+			r.nodes = append(r.nodes, node)
+		}
+	}
+
+	for _, client := range clients {
+		changed := false
+		client.ExtraAllowedIPs = append([]string{}, client.ExtraAllowedIPs...)
+		for _, isIpv6 := range []bool{false, true} {
+			move := moves[isIpv6]
+			if move == nil {
+				continue
+			}
+			address := &client.Address
+			if isIpv6 {
+				address = &client.Address6
+			}
+			if ip := net.ParseIP(*address); ip != nil {
+				if moved, ok := move.mapping[ip.String()]; ok {
+					change(models.AddressChangeExtClient, client.ClientID, client.ClientID, *address, moved.String())
+					*address = moved.String()
+					changed = true
+				}
+			}
+			for i, allowedIP := range client.ExtraAllowedIPs {
+				moved, ok, err := move.translate(allowedIP)
+				if err != nil {
+					return nil, fmt.Errorf("extra allowed ip of ext client %s: %w", client.ClientID, err)
+				}
+				if ok {
+					change(models.AddressChangeAllowedIP, client.ClientID, client.ClientID, allowedIP, moved)
+					client.ExtraAllowedIPs[i] = moved
+					changed = true
+				}
+			}
+		}
+		if changed {
+			r.clients = append(r.clients, client)
+		}
+	}
+
+	for _, entry := range customDNS {
+		changed := false
+		for _, address := range []*string{&entry.Address, &entry.Address6} {
+			ip := net.ParseIP(*address)
+			if ip == nil {
+				continue
+			}
+			if move := moves[ip.To4() == nil]; move != nil {
+				moved, ok, err := move.translate(*address)
+				if err != nil {
+					return nil, fmt.Errorf("custom dns entry %s: %w", entry.Name, err)
+				}
+				if ok {
+					change(models.AddressChangeDNS, entry.Name, entry.Name, *address, moved)
+					*address = moved
+					changed = true
+				}
+			}
+		}
+		if changed {
+			r.dns = append(r.dns, entry)
+		}
+	}
+
+	for i, reservation := range r.record.Reservations {
+		ip := net.ParseIP(reservation.Address)
+		if move := moves[ip.To4() == nil]; move != nil {
+			if moved, ok := move.mapping[ip.String()]; ok {
+				id := reservation.HostID
+				if id == "" {
+					id = reservation.HostName
+				}
+				change(models.AddressChangeReservation, id, reservation.HostName, reservation.Address, moved.String())
+				r.record.Reservations[i].Address = moved.String()
+			}
+		}
+	}
+	// addresses released from the old ranges have nothing to cool down for
+	r.record.Released = map[string]int64{}
+
+	sort.SliceStable(r.plan.Changes, func(i, j int) bool {
+		if r.plan.Changes[i].Kind != r.plan.Changes[j].Kind {
+			return r.plan.Changes[i].Kind < r.plan.Changes[j].Kind
+		}
+		return r.plan.Changes[i].ID < r.plan.Changes[j].ID
+	})
+	return &r, nil
+}
+
+// PlanRenumber - returns how the addresses of a network would change for new address ranges without changing anything
+func PlanRenumber(networkName string, request models.RenumberRequest) (*models.RenumberPlan, error) {
+	r, err := planRenumber(networkName, request)
+	if err != nil {
+		return nil, err
+	}
+	return &r.plan, nil
+}
+
+// RenumberNetwork - moves a network to new address ranges, the addresses of its nodes, ext clients, custom DNS
+// entries, reservations and gateway ranges change in a single transaction
+func RenumberNetwork(networkName string, request models.RenumberRequest) (*models.RenumberPlan, error) {
+	addressLock.Lock()
+	defer addressLock.Unlock()
+	r, err := planRenumber(networkName, request)
+	if err != nil {
+		return nil, err
+	}
+	tx := database.BeginTx()
+	for i := range r.nodes {
+		if err = upsertNodeTx(tx, &r.nodes[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range r.clients {
+		if err = saveExtClientTx(tx, &r.clients[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range r.dns {
+		key, err := GetRecordKey(entry.Name, entry.Network)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(&entry)
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Insert(key, string(data), database.DNS_TABLE_NAME); err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.Marshal(&r.record)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Insert(networkName, string(data), database.IPAM_TABLE_NAME); err != nil {
+		return nil, err
+	}
+	r.network.SetNodesLastModified()
+	r.network.SetNetworkLastModified()
+	if data, err = json.Marshal(&r.network); err != nil {
+		return nil, err
+	}
+	if err = tx.Insert(networkName, string(data), database.NETWORKS_TABLE_NAME); err != nil {
+		return nil, err
+	}
+	tx.OnCommit(func() {
+		resetAddressPools(networkName)
+	})
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.plan.Applied = true
+	return &r.plan, nil
+}
@@ -0,0 +1,123 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeMoveTranslate(t *testing.T) {
+	move, err := newRangeMove("10.95.0.0/24", "10.94.0.0/16")
+	assert.Nil(t, err)
+	moved, ok, err := move.translate("10.95.0.0/24")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "10.94.0.0/16", moved)
+	moved, ok, err = move.translate("10.95.0.16/28")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "10.94.0.16/28", moved)
+	moved, ok, err = move.translate("10.95.0.7")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "10.94.0.7", moved)
+	_, ok, err = move.translate("192.168.1.0/24")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	move, err = newRangeMove("10.95.0.0/24", "10.94.0.0/28")
+	assert.Nil(t, err)
+	_, _, err = move.translate("10.95.0.128/25")
+	assert.NotNil(t, err)
+}
+
+func TestRenumberNetwork(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("renumbernet")
+	})
+	CreateNetwork(models.Network{NetID: "renumbernet", AddressRange: "10.95.0.0/24"})
+	host := models.Host{ID: uuid.New(), Name: "renumberhost", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&host))
+	node, err := UpdateHostNetwork(&host, "renumbernet", true)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteNode(node, true)
+		RemoveHost(&host, true)
+	})
+	assert.Equal(t, "10.95.0.1", node.Address.IP.String())
+	client := models.ExtClient{ClientID: "renumberclient", Network: "renumbernet", IngressGatewayID: uuid.New().String()}
+	assert.Nil(t, CreateExtClient(&client))
+	t.Cleanup(func() {
+		DeleteExtClient("renumbernet", "renumberclient")
+	})
+	assert.Equal(t, "10.95.0.254", client.Address)
+	_, err = CreateDNS(models.DNSEntry{Name: "renumberdns", Network: "renumbernet", Address: "10.95.0.1"})
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		DeleteDNS("renumberdns", "renumbernet")
+	})
+	_, err = CreateAddressReservation("renumbernet", models.AddressReservation{Address: "10.95.0.5", HostName: "otherhost"})
+	assert.Nil(t, err)
+	assert.Nil(t, ReserveAddressRange("renumbernet", models.AddressRange{Start: "10.95.0.200", End: "10.95.0.210"}))
+
+	_, err = PlanRenumber("renumbernet", models.RenumberRequest{AddressRange: "10.95.0.0/24"})
+	assert.NotNil(t, err)
+	_, err = PlanRenumber("renumbernet", models.RenumberRequest{AddressRange6: "fd00::/64"})
+	assert.NotNil(t, err)
+	_, err = PlanRenumber("renumbernet", models.RenumberRequest{AddressRange: "10.94.0.0/30"})
+	assert.NotNil(t, err)
+	_, err = CreateDNS(models.DNSEntry{Name: "renumberdns2", Network: "renumbernet", Address: "10.95.0.100"})
+	assert.Nil(t, err)
+	// the entry points at no node and does not fit
+	_, err = PlanRenumber("renumbernet", models.RenumberRequest{AddressRange: "10.94.0.0/28"})
+	assert.NotNil(t, err)
+	assert.Nil(t, DeleteDNS("renumberdns2", "renumbernet"))
+
+	plan, err := PlanRenumber("renumbernet", models.RenumberRequest{AddressRange: "10.94.0.1/28"})
+	assert.Nil(t, err)
+	assert.False(t, plan.Applied)
+	assert.Equal(t, "10.94.0.0/28", plan.NewAddressRange)
+	newAddresses := map[string]string{}
+	for _, change := range plan.Changes {
+		newAddresses[change.Old] = change.New
+	}
+	assert.Equal(t, map[string]string{
+		"10.95.0.1":               "10.94.0.1",
+		"10.95.0.5":               "10.94.0.5",
+		"10.95.0.254":             "10.94.0.2",
+		"10.95.0.200-10.95.0.210": "",
+	}, newAddresses)
+	network, err := GetNetwork("renumbernet")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.95.0.0/24", network.AddressRange)
+
+	plan, err = RenumberNetwork("renumbernet", models.RenumberRequest{AddressRange: "10.94.0.0/28"})
+	assert.Nil(t, err)
+	assert.True(t, plan.Applied)
+	network, err = GetNetwork("renumbernet")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.94.0.0/28", network.AddressRange)
+	renumbered, err := GetNodeByID(node.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "10.94.0.1/28", renumbered.Address.String())
+	renumberedClient, err := GetExtClient("renumberclient", "renumbernet")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.94.0.2", renumberedClient.Address)
+	entries, err := GetCustomDNS("renumbernet")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.94.0.1", entries[0].Address)
+	reservations, err := GetAddressReservations("renumbernet")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.94.0.5", reservations[0].Address)
+	ranges, err := GetReservedAddressRanges("renumbernet")
+	assert.Nil(t, err)
+	assert.Empty(t, ranges)
+	address, err := UniqueAddress("renumbernet", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.94.0.3", address.String())
+}
@@ -0,0 +1,369 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic/acls"
+	"github.com/gravitl/netmaker/logic/acls/nodeacls"
+	"github.com/gravitl/netmaker/logic/pro"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
+)
+
+// ErrRestoreConflict - the deleted object, or one of its addresses, has been taken since it was deleted
+var ErrRestoreConflict = errors.New("restore conflict")
+
+// trashEntry - a deleted object along with everything needed to restore it
+type trashEntry struct {
+	models.TrashItem
+	// Records - the deleted records, kept as a json string so they are encrypted at rest like other sensitive fields
+	Records string `json:"records"`
+	// NodeACLs - the ACL rows of deleted nodes by node id, restored into the ACLs of their networks
+	NodeACLs map[string]acls.ACL `json:"nodeacls,omitempty"`
+	records  []trashRecord
+}
+
+// trashRecord - a deleted database record
+type trashRecord struct {
+	Table string `json:"table"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func newTrashEntry(kind models.TrashKind, name, network, user string) *trashEntry {
+	now := time.Now()
+	return &trashEntry{
+		TrashItem: models.TrashItem{
+			ID:        uuid.New().String(),
+			Kind:      kind,
+			Name:      name,
+			Network:   network,
+			DeletedBy: user,
+			DeletedAt: now,
+			ExpiresAt: now.Add(servercfg.GetTrashRetention()),
+		},
+		NodeACLs: map[string]acls.ACL{},
+	}
+}
+
+// trashEntry.keep - adds the current value of a record that is about to be deleted, missing records are skipped
+func (e *trashEntry) keep(tableName, key string) error {
+	value, err := database.FetchRecord(tableName, key)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return nil
+		}
+		return err
+	}
+	e.records = append(e.records, trashRecord{Table: tableName, Key: key, Value: value})
+	return nil
+}
+
+// trashEntry.saveTx - queues the entry in given db transaction, it expires at the end of the retention period
+func (e *trashEntry) saveTx(tx *database.Tx) error {
+	records, err := json.Marshal(e.records)
+	if err != nil {
+		return err
+	}
+	e.Records = string(records)
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return tx.InsertWithTTL(e.ID, string(data), database.TRASH_TABLE_NAME, time.Until(e.ExpiresAt))
+}
+
+func getTrashEntry(id string) (*trashEntry, error) {
+	data, err := database.FetchRecord(database.TRASH_TABLE_NAME, id)
+	if err != nil {
+		return nil, err
+	}
+	var entry trashEntry
+	if err = json.Unmarshal([]byte(data), &entry); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(entry.Records), &entry.records); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetTrash - lists the deleted objects that can still be restored, most recently deleted first
+func GetTrash() ([]models.TrashItem, error) {
+	items := []models.TrashItem{}
+	records, err := database.FetchRecords(database.TRASH_TABLE_NAME)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return items, nil
+		}
+		return nil, err
+	}
+	for _, data := range records {
+		var entry trashEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			continue
+		}
+		items = append(items, entry.TrashItem)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// PurgeTrash - permanently deletes an object from the trash
+func PurgeTrash(id string) error {
+	if _, err := database.FetchRecord(database.TRASH_TABLE_NAME, id); err != nil {
+		return err
+	}
+	return database.DeleteRecord(database.TRASH_TABLE_NAME, id)
+}
+
+// SoftDeleteNetwork - deletes a network and keeps it in the trash for the configured retention period
+func SoftDeleteNetwork(network, user string) error {
+	if servercfg.GetTrashRetention() == 0 {
+		return DeleteNetwork(network)
+	}
+	if _, err := GetNetwork(network); err != nil {
+		return err
+	}
+	trash := newTrashEntry(models.TrashNetwork, network, network, user)
+	tx := database.BeginTx()
+	if err := deleteNetworkTx(tx, network, trash); err != nil {
+		return err
+	}
+	if err := trash.saveTx(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SoftDeleteExtClient - deletes an ext client and keeps it in the trash for the configured retention period
+func SoftDeleteExtClient(network, clientid, user string) error {
+	if servercfg.GetTrashRetention() == 0 {
+		return DeleteExtClient(network, clientid)
+	}
+	key, err := GetRecordKey(clientid, network)
+	if err != nil {
+		return err
+	}
+	trash := newTrashEntry(models.TrashExtClient, clientid, network, user)
+	if err = trash.keep(database.EXT_CLIENT_TABLE_NAME, key); err != nil {
+		return err
+	}
+	tx := database.BeginTx()
+	if err = deleteExtClientTx(tx, network, clientid); err != nil {
+		return err
+	}
+	if err = trash.saveTx(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SoftDeleteHost - removes a host, along with its nodes when forced, and keeps it in the trash for the
+// configured retention period
+func SoftDeleteHost(h *models.Host, forceDelete bool, user string) error {
+	if servercfg.GetTrashRetention() == 0 || (!forceDelete && len(h.Nodes) > 0) {
+		return RemoveHost(h, forceDelete)
+	}
+	trash := newTrashEntry(models.TrashHost, h.Name, "", user)
+	if err := trash.keep(database.HOSTS_TABLE_NAME, h.ID.String()); err != nil {
+		return err
+	}
+	nodes := []models.Node{}
+	for _, nodeID := range h.Nodes {
+		node, err := GetNodeByID(nodeID)
+		if err != nil {
+			continue
+		}
+		nodes = append(nodes, node)
+		if err = trash.keep(database.NODES_TABLE_NAME, nodeID); err != nil {
+			return err
+		}
+		if nodeACL, err := nodeacls.FetchNodeACL(nodeacls.NetworkID(node.Network), nodeacls.NodeID(nodeID)); err == nil {
+			trash.NodeACLs[nodeID] = nodeACL
+		}
+		if !node.IsIngressGateway {
+			continue
+		}
+		clients, err := GetNetworkExtClients(node.Network)
+		if err != nil {
+			continue
+		}
+		for _, client := range clients {
+			if client.IngressGatewayID != nodeID {
+				continue
+			}
+			if key, err := GetRecordKey(client.ClientID, client.Network); err == nil {
+				if err = trash.keep(database.EXT_CLIENT_TABLE_NAME, key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// the host, its nodes and their ext clients are deleted in the same transaction as the trash entry is saved, so
+	// nothing is lost if either fails
+	tx := database.BeginTx()
+	for i := range nodes {
+		if err := deleteNodeByID(tx, &nodes[i]); err != nil {
+			return err
+		}
+	}
+	removeHostTx(tx, h)
+	if err := trash.saveTx(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if servercfg.Is_EE {
+		for _, node := range nodes {
+			if err := EnterpriseResetAllPeersFailovers(node.ID, node.Network); err != nil {
+				logger.Log(0, "failed to reset failover lists during node delete for node", node.ID.String(), node.Network)
+			}
+		}
+	}
+	return nil
+}
+
+// RestoreTrash - re-creates a deleted object with its original addresses, keys, ACL rows and DNS entries
+// addressLock is held from the conflict checks until the records are committed so no address is handed out in between
+func RestoreTrash(id string) (*models.TrashItem, error) {
+	addressLock.Lock()
+	defer addressLock.Unlock()
+	entry, err := getTrashEntry(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = entry.checkRestore(); err != nil {
+		return nil, err
+	}
+	tx := database.BeginTx()
+	for _, record := range entry.records {
+		if err = tx.Insert(record.Key, record.Value, record.Table); err != nil {
+			return nil, err
+		}
+	}
+	for nodeID, nodeACL := range entry.NodeACLs {
+		node, ok := entry.node(nodeID)
+		if !ok {
+			continue
+		}
+		defaultACLVal := acls.Allowed
+		if network, err := GetNetwork(node.Network); err == nil && network.DefaultACL != "yes" {
+			defaultACLVal = acls.NotAllowed
+		}
+		if err = nodeacls.RestoreNodeACLTx(tx, nodeacls.NetworkID(node.Network), nodeacls.NodeID(nodeID), nodeACL, defaultACLVal); err != nil {
+			return nil, err
+		}
+	}
+	tx.Delete(database.TRASH_TABLE_NAME, id)
+	tx.OnCommit(func() {
+		for _, record := range entry.records {
+			syncCache(database.Change{Table: record.Table, Key: record.Key})
+			if record.Table != database.EXT_CLIENT_TABLE_NAME {
+				continue
+			}
+			var client models.ExtClient
+			if err := json.Unmarshal([]byte(record.Value), &client); err == nil && client.OwnerID != "" {
+				if err = pro.AssociateNetworkUserClient(client.OwnerID, client.Network, client.ClientID); err != nil {
+					logger.Log(0, "failed to associate restored client", client.ClientID, "to user", client.OwnerID)
+				}
+			}
+		}
+		if servercfg.IsDNSMode() {
+			if err := SetDNS(); err != nil {
+				logger.Log(0, "failed to set DNS after restore:", err.Error())
+			}
+		}
+	})
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &entry.TrashItem, nil
+}
+
+// trashEntry.node - returns a deleted node of the entry
+func (e *trashEntry) node(nodeID string) (models.Node, bool) {
+	var node models.Node
+	for _, record := range e.records {
+		if record.Table == database.NODES_TABLE_NAME && record.Key == nodeID {
+			return node, json.Unmarshal([]byte(record.Value), &node) == nil
+		}
+	}
+	return node, false
+}
+
+// trashEntry.checkRestore - makes sure none of the deleted records or their addresses have been taken since
+func (e *trashEntry) checkRestore() error {
+	restoredNodes := map[string]bool{}
+	for _, record := range e.records {
+		if record.Table == database.NODES_TABLE_NAME {
+			restoredNodes[record.Key] = true
+		}
+	}
+	for _, record := range e.records {
+		switch record.Table {
+		case database.NETWORKS_TABLE_NAME:
+			if _, err := GetNetwork(record.Key); err == nil {
+				return fmt.Errorf("%w: network %s already exists", ErrRestoreConflict, record.Key)
+			}
+		case database.HOSTS_TABLE_NAME:
+			if _, err := GetHost(record.Key); err == nil {
+				return fmt.Errorf("%w: host %s already exists", ErrRestoreConflict, record.Key)
+			}
+		case database.NODES_TABLE_NAME:
+			var node models.Node
+			if err := json.Unmarshal([]byte(record.Value), &node); err != nil {
+				return err
+			}
+			if _, err := GetNetwork(node.Network); err != nil {
+				return fmt.Errorf("%w: network %s of node %s no longer exists", ErrRestoreConflict, node.Network, record.Key)
+			}
+			if _, err := GetNodeByID(record.Key); err == nil {
+				return fmt.Errorf("%w: node %s already exists", ErrRestoreConflict, record.Key)
+			}
+			if node.Address.IP != nil && isAddressTaken(node.Network, node.Address.IP.String(), false) {
+				return fmt.Errorf("%w: address %s is in use", ErrRestoreConflict, node.Address.IP.String())
+			}
+			if node.Address6.IP != nil && isAddressTaken(node.Network, node.Address6.IP.String(), true) {
+				return fmt.Errorf("%w: address %s is in use", ErrRestoreConflict, node.Address6.IP.String())
+			}
+		case database.EXT_CLIENT_TABLE_NAME:
+			var client models.ExtClient
+			if err := json.Unmarshal([]byte(record.Value), &client); err != nil {
+				return err
+			}
+			if _, err := GetExtClient(client.ClientID, client.Network); err == nil {
+				return fmt.Errorf("%w: ext client %s already exists", ErrRestoreConflict, client.ClientID)
+			}
+			if !restoredNodes[client.IngressGatewayID] {
+				gateway, err := GetNodeByID(client.IngressGatewayID)
+				if err != nil || !gateway.IsIngressGateway {
+					return fmt.Errorf("%w: ingress gateway of ext client %s no longer exists", ErrRestoreConflict, client.ClientID)
+				}
+			}
+			if client.Address != "" && isAddressTaken(client.Network, client.Address, false) {
+				return fmt.Errorf("%w: address %s is in use", ErrRestoreConflict, client.Address)
+			}
+			if client.Address6 != "" && isAddressTaken(client.Network, client.Address6, true) {
+				return fmt.Errorf("%w: address %s is in use", ErrRestoreConflict, client.Address6)
+			}
+		}
+	}
+	return nil
+}
+
+// isAddressTaken - checks if an address is used by a node or ext client of a network
+func isAddressTaken(network, address string, isIpv6 bool) bool {
+	return !IsIPUnique(network, address, database.NODES_TABLE_NAME, isIpv6) ||
+		!IsIPUnique(network, address, database.EXT_CLIENT_TABLE_NAME, isIpv6)
+}
@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logic/acls/nodeacls"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	database.InitializeDatabase()
+	database.DeleteAllRecords(database.TRASH_TABLE_NAME)
+	t.Cleanup(func() {
+		database.DeleteAllRecords(database.TRASH_TABLE_NAME)
+		DeleteNetwork("trashnet")
+	})
+	// network users can not be initialized without any users, the network is created anyway
+	CreateNetwork(models.Network{NetID: "trashnet", AddressRange: "10.99.0.0/24"})
+	_, err := GetNetwork("trashnet")
+	assert.Nil(t, err)
+
+	t.Run("HostWithNodes", func(t *testing.T) {
+		host := models.Host{ID: uuid.New(), Name: "trashhost", HostPass: "password", OS: "linux"}
+		assert.Nil(t, CreateHost(&host))
+		node, err := UpdateHostNetwork(&host, "trashnet", true)
+		assert.Nil(t, err)
+		assert.Nil(t, SoftDeleteHost(&host, true, "admin"))
+		_, err = GetHost(host.ID.String())
+		assert.NotNil(t, err)
+		_, err = GetNodeByID(node.ID.String())
+		assert.NotNil(t, err)
+		_, err = nodeacls.FetchNodeACL("trashnet", nodeacls.NodeID(node.ID.String()))
+		assert.NotNil(t, err)
+
+		items, err := GetTrash()
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, models.TrashHost, items[0].Kind)
+		assert.Equal(t, "admin", items[0].DeletedBy)
+
+		_, err = RestoreTrash(items[0].ID)
+		assert.Nil(t, err)
+		restoredHost, err := GetHost(host.ID.String())
+		assert.Nil(t, err)
+		assert.Equal(t, []string{node.ID.String()}, restoredHost.Nodes)
+		restoredNode, err := GetNodeByID(node.ID.String())
+		assert.Nil(t, err)
+		assert.Equal(t, node.Address.IP.String(), restoredNode.Address.IP.String())
+		_, err = nodeacls.FetchNodeACL("trashnet", nodeacls.NodeID(node.ID.String()))
+		assert.Nil(t, err)
+
+		items, err = GetTrash()
+		assert.Nil(t, err)
+		assert.Empty(t, items)
+		assert.Nil(t, RemoveHost(restoredHost, true))
+	})
+	t.Run("NetworkConflict", func(t *testing.T) {
+		assert.Nil(t, SoftDeleteNetwork("trashnet", "admin"))
+		_, err := GetNetwork("trashnet")
+		assert.True(t, database.IsEmptyRecord(err))
+		items, err := GetTrash()
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+
+		CreateNetwork(models.Network{NetID: "trashnet", AddressRange: "10.99.0.0/24"})
+		_, err = RestoreTrash(items[0].ID)
+		assert.True(t, errors.Is(err, ErrRestoreConflict))
+
+		assert.Nil(t, DeleteNetwork("trashnet"))
+		_, err = RestoreTrash(items[0].ID)
+		assert.Nil(t, err)
+		_, err = GetNetwork("trashnet")
+		assert.Nil(t, err)
+		_, err = RestoreTrash(items[0].ID)
+		assert.True(t, database.IsEmptyRecord(err))
+	})
+	t.Run("Purge", func(t *testing.T) {
+		assert.Nil(t, SoftDeleteNetwork("trashnet", "admin"))
+		items, err := GetTrash()
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+		assert.Nil(t, PurgeTrash(items[0].ID))
+		assert.True(t, database.IsEmptyRecord(PurgeTrash(items[0].ID)))
+	})
+}
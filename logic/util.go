@@ -14,6 +14,7 @@ import (
 	"github.com/c-robinson/iplib"
 	"github.com/gravitl/netmaker/database"
 	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic/acls"
 )
 
 // IsBase64 - checks if a string is in base64 format
@@ -134,4 +135,21 @@ func RemoveStringSlice(slice []string, i int) []string {
 	return append(slice[:i], slice[i+1:]...)
 }
 
+// expectRevision - makes a transaction fail with database.ErrRevisionMismatch unless a record is still at the given
+// revision, a nil revision expects nothing
+func expectRevision(tx *database.Tx, tableName, key string, revision *uint64) {
+	if revision != nil {
+		tx.ExpectRevision(tableName, key, *revision)
+	}
+}
+
+// ClearCaches - drops every in memory cache so state is reloaded from the db on next use
+func ClearCaches() {
+	ClearNodeCache()
+	clearHostCache()
+	clearExtClientCache()
+	acls.ClearAclCache()
+	clearAddressPools()
+}
+
 // == private ==
@@ -11,6 +11,11 @@ const MinVersion = "v0.17.0"
 
 // IsVersionCompatible checks that the version passed is compabtible (>=) with MinVersion
 func IsVersionComptatible(ver string) bool {
+	return IsVersionAtLeast(ver, MinVersion)
+}
+
+// IsVersionAtLeast - checks that the version passed is the same as or newer than min
+func IsVersionAtLeast(ver, min string) bool {
 	// during dev, assume developers know what they are doing
 	if ver == "dev" {
 		return true
@@ -22,7 +27,7 @@ func IsVersionComptatible(ver string) bool {
 	if err != nil {
 		return false
 	}
-	constraint, err := version.NewConstraint(">= " + MinVersion)
+	constraint, err := version.NewConstraint(">= " + min)
 	if err != nil {
 		return false
 	}
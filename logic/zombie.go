@@ -2,11 +2,13 @@ package logic
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gravitl/netmaker/logger"
 	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
 )
 
 const (
@@ -14,6 +16,8 @@ const (
 	ZOMBIE_TIMEOUT = 6
 	// ZOMBIE_DELETE_TIME - timeout in minutes for zombie node deletion
 	ZOMBIE_DELETE_TIME = 10
+	// EphemeralHostCheckInterval - how often ephemeral hosts are checked for having been offline too long
+	EphemeralHostCheckInterval = time.Minute
 )
 
 var (
@@ -130,6 +134,56 @@ func ManageZombies(ctx context.Context, peerUpdate chan *models.Node) {
 	}
 }
 
+// ExpiredEphemeralHosts - returns the ephemeral hosts that have been offline longer than the ephemeral host timeout
+func ExpiredEphemeralHosts(now time.Time) ([]models.Host, error) {
+	hosts, err := GetAllHosts()
+	if err != nil {
+		return nil, err
+	}
+	timeout := servercfg.GetEphemeralHostTimeout()
+	expired := []models.Host{}
+	for _, host := range hosts {
+		if host.Ephemeral && now.Sub(hostLastSeen(&host)) > timeout {
+			expired = append(expired, host)
+		}
+	}
+	return expired, nil
+}
+
+// RemoveEphemeralHost - removes an ephemeral host with all of its nodes, releasing their addresses and DNS entries,
+// returns the removed nodes
+func RemoveEphemeralHost(h *models.Host) ([]models.Node, error) {
+	nodes := []models.Node{}
+	for _, nodeID := range h.Nodes {
+		node, err := GetNodeByID(nodeID)
+		if err != nil {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	if err := RemoveHost(h, true); err != nil {
+		return nil, err
+	}
+	logger.Log(1, "removed ephemeral host", h.Name, h.ID.String(), "with", strconv.Itoa(len(nodes)), "nodes")
+	return nodes, nil
+}
+
+// hostLastSeen - returns the latest check in of the nodes of a host, for a host without nodes the last time it
+// registered or changed its key
+func hostLastSeen(h *models.Host) time.Time {
+	lastSeen := h.KeysUpdatedAt
+	for _, nodeID := range h.Nodes {
+		node, err := GetNodeByID(nodeID)
+		if err != nil {
+			continue
+		}
+		if node.LastCheckIn.After(lastSeen) {
+			lastSeen = node.LastCheckIn
+		}
+	}
+	return lastSeen
+}
+
 // InitializeZombies - populates the zombie quarantine list (should be called from initialization)
 func InitializeZombies() {
 	nodes, err := GetAllNodes()
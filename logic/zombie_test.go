@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEphemeralHosts(t *testing.T) {
+	t.Setenv("DNS_MODE", "off")
+	t.Setenv("EPHEMERAL_HOST_TIMEOUT", "10m")
+	database.InitializeDatabase()
+	t.Cleanup(func() {
+		DeleteNetwork("ephemeralnet")
+	})
+	CreateNetwork(models.Network{NetID: "ephemeralnet", AddressRange: "10.86.0.0/24"})
+	ephemeral := models.Host{ID: uuid.New(), Name: "ephemeralrunner", HostPass: "password", OS: "linux", Ephemeral: true}
+	permanent := models.Host{ID: uuid.New(), Name: "ephemeralserver", HostPass: "password", OS: "linux"}
+	assert.Nil(t, CreateHost(&ephemeral))
+	assert.Nil(t, CreateHost(&permanent))
+	t.Cleanup(func() {
+		RemoveHost(&ephemeral, true)
+		RemoveHost(&permanent, true)
+	})
+	node, err := UpdateHostNetwork(&ephemeral, "ephemeralnet", true)
+	assert.Nil(t, err)
+	node.LastCheckIn = time.Now()
+	assert.Nil(t, UpsertNode(node))
+
+	t.Run("OnlineHostIsKept", func(t *testing.T) {
+		hosts, err := ExpiredEphemeralHosts(time.Now().Add(time.Minute * 5))
+		assert.Nil(t, err)
+		assert.Empty(t, hosts)
+	})
+	t.Run("OfflineHostExpires", func(t *testing.T) {
+		hosts, err := ExpiredEphemeralHosts(time.Now().Add(time.Minute * 11))
+		assert.Nil(t, err)
+		assert.Len(t, hosts, 1)
+		assert.Equal(t, ephemeral.ID, hosts[0].ID)
+	})
+	t.Run("Remove", func(t *testing.T) {
+		current, err := GetHost(ephemeral.ID.String())
+		assert.Nil(t, err)
+		nodes, err := RemoveEphemeralHost(current)
+		assert.Nil(t, err)
+		assert.Len(t, nodes, 1)
+		assert.Equal(t, node.ID, nodes[0].ID)
+		_, err = GetHost(ephemeral.ID.String())
+		assert.NotNil(t, err)
+		_, err = GetNodeByID(node.ID.String())
+		assert.NotNil(t, err)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime/debug"
+	"strconv"
 	"sync"
 	"syscall"
 
@@ -25,7 +26,6 @@ import (
 	"github.com/gravitl/netmaker/mq"
 	"github.com/gravitl/netmaker/netclient/ncutils"
 	"github.com/gravitl/netmaker/servercfg"
-	"github.com/gravitl/netmaker/serverctl"
 	"golang.org/x/exp/slog"
 )
 
@@ -34,9 +34,22 @@ var version = "v0.20.5"
 // Start DB Connection and start API Request Handler
 func main() {
 	absoluteConfigPath := flag.String("c", "", "absolute path to configuration file")
+	dryRun := flag.Bool("dry-run", false, "report pending database migrations without applying them and exit")
 	flag.Parse()
 	setupConfig(*absoluteConfigPath)
 	servercfg.SetVersion(version)
+	if *dryRun {
+		reportMigrations()
+		return
+	}
+	switch flag.Arg(0) {
+	case "migrate-db":
+		migrateDatabase(flag.Args()[1:])
+		return
+	case "rotate-db-key":
+		rotateDatabaseKey()
+		return
+	}
 	fmt.Println(models.RetrieveLogo()) // print the logo
 	initialize()                       // initial db and acls
 	setGarbageCollection()
@@ -47,12 +60,61 @@ func main() {
 	defer database.CloseDB()
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
 	defer stop()
+	logic.StartCacheSync(ctx) // pick up writes made by other server instances
+	database.StartReaper(ctx) // remove expired sso states, cache entries, host actions and enrollment keys
 	var waitGroup sync.WaitGroup
 	startControllers(&waitGroup, ctx) // start the api endpoint and mq and stun
 	<-ctx.Done()
 	waitGroup.Wait()
 }
 
+// reportMigrations - prints the database migrations that would be applied on startup
+func reportMigrations() {
+	if err := database.InitializeDatabase(); err != nil {
+		logger.FatalLog("Error connecting to database: ", err.Error())
+	}
+	defer database.CloseDB()
+	report, err := migrate.Report()
+	if err != nil {
+		logger.FatalLog("error checking database migrations: ", err.Error())
+	}
+	fmt.Print(report)
+}
+
+// migrateDatabase - copies all records from one database backend to another, ie. sqlite to postgres
+func migrateDatabase(args []string) {
+	flags := flag.NewFlagSet("migrate-db", flag.ExitOnError)
+	from := flags.String("from", "sqlite", "database to copy records from (sqlite, postgres, rqlite or bbolt)")
+	to := flags.String("to", "", "database to copy records to (sqlite, postgres, rqlite or bbolt)")
+	overwrite := flags.Bool("overwrite", false, "replace any records already present in the destination database")
+	flags.Parse(args)
+	if *to == "" {
+		logger.FatalLog("a destination database must be provided with -to")
+	}
+	results, err := database.CopyDatabase(*from, *to, *overwrite)
+	for _, result := range results {
+		fmt.Printf("%-16s %6d -> %d\n", result.Table, result.Source, result.Destination)
+	}
+	if err != nil {
+		logger.FatalLog("database migration failed: ", err.Error())
+	}
+	fmt.Printf("migrated %s to %s, set DATABASE=%s to use it\n", *from, *to, *to)
+}
+
+// rotateDatabaseKey - encrypts sensitive fields with the first configured database encryption key
+// configure the new key followed by the old one(s) before running this, the old keys can be dropped afterwards
+func rotateDatabaseKey() {
+	if err := database.InitializeDatabase(); err != nil {
+		logger.FatalLog("Error connecting to database: ", err.Error())
+	}
+	defer database.CloseDB()
+	count, err := database.EncryptSensitiveRecords()
+	if err != nil {
+		logger.FatalLog("failed to encrypt sensitive fields: ", err.Error())
+	}
+	fmt.Printf("encrypted %d records with the current database encryption key\n", count)
+}
+
 func setupConfig(absoluteConfigPath string) {
 	if len(absoluteConfigPath) > 0 {
 		cfg, err := config.ReadConfig(absoluteConfigPath)
@@ -79,7 +141,6 @@ func initialize() { // Client Mode Prereq Check
 		logger.FatalLog("Error connecting to database: ", err.Error())
 	}
 	logger.Log(0, "database successfully connected")
-	migrate.Run()
 
 	logic.SetJWTSecret()
 
@@ -100,9 +161,14 @@ func initialize() { // Client Mode Prereq Check
 		logger.Log(0, "no OAuth provider found or not configured, continuing without OAuth")
 	}
 
-	err = serverctl.SetDefaults()
-	if err != nil {
-		logger.FatalLog("error setting defaults: ", err.Error())
+	if err = migrate.Run(); err != nil {
+		logger.FatalLog("error migrating database: ", err.Error())
+	}
+
+	if interrupted, err := logic.FailInterruptedBulkJobs(); err != nil {
+		logger.Log(0, "failed to mark interrupted bulk jobs as failed:", err.Error())
+	} else if interrupted > 0 {
+		logger.Log(0, "marked", strconv.Itoa(interrupted), "bulk jobs interrupted by the restart as failed")
 	}
 
 	if servercfg.IsDNSMode() {
@@ -165,6 +231,8 @@ func runMessageQueue(wg *sync.WaitGroup, ctx context.Context) {
 	}
 	defer mq.CloseClient()
 	go mq.Keepalive(ctx)
+	go mq.RotateKeys(ctx)
+	go mq.RemoveEphemeralHosts(ctx)
 	go func() {
 		peerUpdate := make(chan *models.Node)
 		go logic.ManageZombies(ctx, peerUpdate)
@@ -2,49 +2,149 @@ package migrate
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/gravitl/netmaker/database"
 	"github.com/gravitl/netmaker/logger"
-	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
 )
 
-// Run - runs all migrations
-func Run() {
-	updateEnrollmentKeys()
+// SCHEMA_VERSION_KEY - serverconf record holding the schema version applied to the db
+const SCHEMA_VERSION_KEY = "schemaversion"
+
+// Migration - a numbered change to the data stored by the server, applied exactly once and in order
+type Migration struct {
+	Version     int
+	Description string
+	Run         func() error
 }
 
-func updateEnrollmentKeys() {
-	rows, err := database.FetchRecords(database.ENROLLMENT_KEYS_TABLE_NAME)
-	if err != nil {
-		return
+// SchemaVersion - the schema version recorded in the db
+type SchemaVersion struct {
+	Version       int       `json:"version"`
+	ServerVersion string    `json:"serverversion"`
+	UpdatedAt     time.Time `json:"updatedat"`
+}
+
+// ErrDowngrade - returned when the db has been migrated by a newer server
+var ErrDowngrade = errors.New("database schema is newer than this server supports, refusing to downgrade")
+
+// migrations - every migration in order, versions must increase and must never be reused or renumbered
+var migrations = []Migration{
+	{Version: 1, Description: "set enrollment key types", Run: updateEnrollmentKeys},
+	{Version: 2, Description: "set node defaults and missing node ACLs", Run: setNodeDefaults},
+	{Version: 3, Description: "set network defaults and network users", Run: setNetworkDefaults},
+	{Version: 4, Description: "set user defaults", Run: setUserDefaults},
+	{Version: 5, Description: "encrypt sensitive fields", Run: encryptSensitiveFields},
+}
+
+// LatestVersion - the schema version this server migrates the db to
+func LatestVersion() int {
+	if len(migrations) == 0 {
+		return 0
 	}
-	for _, row := range rows {
-		var key models.EnrollmentKey
-		if err = json.Unmarshal([]byte(row), &key); err != nil {
-			continue
+	return migrations[len(migrations)-1].Version
+}
+
+// GetSchemaVersion - fetches the schema version applied to the db, zero if never migrated
+func GetSchemaVersion() (SchemaVersion, error) {
+	var version SchemaVersion
+	record, err := database.FetchRecord(database.SERVERCONF_TABLE_NAME, SCHEMA_VERSION_KEY)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return version, nil
 		}
-		if key.Type != models.Undefined {
-			logger.Log(2, "migration: enrollment key type already set")
-			continue
-		} else {
-			logger.Log(2, "migration: updating enrollment key type")
-			if key.Unlimited {
-				key.Type = models.Unlimited
-			} else if key.UsesRemaining > 0 {
-				key.Type = models.Uses
-			} else if !key.Expiration.IsZero() {
-				key.Type = models.TimeExpiration
-			}
+		return version, err
+	}
+	err = json.Unmarshal([]byte(record), &version)
+	return version, err
+}
+
+func setSchemaVersion(version int) error {
+	data, err := json.Marshal(&SchemaVersion{
+		Version:       version,
+		ServerVersion: servercfg.GetVersion(),
+		UpdatedAt:     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return database.Insert(SCHEMA_VERSION_KEY, string(data), database.SERVERCONF_TABLE_NAME)
+}
+
+// Pending - returns the current schema version and the migrations that have not been applied yet
+func Pending() (SchemaVersion, []Migration, error) {
+	if err := validateMigrations(); err != nil {
+		return SchemaVersion{}, nil, err
+	}
+	current, err := GetSchemaVersion()
+	if err != nil {
+		return current, nil, err
+	}
+	if current.Version > LatestVersion() {
+		return current, nil, fmt.Errorf("%w: db is at schema version %d (server %s), this server supports up to %d",
+			ErrDowngrade, current.Version, current.ServerVersion, LatestVersion())
+	}
+	pending := []Migration{}
+	for _, m := range migrations {
+		if m.Version > current.Version {
+			pending = append(pending, m)
 		}
-		data, err := json.Marshal(key)
-		if err != nil {
-			logger.Log(0, "migration: marshalling enrollment key: "+err.Error())
-			continue
+	}
+	return current, pending, nil
+}
+
+// Run - applies all pending migrations in order, recording the schema version after each one
+func Run() error {
+	current, pending, err := Pending()
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		logger.Log(0, fmt.Sprintf("migration: applying %d - %s", m.Version, m.Description))
+		if err := m.Run(); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Description, err)
 		}
-		if err = database.Insert(key.Value, string(data), database.ENROLLMENT_KEYS_TABLE_NAME); err != nil {
-			logger.Log(0, "migration: inserting enrollment key: "+err.Error())
-			continue
+		if err := setSchemaVersion(m.Version); err != nil {
+			return fmt.Errorf("migration %d (%s) applied but schema version not recorded: %w", m.Version, m.Description, err)
 		}
+	}
+	if len(pending) > 0 {
+		logger.Log(0, fmt.Sprintf("migration: schema migrated from version %d to %d", current.Version, LatestVersion()))
+	}
+	return nil
+}
 
+// Report - returns a human readable report of the migrations Run would apply, without applying them
+func Report() (string, error) {
+	current, pending, err := Pending()
+	if err != nil {
+		return "", err
+	}
+	report := fmt.Sprintf("current schema version: %d\nlatest schema version: %d\n", current.Version, LatestVersion())
+	if len(pending) == 0 {
+		return report + "no pending migrations\n", nil
+	}
+	report += "pending migrations:\n"
+	for _, m := range pending {
+		report += fmt.Sprintf("  %d - %s\n", m.Version, m.Description)
+	}
+	return report, nil
+}
+
+// validateMigrations - ensures migration versions are strictly increasing
+func validateMigrations() error {
+	last := 0
+	for _, m := range migrations {
+		if m.Version <= last {
+			return fmt.Errorf("invalid migration order: version %d follows %d", m.Version, last)
+		}
+		if m.Run == nil {
+			return fmt.Errorf("migration %d has nothing to run", m.Version)
+		}
+		last = m.Version
 	}
+	return nil
 }
@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	database.InitializeDatabase()
+	defer database.CloseDB()
+	os.Exit(m.Run())
+}
+
+func useMigrations(t *testing.T, testMigrations []Migration) {
+	original := migrations
+	migrations = testMigrations
+	database.DeleteRecord(database.SERVERCONF_TABLE_NAME, SCHEMA_VERSION_KEY)
+	t.Cleanup(func() {
+		migrations = original
+		database.DeleteRecord(database.SERVERCONF_TABLE_NAME, SCHEMA_VERSION_KEY)
+	})
+}
+
+func TestRun(t *testing.T) {
+	runs := map[int]int{}
+	counter := func(version int) func() error {
+		return func() error {
+			runs[version]++
+			return nil
+		}
+	}
+	useMigrations(t, []Migration{
+		{Version: 1, Description: "one", Run: counter(1)},
+		{Version: 2, Description: "two", Run: counter(2)},
+	})
+	t.Run("AppliesInOrderOnce", func(t *testing.T) {
+		assert.Nil(t, Run())
+		assert.Nil(t, Run())
+		assert.Equal(t, map[int]int{1: 1, 2: 1}, runs)
+		current, err := GetSchemaVersion()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, current.Version)
+	})
+	t.Run("AppliesOnlyNewMigrations", func(t *testing.T) {
+		migrations = append(migrations, Migration{Version: 3, Description: "three", Run: counter(3)})
+		report, err := Report()
+		assert.Nil(t, err)
+		assert.Contains(t, report, "3 - three")
+		assert.Nil(t, Run())
+		assert.Equal(t, map[int]int{1: 1, 2: 1, 3: 1}, runs)
+	})
+	t.Run("RefusesDowngrade", func(t *testing.T) {
+		migrations = migrations[:2]
+		err := Run()
+		assert.True(t, errors.Is(err, ErrDowngrade))
+	})
+}
+
+func TestRunFailure(t *testing.T) {
+	failure := errors.New("failed")
+	useMigrations(t, []Migration{
+		{Version: 1, Description: "one", Run: func() error { return nil }},
+		{Version: 2, Description: "two", Run: func() error { return failure }},
+	})
+	err := Run()
+	assert.True(t, errors.Is(err, failure))
+	current, err := GetSchemaVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, current.Version)
+}
+
+func TestValidateMigrations(t *testing.T) {
+	useMigrations(t, []Migration{
+		{Version: 2, Description: "two", Run: func() error { return nil }},
+		{Version: 1, Description: "one", Run: func() error { return nil }},
+	})
+	assert.NotNil(t, Run())
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"net"
 	"strings"
+	"time"
 )
 
 // ApiHost - the host struct for API usage
@@ -31,6 +32,14 @@ type ApiHost struct {
 	IsRelay            bool     `json:"isrelay" bson:"isrelay" yaml:"isrelay"`
 	RelayedHosts       []string `json:"relay_hosts" bson:"relay_hosts" yaml:"relay_hosts"`
 	NatType            string   `json:"nat_type" yaml:"nat_type"`
+	// Labels - left out to keep the current labels, an empty object removes them
+	Labels map[string]string `json:"labels"`
+	// KeysUpdatedAt - when the host last changed its wireguard key, read only
+	KeysUpdatedAt time.Time `json:"keysupdatedat"`
+	// KeyRotationRequestedAt - when the host was asked to rotate its key, zero if no rotation is pending, read only
+	KeyRotationRequestedAt time.Time `json:"keyrotationrequestedat"`
+	// Ephemeral - the host is removed once it stays offline too long, read only
+	Ephemeral bool `json:"ephemeral"`
 }
 
 // Host.ConvertNMHostToAPI - converts a Netmaker host to an API editable host
@@ -62,6 +71,10 @@ func (h *Host) ConvertNMHostToAPI() *ApiHost {
 	a.Version = h.Version
 	a.IsDefault = h.IsDefault
 	a.NatType = h.NatType
+	a.Labels = h.Labels
+	a.KeysUpdatedAt = h.KeysUpdatedAt
+	a.KeyRotationRequestedAt = h.KeyRotationRequestedAt
+	a.Ephemeral = h.Ephemeral
 	return &a
 }
 
@@ -100,6 +113,13 @@ func (a *ApiHost) ConvertAPIHostToNMHost(currentHost *Host) *Host {
 	h.IsDefault = a.IsDefault
 	h.NatType = currentHost.NatType
 	h.TurnEndpoint = currentHost.TurnEndpoint
+	h.Labels = a.Labels
+	if h.Labels == nil {
+		h.Labels = currentHost.Labels
+	}
+	h.KeysUpdatedAt = currentHost.KeysUpdatedAt
+	h.KeyRotationRequestedAt = currentHost.KeyRotationRequestedAt
+	h.Ephemeral = currentHost.Ephemeral
 
 	return &h
 }
@@ -23,6 +23,7 @@ type ApiNode struct {
 	Network                 string   `json:"network"`
 	NetworkRange            string   `json:"networkrange"`
 	NetworkRange6           string   `json:"networkrange6"`
+	NetworkRanges           []string `json:"networkranges"`
 	IsRelayed               bool     `json:"isrelayed"`
 	IsRelay                 bool     `json:"isrelay"`
 	RelayedBy               string   `json:"relayedby" bson:"relayedby" yaml:"relayedby"`
@@ -41,6 +42,14 @@ type ApiNode struct {
 	// == PRO ==
 	DefaultACL string `json:"defaultacl,omitempty" validate:"checkyesornoorunset"`
 	Failover   bool   `json:"failover"`
+	// Labels - the labels of the node itself, left out to keep the current labels, an empty object removes them
+	Labels map[string]string `json:"labels"`
+	// IsPending - the node waits for an admin to approve it, read only
+	IsPending bool `json:"ispending"`
+	// Quarantined - the host of the node fails the posture policy of the network, read only
+	Quarantined bool `json:"quarantined"`
+	// PostureViolations - why the node is quarantined, read only
+	PostureViolations []string `json:"postureviolations,omitempty"`
 }
 
 // ApiNode.ConvertToServerNode - converts an api node to a server node
@@ -72,6 +81,13 @@ func (a *ApiNode) ConvertToServerNode(currentNode *Node) *Node {
 	convertedNode.RelayedNodes = a.RelayedNodes
 	convertedNode.DefaultACL = a.DefaultACL
 	convertedNode.OwnerID = currentNode.OwnerID
+	convertedNode.Labels = a.Labels
+	if convertedNode.Labels == nil {
+		convertedNode.Labels = currentNode.Labels
+	}
+	convertedNode.IsPending = currentNode.IsPending
+	convertedNode.Quarantined = currentNode.Quarantined
+	convertedNode.PostureViolations = currentNode.PostureViolations
 	_, networkRange, err := net.ParseCIDR(a.NetworkRange)
 	if err == nil {
 		convertedNode.NetworkRange = *networkRange
@@ -80,6 +96,12 @@ func (a *ApiNode) ConvertToServerNode(currentNode *Node) *Node {
 	if err == nil {
 		convertedNode.NetworkRange6 = *networkRange6
 	}
+	convertedNode.NetworkRanges = []net.IPNet{}
+	for _, secondaryRange := range a.NetworkRanges {
+		if _, cidr, err := net.ParseCIDR(secondaryRange); err == nil {
+			convertedNode.NetworkRanges = append(convertedNode.NetworkRanges, *cidr)
+		}
+	}
 	if len(a.LocalAddress) > 0 {
 		_, localAddr, err := net.ParseCIDR(a.LocalAddress)
 		if err == nil {
@@ -141,6 +163,10 @@ func (nm *Node) ConvertToAPINode() *ApiNode {
 	if isEmptyAddr(apiNode.NetworkRange6) {
 		apiNode.NetworkRange6 = ""
 	}
+	apiNode.NetworkRanges = []string{}
+	for _, secondaryRange := range nm.NetworkRanges {
+		apiNode.NetworkRanges = append(apiNode.NetworkRanges, secondaryRange.String())
+	}
 	apiNode.IsRelayed = nm.IsRelayed
 	apiNode.IsRelay = nm.IsRelay
 	apiNode.RelayedBy = nm.RelayedBy
@@ -164,6 +190,10 @@ func (nm *Node) ConvertToAPINode() *ApiNode {
 	apiNode.PendingDelete = nm.PendingDelete
 	apiNode.DefaultACL = nm.DefaultACL
 	apiNode.Failover = nm.Failover
+	apiNode.Labels = nm.Labels
+	apiNode.IsPending = nm.IsPending
+	apiNode.Quarantined = nm.Quarantined
+	apiNode.PostureViolations = nm.PostureViolations
 	return &apiNode
 }
 
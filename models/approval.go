@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ApprovalDecision - what an admin decided about a node pending approval
+type ApprovalDecision string
+
+const (
+	// NodeApproved - the node was approved and gets its peers
+	NodeApproved ApprovalDecision = "approved"
+	// NodeRejected - the node was rejected and removed from its network
+	NodeRejected ApprovalDecision = "rejected"
+)
+
+// ApprovalPolicy - whether hosts registering themselves into a network need an admin to approve their nodes
+type ApprovalPolicy struct {
+	RequiresApproval bool `json:"requiresapproval"`
+}
+
+// NodeApproval - an audit record of an admin approving or rejecting a node
+type NodeApproval struct {
+	ID       string           `json:"id"`
+	NodeID   string           `json:"nodeid"`
+	HostID   string           `json:"hostid"`
+	HostName string           `json:"hostname"`
+	Network  string           `json:"network"`
+	Decision ApprovalDecision `json:"decision"`
+	User     string           `json:"user"`
+	Time     time.Time        `json:"time"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// BACKUP_FORMAT_VERSION - version of the backup archive format written by this server
+const BACKUP_FORMAT_VERSION = 1
+
+// ServerBackup - export of every table of a netmaker server, including its UUID, traffic keys, JWT secret and certs
+type ServerBackup struct {
+	ServerVersion string                       `json:"serverversion"`
+	SchemaVersion int                          `json:"schemaversion"`
+	CreatedAt     time.Time                    `json:"createdat"`
+	Tables        map[string]map[string]string `json:"tables"`
+}
+
+// BackupArchive - versioned archive holding a gzipped ServerBackup, sealed with a passphrase when encrypted
+type BackupArchive struct {
+	FormatVersion int    `json:"formatversion"`
+	ServerVersion string `json:"serverversion"`
+	CreatedAt     int64  `json:"createdat"`
+	Encrypted     bool   `json:"encrypted"`
+	Salt          []byte `json:"salt,omitempty"`
+	Nonce         []byte `json:"nonce,omitempty"`
+	Data          []byte `json:"data"`
+}
+
+// BackupRequest - request body for creating a server backup
+type BackupRequest struct {
+	Passphrase string `json:"passphrase"`
+	// Insecure - allows an unencrypted archive when no passphrase is given, it holds the server's secrets in plain text
+	Insecure bool `json:"insecure"`
+}
+
+// RestoreRequest - request body for restoring a server backup
+type RestoreRequest struct {
+	Archive    BackupArchive `json:"archive"`
+	Passphrase string        `json:"passphrase"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// BulkOperation - an action run against every host picked by a bulk request
+type BulkOperation string
+
+const (
+	// BulkAddToNetwork - adds the hosts to a network
+	BulkAddToNetwork BulkOperation = "addnetwork"
+	// BulkRemoveFromNetwork - removes the hosts from a network
+	BulkRemoveFromNetwork BulkOperation = "removenetwork"
+	// BulkRefreshKeys - makes the hosts generate new wireguard keys
+	BulkRefreshKeys BulkOperation = "refreshkeys"
+	// BulkUpdateSettings - sets the verbosity, MTU or auto update of the hosts
+	BulkUpdateSettings BulkOperation = "update"
+	// BulkDelete - deletes the hosts
+	BulkDelete BulkOperation = "delete"
+)
+
+// BulkJobStatus - the progress of a bulk job or of one of its hosts
+type BulkJobStatus string
+
+const (
+	// BulkPending - not started yet
+	BulkPending BulkJobStatus = "pending"
+	// BulkRunning - the job is working through its hosts
+	BulkRunning BulkJobStatus = "running"
+	// BulkSucceeded - the job or host finished without errors
+	BulkSucceeded BulkJobStatus = "succeeded"
+	// BulkFailed - the host failed, or at least one host of the job failed
+	BulkFailed BulkJobStatus = "failed"
+)
+
+// HostFilter - picks hosts for a bulk operation, empty fields match every host
+type HostFilter struct {
+	// Network - hosts with a node in the network
+	Network string `json:"network,omitempty"`
+	OS      string `json:"os,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Selector - a label selector like env=prod,role!=db
+	Selector string `json:"selector,omitempty"`
+}
+
+// BulkHostRequest - an operation to run against every host matching a filter
+type BulkHostRequest struct {
+	Filter    HostFilter    `json:"filter"`
+	Operation BulkOperation `json:"operation"`
+	// Network - the network hosts are added to or removed from
+	Network    string `json:"network,omitempty"`
+	Verbosity  *int   `json:"verbosity,omitempty"`
+	MTU        *int   `json:"mtu,omitempty"`
+	AutoUpdate *bool  `json:"autoupdate,omitempty"`
+	// Force - deletes nodes and hosts without waiting for their hosts to acknowledge
+	Force bool `json:"force,omitempty"`
+}
+
+// BulkHostResult - the outcome of a bulk operation on one host
+type BulkHostResult struct {
+	HostID   string        `json:"hostid"`
+	HostName string        `json:"hostname"`
+	Status   BulkJobStatus `json:"status"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// BulkJob - a bulk operation tracked with the result of every host it ran against
+type BulkJob struct {
+	ID         string           `json:"id"`
+	Request    BulkHostRequest  `json:"request"`
+	Status     BulkJobStatus    `json:"status"`
+	Total      int              `json:"total"`
+	Succeeded  int              `json:"succeeded"`
+	Failed     int              `json:"failed"`
+	Results    []BulkHostResult `json:"results"`
+	CreatedBy  string           `json:"createdby,omitempty"`
+	CreatedAt  time.Time        `json:"createdat"`
+	FinishedAt *time.Time       `json:"finishedat,omitempty"`
+}
@@ -42,8 +42,8 @@ type DNSUpdate struct {
 
 // DNSEntry - a DNS entry represented as struct
 type DNSEntry struct {
-	Address  string `json:"address" bson:"address" validate:"ip"`
-	Address6 string `json:"address6" bson:"address6"`
+	Address  string `json:"address" bson:"address" validate:"required_without=Address6,omitempty,ip"`
+	Address6 string `json:"address6" bson:"address6" validate:"omitempty,ipv6"`
 	Name     string `json:"name" bson:"name" validate:"required,name_unique,min=1,max=192"`
 	Network  string `json:"network" bson:"network" validate:"network_exists"`
 }
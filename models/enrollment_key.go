@@ -39,6 +39,10 @@ type EnrollmentKey struct {
 	Tags          []string  `json:"tags"`
 	Token         string    `json:"token,omitempty"` // B64 value of EnrollmentToken
 	Type          KeyType   `json:"type"`
+	// RequiresApproval - the nodes of hosts registered with the key stay pending until an admin approves them
+	RequiresApproval bool `json:"requires_approval"`
+	// Ephemeral - hosts registered with the key are removed once they stay offline longer than the grace period
+	Ephemeral bool `json:"ephemeral"`
 }
 
 // APIEnrollmentKey - used to create enrollment keys via API
@@ -49,6 +53,10 @@ type APIEnrollmentKey struct {
 	Unlimited     bool     `json:"unlimited"`
 	Tags          []string `json:"tags"`
 	Type          KeyType  `json:"type"`
+	// RequiresApproval - the nodes of hosts registered with the key stay pending until an admin approves them
+	RequiresApproval bool `json:"requires_approval"`
+	// Ephemeral - hosts registered with the key are removed once they stay offline longer than the grace period
+	Ephemeral bool `json:"ephemeral"`
 }
 
 // RegisterResponse - the response to a successful enrollment register
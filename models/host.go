@@ -3,6 +3,7 @@ package models
 import (
 	"net"
 	"net/netip"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
@@ -66,6 +67,14 @@ type Host struct {
 	IsDefault          bool             `json:"isdefault" yaml:"isdefault"`
 	NatType            string           `json:"nat_type,omitempty" yaml:"nat_type,omitempty"`
 	TurnEndpoint       *netip.AddrPort  `json:"turn_endpoint,omitempty" yaml:"turn_endpoint,omitempty"`
+	// Labels - key/value pairs hosts are selected by, the nodes of a host share its labels
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// KeysUpdatedAt - when the server last saw the host change its wireguard key, zero if not known
+	KeysUpdatedAt time.Time `json:"keysupdatedat" yaml:"keysupdatedat"`
+	// KeyRotationRequestedAt - when the host was last asked to rotate its key, zero once it did
+	KeyRotationRequestedAt time.Time `json:"keyrotationrequestedat" yaml:"keyrotationrequestedat"`
+	// Ephemeral - the host registered with an ephemeral enrollment key and is removed once it stays offline too long
+	Ephemeral bool `json:"ephemeral" yaml:"ephemeral"`
 }
 
 // FormatBool converts a boolean to a [yes|no] string
@@ -0,0 +1,15 @@
+package models
+
+// AddressRange - an inclusive range of addresses of a network
+type AddressRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// AddressReservation - an address of a network that is only handed out to the host it is bound to
+type AddressReservation struct {
+	Address     string `json:"address"`
+	HostID      string `json:"hostid,omitempty"`
+	HostName    string `json:"hostname,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// KeyRotationPolicy - how often the hosts of a network rotate their wireguard keys
+type KeyRotationPolicy struct {
+	// IntervalDays - the maximum age of a key in days, 0 disables rotation
+	IntervalDays int32 `json:"intervaldays"`
+}
+
+// HostKeyStatus - the age of the wireguard key of a host and when it is due for rotation
+type HostKeyStatus struct {
+	HostID   string `json:"hostid"`
+	HostName string `json:"hostname"`
+	// KeysUpdatedAt - when the host last changed its key, zero if not known
+	KeysUpdatedAt time.Time `json:"keysupdatedat"`
+	// KeyAgeDays - the age of the key in whole days, -1 if not known
+	KeyAgeDays int `json:"keyagedays"`
+	// IntervalDays - the shortest rotation interval of the networks of the host, it applies to the key of the host
+	IntervalDays int32     `json:"intervaldays"`
+	DueAt        time.Time `json:"dueat"`
+	Overdue      bool      `json:"overdue"`
+	// RotationRequestedAt - when the host was asked to rotate its key, zero if no rotation is pending
+	RotationRequestedAt time.Time `json:"rotationrequestedat"`
+}
+
+// KeyRotationStatus - the key rotation policy of a network and the key age of each of its hosts
+type KeyRotationStatus struct {
+	Network      string          `json:"network"`
+	IntervalDays int32           `json:"intervaldays"`
+	Hosts        []HostKeyStatus `json:"hosts"`
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	labelKeyRegex   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+	labelValueRegex = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// LabelOperator - how a requirement of a label selector compares a label
+type LabelOperator string
+
+const (
+	// LabelEquals - the label is set to the value
+	LabelEquals LabelOperator = "="
+	// LabelNotEquals - the label is not set to the value, hosts and nodes without the label match
+	LabelNotEquals LabelOperator = "!="
+	// LabelExists - the label is set to any value
+	LabelExists LabelOperator = "exists"
+	// LabelNotExists - the label is not set
+	LabelNotExists LabelOperator = "!exists"
+)
+
+// LabelRequirement - a single condition of a label selector
+type LabelRequirement struct {
+	Key      string        `json:"key"`
+	Operator LabelOperator `json:"operator"`
+	Value    string        `json:"value"`
+}
+
+// LabelSelector - selects hosts and nodes whose labels meet all of its requirements
+type LabelSelector []LabelRequirement
+
+// SelectorACLRequest - allows or denies traffic between every node matching one label selector and every node
+// matching the other
+type SelectorACLRequest struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Allowed bool   `json:"allowed"`
+}
+
+// ParseLabelSelector - parses a comma separated selector like env=prod,role!=db,backup,!legacy
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	requirements := LabelSelector{}
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var requirement LabelRequirement
+		switch {
+		case strings.Contains(part, "!="):
+			key, value, _ := strings.Cut(part, "!=")
+			requirement = LabelRequirement{Key: key, Operator: LabelNotEquals, Value: value}
+		case strings.Contains(part, "=="):
+			key, value, _ := strings.Cut(part, "==")
+			requirement = LabelRequirement{Key: key, Operator: LabelEquals, Value: value}
+		case strings.Contains(part, "="):
+			key, value, _ := strings.Cut(part, "=")
+			requirement = LabelRequirement{Key: key, Operator: LabelEquals, Value: value}
+		case strings.HasPrefix(part, "!"):
+			requirement = LabelRequirement{Key: part[1:], Operator: LabelNotExists}
+		default:
+			requirement = LabelRequirement{Key: part, Operator: LabelExists}
+		}
+		requirement.Key = strings.TrimSpace(requirement.Key)
+		requirement.Value = strings.TrimSpace(requirement.Value)
+		if !labelKeyRegex.MatchString(requirement.Key) {
+			return nil, fmt.Errorf("invalid label key %q in selector", requirement.Key)
+		}
+		if !labelValueRegex.MatchString(requirement.Value) {
+			return nil, fmt.Errorf("invalid label value %q in selector", requirement.Value)
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+// LabelSelector.Matches - checks if labels meet every requirement, an empty selector matches everything
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		value, ok := labels[requirement.Key]
+		switch requirement.Operator {
+		case LabelEquals:
+			if !ok || value != requirement.Value {
+				return false
+			}
+		case LabelNotEquals:
+			if ok && value == requirement.Value {
+				return false
+			}
+		case LabelExists:
+			if !ok {
+				return false
+			}
+		case LabelNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// LabelSelector.String - formats a selector the way it is parsed
+func (s LabelSelector) String() string {
+	parts := []string{}
+	for _, requirement := range s {
+		switch requirement.Operator {
+		case LabelExists:
+			parts = append(parts, requirement.Key)
+		case LabelNotExists:
+			parts = append(parts, "!"+requirement.Key)
+		default:
+			parts = append(parts, requirement.Key+string(requirement.Operator)+requirement.Value)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ValidateLabels - checks the keys and values of labels, keys are up to 63 alphanumeric characters with ., _, / or -
+// inside, values are the same without / and may be empty
+func ValidateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if !labelKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid label key %q", key)
+		}
+		if !labelValueRegex.MatchString(value) {
+			return fmt.Errorf("invalid value %q of label %s", value, key)
+		}
+	}
+	return nil
+}
+
+// LabelsFromTags - turns enrollment key tags into labels, key=value tags become a label of that value and other tags
+// a label with an empty value, tags that are not valid labels are returned separately
+func LabelsFromTags(tags []string) (labels map[string]string, invalid []string) {
+	labels = map[string]string{}
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !labelKeyRegex.MatchString(key) || !labelValueRegex.MatchString(value) {
+			invalid = append(invalid, tag)
+			continue
+		}
+		labels[key] = value
+	}
+	return labels, invalid
+}
+
+// MergeLabels - returns the labels of base overridden by the labels of overlay
+func MergeLabels(base, overlay map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		merged[key] = value
+	}
+	return merged
+}
+
+// FormatLabels - formats labels as a sorted, comma separated list of key=value pairs
+func FormatLabels(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for key, value := range labels {
+		parts = append(parts, key+"="+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
@@ -1,16 +1,21 @@
 package models
 
 import (
+	"net"
 	"time"
 
 	"github.com/gravitl/netmaker/models/promodels"
 )
 
+// ULAAddressRange6 - the AddressRange6 of a new network which asks the server to generate a unique local ipv6 range
+const ULAAddressRange6 = "ula"
+
 // Network Struct - contains info for a given unique network
 // At  some point, need to replace all instances of Name with something else like  Identifier
 type Network struct {
 	AddressRange        string                `json:"addressrange" bson:"addressrange" validate:"omitempty,cidrv4"`
 	AddressRange6       string                `json:"addressrange6" bson:"addressrange6" validate:"omitempty,cidrv6"`
+	SecondaryRanges     []string              `json:"secondaryranges" bson:"secondaryranges" validate:"omitempty,dive,cidr"`
 	NetID               string                `json:"netid" bson:"netid" validate:"required,min=1,max=12,netid_valid"`
 	NodesLastModified   int64                 `json:"nodeslastmodified" bson:"nodeslastmodified"`
 	NetworkLastModified int64                 `json:"networklastmodified" bson:"networklastmodified"`
@@ -26,6 +31,14 @@ type Network struct {
 	DefaultMTU          int32                 `json:"defaultmtu" bson:"defaultmtu"`
 	DefaultACL          string                `json:"defaultacl" bson:"defaultacl" yaml:"defaultacl" validate:"checkyesorno"`
 	ProSettings         *promodels.ProNetwork `json:"prosettings,omitempty" bson:"prosettings,omitempty" yaml:"prosettings,omitempty"`
+	// KeyRotationDays - how many days the wireguard keys of the hosts of the network are kept before they are rotated,
+	// 0 disables rotation
+	KeyRotationDays int32 `json:"keyrotationdays" bson:"keyrotationdays" yaml:"keyrotationdays" validate:"omitempty,min=1,max=3650"`
+	// RequiresApproval - nodes of hosts that join the network on their own, with an enrollment key or SSO, stay
+	// pending until an admin approves them
+	RequiresApproval bool `json:"requiresapproval" bson:"requiresapproval" yaml:"requiresapproval"`
+	// PosturePolicy - the requirements hosts must meet for their nodes in the network to get peers
+	PosturePolicy *PosturePolicy `json:"posturepolicy,omitempty" bson:"posturepolicy,omitempty" yaml:"posturepolicy,omitempty"`
 }
 
 // SaveData - sensitive fields of a network that should be kept the same
@@ -43,6 +56,28 @@ func (network *Network) SetNetworkLastModified() {
 	network.NetworkLastModified = time.Now().Unix()
 }
 
+// Network.AllAddressRanges - returns the primary ranges of a network followed by its secondary ranges
+func (network *Network) AllAddressRanges() []string {
+	ranges := []string{}
+	for _, addressRange := range append([]string{network.AddressRange, network.AddressRange6}, network.SecondaryRanges...) {
+		if addressRange != "" {
+			ranges = append(ranges, addressRange)
+		}
+	}
+	return ranges
+}
+
+// Network.SecondaryNetworks - returns the parsed secondary ranges of a network, invalid ranges are skipped
+func (network *Network) SecondaryNetworks() []net.IPNet {
+	networks := []net.IPNet{}
+	for _, addressRange := range network.SecondaryRanges {
+		if _, cidr, err := net.ParseCIDR(addressRange); err == nil {
+			networks = append(networks, *cidr)
+		}
+	}
+	return networks
+}
+
 // Network.SetDefaults - sets default values for a network struct
 func (network *Network) SetDefaults() {
 	if network.DefaultUDPHolePunch == "" {
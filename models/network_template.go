@@ -0,0 +1,37 @@
+package models
+
+// NetworkTemplate - the settings new networks are created with, a network created from a template only brings its
+// own name and address ranges
+type NetworkTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Network - the network defaults, pro settings and default ACL policy, its address ranges are only used to move
+	// the DNS entries of the template into the ranges of a new network
+	Network        Network                 `json:"network"`
+	DNS            []DNSEntry              `json:"dns"`
+	EnrollmentKeys []EnrollmentKeyTemplate `json:"enrollmentkeys"`
+}
+
+// EnrollmentKeyTemplate - an enrollment key that is created for every network created from a template
+type EnrollmentKeyTemplate struct {
+	UsesRemaining int  `json:"uses_remaining"`
+	Unlimited     bool `json:"unlimited"`
+	// Validity - the number of seconds a time based key stays valid after the network is created
+	Validity         int64    `json:"validity"`
+	Tags             []string `json:"tags"`
+	RequiresApproval bool     `json:"requires_approval"`
+	Ephemeral        bool     `json:"ephemeral"`
+}
+
+// NetworkTemplateRequest - saves an existing network as a template
+type NetworkTemplateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// NetworkCloneRequest - the name and address ranges of a network cloned from another one
+type NetworkCloneRequest struct {
+	NetID         string `json:"netid"`
+	AddressRange  string `json:"addressrange"`
+	AddressRange6 string `json:"addressrange6"`
+}
@@ -21,8 +21,6 @@ const (
 	// == ACTIONS == (can only be set by server)
 	// NODE_DELETE - delete node action
 	NODE_DELETE = "delete"
-	// NODE_IS_PENDING - node pending status
-	NODE_IS_PENDING = "pending"
 	// NODE_NOOP - node no op action
 	NODE_NOOP = "noop"
 	// NODE_FORCE_UPDATE - indicates a node should pull all changes
@@ -59,6 +57,7 @@ type CommonNode struct {
 	Network             string        `json:"network" yaml:"network"`
 	NetworkRange        net.IPNet     `json:"networkrange" yaml:"networkrange"`
 	NetworkRange6       net.IPNet     `json:"networkrange6" yaml:"networkrange6"`
+	NetworkRanges       []net.IPNet   `json:"networkranges" yaml:"networkranges"`
 	InternetGateway     *net.UDPAddr  `json:"internetgateway" yaml:"internetgateway"`
 	Server              string        `json:"server" yaml:"server"`
 	Connected           bool          `json:"connected" yaml:"connected"`
@@ -95,6 +94,15 @@ type Node struct {
 	OwnerID      string    `json:"ownerid,omitempty" bson:"ownerid,omitempty" yaml:"ownerid,omitempty"`
 	FailoverNode uuid.UUID `json:"failovernode" bson:"failovernode" yaml:"failovernode"`
 	Failover     bool      `json:"failover" bson:"failover" yaml:"failover"`
+	// Labels - key/value pairs nodes are selected by, they take precedence over the labels of the host
+	Labels map[string]string `json:"labels,omitempty" bson:"labels,omitempty" yaml:"labels,omitempty"`
+	// IsPending - the node gets no peers and is no peer of other nodes until an admin approves it
+	IsPending bool `json:"ispending" bson:"ispending" yaml:"ispending"`
+	// Quarantined - the host of the node fails the posture policy of the network, the node gets no peers and is no
+	// peer of other nodes until the host complies
+	Quarantined bool `json:"quarantined" bson:"quarantined" yaml:"quarantined"`
+	// PostureViolations - why the node is quarantined
+	PostureViolations []string `json:"postureviolations,omitempty" bson:"postureviolations,omitempty" yaml:"postureviolations,omitempty"`
 }
 
 // LegacyNode - legacy struct for node model
@@ -389,6 +397,14 @@ func (newNode *Node) Fill(currentNode *Node, isEE bool) { // TODO add new field
 	if newNode.Network == "" {
 		newNode.Network = currentNode.Network
 	}
+	if newNode.Labels == nil {
+		newNode.Labels = currentNode.Labels
+	}
+	// only approving or rejecting the node changes its approval
+	newNode.IsPending = currentNode.IsPending
+	// only posture checks quarantine the node
+	newNode.Quarantined = currentNode.Quarantined
+	newNode.PostureViolations = currentNode.PostureViolations
 	if newNode.IsEgressGateway != currentNode.IsEgressGateway {
 		newNode.IsEgressGateway = currentNode.IsEgressGateway
 	}
@@ -591,6 +607,7 @@ func (node *Node) NetworkSettings(n Network) {
 	if err == nil {
 		node.NetworkRange6 = *cidr
 	}
+	node.NetworkRanges = n.SecondaryNetworks()
 }
 
 func parseBool(s string) bool {
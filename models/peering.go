@@ -0,0 +1,33 @@
+package models
+
+// NetworkPeering - routes traffic between two networks through a gateway host that has a node in both, each
+// gateway node carries the address ranges of the other network as egress routes
+type NetworkPeering struct {
+	ID       string `json:"id"`
+	NetworkA string `json:"network_a"`
+	// GatewayA - the id of the gateway node in network A
+	GatewayA string `json:"gateway_a"`
+	NetworkB string `json:"network_b"`
+	// GatewayB - the id of the gateway node in network B
+	GatewayB  string `json:"gateway_b"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// NetworkPeeringRequest - the networks and gateway nodes of a new peering
+type NetworkPeeringRequest struct {
+	NetworkA string `json:"network_a"`
+	GatewayA string `json:"gateway_a"`
+	NetworkB string `json:"network_b"`
+	GatewayB string `json:"gateway_b"`
+}
+
+// NetworkPeering.Gateway - returns the gateway node of a network of the peering and the network on the other side
+func (p *NetworkPeering) Gateway(network string) (gateway, remote string) {
+	switch network {
+	case p.NetworkA:
+		return p.GatewayA, p.NetworkB
+	case p.NetworkB:
+		return p.GatewayB, p.NetworkA
+	}
+	return "", ""
+}
@@ -0,0 +1,37 @@
+package models
+
+// PosturePolicy - the requirements the hosts of a network must meet, nodes of hosts that fail any of them are
+// quarantined until they comply
+type PosturePolicy struct {
+	// MinVersion - the oldest netclient version allowed, e.g. v0.20.0
+	MinVersion string `json:"minversion,omitempty" bson:"minversion,omitempty" yaml:"minversion,omitempty"`
+	// AllowedOS - the operating systems hosts may run, any if empty
+	AllowedOS []string `json:"allowedos,omitempty" bson:"allowedos,omitempty" yaml:"allowedos,omitempty"`
+	// Firewall - the firewall hosts must use, e.g. nftables
+	Firewall string `json:"firewall,omitempty" bson:"firewall,omitempty" yaml:"firewall,omitempty"`
+	// DenyDocker - hosts running in a docker container are not allowed
+	DenyDocker bool `json:"denydocker,omitempty" bson:"denydocker,omitempty" yaml:"denydocker,omitempty"`
+	// RequireDaemon - the netclient must be installed as a daemon
+	RequireDaemon bool `json:"requiredaemon,omitempty" bson:"requiredaemon,omitempty" yaml:"requiredaemon,omitempty"`
+}
+
+// IsEmpty - checks if a policy has no requirements
+func (p *PosturePolicy) IsEmpty() bool {
+	return p == nil || (p.MinVersion == "" && len(p.AllowedOS) == 0 && p.Firewall == "" && !p.DenyDocker && !p.RequireDaemon)
+}
+
+// NodePosture - whether the host of a node meets the posture policy of the network of the node
+type NodePosture struct {
+	NodeID      string   `json:"nodeid"`
+	HostID      string   `json:"hostid"`
+	HostName    string   `json:"hostname"`
+	Quarantined bool     `json:"quarantined"`
+	Violations  []string `json:"violations"`
+}
+
+// PostureStatus - the posture policy of a network and the compliance of each of its nodes
+type PostureStatus struct {
+	Network string         `json:"network"`
+	Policy  *PosturePolicy `json:"policy"`
+	Nodes   []NodePosture  `json:"nodes"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// RenumberRequest - the new address ranges of a network, an empty range keeps the current one
+type RenumberRequest struct {
+	AddressRange  string `json:"addressrange"`
+	AddressRange6 string `json:"addressrange6"`
+}
+
+// AddressChangeKind - the type of object whose address changes when a network is renumbered
+type AddressChangeKind string
+
+const (
+	// AddressChangeNode - the address of a node
+	AddressChangeNode AddressChangeKind = "node"
+	// AddressChangeExtClient - the address of an ext client
+	AddressChangeExtClient AddressChangeKind = "extclient"
+	// AddressChangeDNS - the address of a custom DNS entry
+	AddressChangeDNS AddressChangeKind = "dns"
+	// AddressChangeReservation - an address reserved for a host or a reserved address range
+	AddressChangeReservation AddressChangeKind = "reservation"
+	// AddressChangeEgressRange - an egress gateway range that lies within the network range
+	AddressChangeEgressRange AddressChangeKind = "egressrange"
+	// AddressChangeIngressRange - the range an ingress gateway hands to its ext clients
+	AddressChangeIngressRange AddressChangeKind = "ingressrange"
+	// AddressChangeAllowedIP - an extra allowed ip of an ext client that lies within the network range
+	AddressChangeAllowedIP AddressChangeKind = "allowedip"
+)
+
+// AddressChange - how one address or range of a network changes when it is renumbered
+type AddressChange struct {
+	Kind AddressChangeKind `json:"kind"`
+	ID   string            `json:"id"`
+	Name string            `json:"name,omitempty"`
+	Old  string            `json:"old"`
+	// New - the new address or range, empty if the reserved range no longer fits the network and is dropped
+	New string `json:"new"`
+}
+
+// RenumberPlan - the address changes of a network moving to new address ranges
+type RenumberPlan struct {
+	Network          string          `json:"network"`
+	AddressRange     string          `json:"addressrange"`
+	AddressRange6    string          `json:"addressrange6"`
+	NewAddressRange  string          `json:"newaddressrange"`
+	NewAddressRange6 string          `json:"newaddressrange6"`
+	Changes          []AddressChange `json:"changes"`
+	Applied          bool            `json:"applied"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// TrashKind - the type of a deleted object kept in the trash
+type TrashKind string
+
+const (
+	// TrashNetwork - a deleted network with its ACLs, network users and custom DNS entries
+	TrashNetwork TrashKind = "network"
+	// TrashHost - a deleted host with its nodes, their ACL rows and the ext clients of its gateways
+	TrashHost TrashKind = "host"
+	// TrashExtClient - a deleted ext client
+	TrashExtClient TrashKind = "extclient"
+)
+
+// TrashItem - a deleted object that can be restored until it expires
+type TrashItem struct {
+	ID        string    `json:"id"`
+	Kind      TrashKind `json:"kind"`
+	Name      string    `json:"name"`
+	Network   string    `json:"network,omitempty"`
+	DeletedBy string    `json:"deletedby,omitempty"`
+	DeletedAt time.Time `json:"deletedat"`
+	ExpiresAt time.Time `json:"expiresat"`
+}
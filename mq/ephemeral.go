@@ -0,0 +1,60 @@
+package mq
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
+	"golang.org/x/exp/slog"
+)
+
+// RemoveEphemeralHosts - periodically removes the ephemeral hosts that have been offline longer than the ephemeral
+// host timeout and tells their peers
+func RemoveEphemeralHosts(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(logic.EphemeralHostCheckInterval):
+			hosts, err := logic.ExpiredEphemeralHosts(time.Now())
+			if err != nil {
+				slog.Error("failed to check for expired ephemeral hosts", "error", err)
+				continue
+			}
+			removed := false
+			for i := range hosts {
+				if removeEphemeralHost(&hosts[i]) {
+					removed = true
+				}
+			}
+			if removed {
+				if err := PublishPeerUpdate(); err != nil {
+					slog.Error("failed to publish peer update", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// removeEphemeralHost - removes an ephemeral host with its nodes and MQ credentials and deletes the DNS entries of
+// its nodes from their networks
+func removeEphemeralHost(host *models.Host) bool {
+	if servercfg.GetBrokerType() == servercfg.EmqxBrokerType {
+		if err := DeleteEmqxUser(host.ID.String()); err != nil {
+			slog.Error("failed to remove host credentials from EMQX", "id", host.ID, "error", err)
+		}
+	}
+	nodes, err := logic.RemoveEphemeralHost(host)
+	if err != nil {
+		slog.Error("failed to remove ephemeral host", "name", host.Name, "id", host.ID, "error", err)
+		return false
+	}
+	for i := range nodes {
+		if err := PublishDNSDelete(&nodes[i], host); err != nil {
+			slog.Error("failed to publish dns delete", "node", nodes[i].ID, "error", err)
+		}
+	}
+	return true
+}
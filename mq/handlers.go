@@ -390,7 +390,8 @@ func handleNewNodeDNS(host *models.Host, node *models.Node) error {
 		if err := PublishDNSUpdate(node.Network, dns); err != nil {
 			return err
 		}
-	} else if node.Address6.IP != nil {
+	}
+	if node.Address6.IP != nil {
 		dns.Address = node.Address6.IP.String()
 		if err := PublishDNSUpdate(node.Network, dns); err != nil {
 			return err
@@ -430,10 +431,13 @@ func handleHostCheckin(h, currentHost *models.Host) bool {
 	for i := range h.Interfaces {
 		h.Interfaces[i].AddressString = h.Interfaces[i].Address.String()
 	}
-	/// version or firewall in use change does not require a peerUpdate
-	if h.Version != currentHost.Version || h.FirewallInUse != currentHost.FirewallInUse {
+	/// version, firewall in use or daemon change does not require a peerUpdate unless it changes the posture of the host
+	if h.Version != currentHost.Version || h.FirewallInUse != currentHost.FirewallInUse ||
+		h.DaemonInstalled != currentHost.DaemonInstalled || h.IsDocker != currentHost.IsDocker {
 		currentHost.FirewallInUse = h.FirewallInUse
 		currentHost.Version = h.Version
+		currentHost.DaemonInstalled = h.DaemonInstalled
+		currentHost.IsDocker = h.IsDocker
 		if err := logic.UpsertHost(currentHost); err != nil {
 			slog.Error("failed to update host after check-in", "name", h.Name, "id", h.ID, "error", err)
 			return false
@@ -461,7 +465,14 @@ func handleHostCheckin(h, currentHost *models.Host) bool {
 		}
 		slog.Info("updated host after check-in", "name", currentHost.Name, "id", currentHost.ID)
 	}
+	if logic.KeyRotationDue(currentHost, time.Now(), nil) {
+		requestKeyRotation(currentHost)
+	}
+	quarantineDelta, err := logic.EvaluateHostPosture(currentHost)
+	if err != nil {
+		slog.Error("failed to check posture of host", "name", h.Name, "id", h.ID, "error", err)
+	}
 
 	slog.Info("check-in processed for host", "name", h.Name, "id", h.ID)
-	return ifaceDelta
+	return ifaceDelta || quarantineDelta
 }
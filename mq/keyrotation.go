@@ -0,0 +1,44 @@
+package mq
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+	"golang.org/x/exp/slog"
+)
+
+// RotateKeys - periodically asks online hosts whose keys are older than the key rotation policy of their networks to
+// rotate them, hosts that are offline are asked once they check in
+func RotateKeys(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(logic.KeyRotationCheckInterval):
+			hosts, err := logic.DueKeyRotations(time.Now())
+			if err != nil {
+				slog.Error("failed to check hosts for key rotation", "error", err)
+				continue
+			}
+			for i := range hosts {
+				requestKeyRotation(&hosts[i])
+			}
+		}
+	}
+}
+
+// requestKeyRotation - sends the update keys action to a host and records the request
+func requestKeyRotation(host *models.Host) {
+	if err := HostUpdate(&models.HostUpdate{
+		Action: models.UpdateKeys,
+		Host:   *host,
+	}); err != nil {
+		slog.Error("failed to request key rotation", "name", host.Name, "id", host.ID, "error", err)
+		return
+	}
+	if err := logic.KeyRotationRequested(host); err != nil {
+		slog.Error("failed to save key rotation request", "name", host.Name, "id", host.ID, "error", err)
+	}
+}
@@ -192,6 +192,44 @@ func PublishDNSUpdate(network string, dns models.DNSUpdate) error {
 	return nil
 }
 
+// PublishRenumber pushes the new addresses of a renumbered network to its hosts and replaces their dns entries
+func PublishRenumber(plan *models.RenumberPlan) error {
+	updated := map[string]bool{}
+	for _, change := range plan.Changes {
+		switch change.Kind {
+		case models.AddressChangeNode, models.AddressChangeEgressRange, models.AddressChangeIngressRange:
+			if updated[change.ID] {
+				break
+			}
+			updated[change.ID] = true
+			node, err := logic.GetNodeByID(change.ID)
+			if err != nil {
+				logger.Log(0, "failed to fetch renumbered node", change.ID, err.Error())
+				break
+			}
+			if err = NodeUpdate(&node); err != nil {
+				logger.Log(0, "failed to publish renumbered node", change.ID, err.Error())
+			}
+		}
+		switch change.Kind {
+		case models.AddressChangeNode, models.AddressChangeExtClient, models.AddressChangeDNS:
+			if change.Name == "" {
+				continue
+			}
+			dns := models.DNSUpdate{
+				Action:     models.DNSReplaceIP,
+				Name:       change.Name + "." + plan.Network,
+				Address:    change.Old,
+				NewAddress: change.New,
+			}
+			if err := PublishDNSUpdate(plan.Network, dns); err != nil {
+				logger.Log(0, "failed to publish dns update for", dns.Name, err.Error())
+			}
+		}
+	}
+	return PublishPeerUpdate()
+}
+
 // PublishAllDNS publishes an array of dns updates (ip / host.network) for each peer to a node joining a network
 func PublishAllDNS(newnode *models.Node) error {
 	alldns := []models.DNSUpdate{}
@@ -314,11 +352,15 @@ func PublishCustomDNS(entry *models.DNSEntry) error {
 	dns := models.DNSUpdate{
 		Action: models.DNSInsert,
 		Name:   entry.Name + "." + entry.Network,
-		//entry.Address6 is never used
-		Address: entry.Address,
 	}
-	if err := PublishDNSUpdate(entry.Network, dns); err != nil {
-		return err
+	for _, address := range []string{entry.Address, entry.Address6} {
+		if address == "" {
+			continue
+		}
+		dns.Address = address
+		if err := PublishDNSUpdate(entry.Network, dns); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -402,7 +444,7 @@ func getExtClientDNS(network string) []models.DNSUpdate {
 			alldns = append(alldns, dns)
 		}
 		if client.Address6 != "" {
-			dns.Address = client.Address
+			dns.Address = client.Address6
 			alldns = append(alldns, dns)
 		}
 	}
@@ -418,9 +460,15 @@ func getCustomDNS(network string) []models.DNSUpdate {
 	}
 	for _, custom := range customdns {
 		dns.Action = models.DNSInsert
-		dns.Address = custom.Address
 		dns.Name = custom.Name + "." + custom.Network
-		alldns = append(alldns, dns)
+		if custom.Address != "" {
+			dns.Address = custom.Address
+			alldns = append(alldns, dns)
+		}
+		if custom.Address6 != "" {
+			dns.Address = custom.Address6
+			alldns = append(alldns, dns)
+		}
 	}
 	return alldns
 }
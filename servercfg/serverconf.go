@@ -557,6 +557,74 @@ func GetSQLConn() string {
 	return sqlconn
 }
 
+// GetDBEncryptionKeys - gets the base64 encoded keys used to encrypt sensitive fields in the database
+// keys are separated by commas or newlines, the first key encrypts and the others are only used to decrypt
+func GetDBEncryptionKeys() (string, error) {
+	if os.Getenv("DB_ENCRYPTION_KEY") != "" {
+		return os.Getenv("DB_ENCRYPTION_KEY"), nil
+	}
+	keyFile := config.Config.Server.DBEncryptionKeyFile
+	if os.Getenv("DB_ENCRYPTION_KEY_FILE") != "" {
+		keyFile = os.Getenv("DB_ENCRYPTION_KEY_FILE")
+	}
+	if keyFile == "" {
+		return "", nil
+	}
+	keys, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", err
+	}
+	return string(keys), nil
+}
+
+// GetTrashRetention - gets how long deleted networks, hosts and ext clients are kept for restoring, 0 disables the trash
+func GetTrashRetention() time.Duration {
+	retention := 7 * 24 * time.Hour // default
+	value := config.Config.Server.TrashRetention
+	if os.Getenv("TRASH_RETENTION") != "" {
+		value = os.Getenv("TRASH_RETENTION")
+	}
+	if value == "0" {
+		return 0
+	}
+	if value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			retention = parsed
+		}
+	}
+	return retention
+}
+
+// GetAddressCooldown - gets how long a released node or ext client address is kept from being handed out again
+func GetAddressCooldown() time.Duration {
+	cooldown := 10 * time.Minute // default
+	value := config.Config.Server.AddressCooldown
+	if os.Getenv("ADDRESS_COOLDOWN") != "" {
+		value = os.Getenv("ADDRESS_COOLDOWN")
+	}
+	if value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			cooldown = parsed
+		}
+	}
+	return cooldown
+}
+
+// GetEphemeralHostTimeout - gets how long a host registered with an ephemeral enrollment key may stay offline before it is removed
+func GetEphemeralHostTimeout() time.Duration {
+	timeout := 30 * time.Minute // default
+	value := config.Config.Server.EphemeralHostTimeout
+	if os.Getenv("EPHEMERAL_HOST_TIMEOUT") != "" {
+		value = os.Getenv("EPHEMERAL_HOST_TIMEOUT")
+	}
+	if value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			timeout = parsed
+		}
+	}
+	return timeout
+}
+
 // GetNodeID - gets the node id
 func GetNodeID() string {
 	var id string
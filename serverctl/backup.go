@@ -0,0 +1,208 @@
+package serverctl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/migrate"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrBackupPassphrase - returned when an encrypted archive is opened without the right passphrase
+	ErrBackupPassphrase = errors.New("could not decrypt backup, invalid passphrase")
+	// ErrBackupVersion - returned when an archive was written by an incompatible server
+	ErrBackupVersion = errors.New("incompatible backup version")
+	// ErrBackupUnencrypted - returned when a backup without a passphrase is not explicitly allowed to be unencrypted
+	ErrBackupUnencrypted = errors.New("a passphrase is required, an unencrypted backup holds the server's secrets in plain text")
+)
+
+// backupTables - the tables that are exported and restored, revision counters stay with the server so restored
+// records get revisions past any ETag handed out before the restore
+func backupTables() []string {
+	tables := []string{}
+	for _, table := range database.Tables() {
+		if table != database.REVISIONS_TABLE_NAME {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// CreateBackup - exports every table to an archive, encrypted with the passphrase of the request, an unencrypted
+// archive is only written when the request is marked insecure
+func CreateBackup(request models.BackupRequest) (*models.BackupArchive, error) {
+	if request.Passphrase == "" && !request.Insecure {
+		return nil, ErrBackupUnencrypted
+	}
+	schema, err := migrate.GetSchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	backup := models.ServerBackup{
+		ServerVersion: servercfg.GetVersion(),
+		SchemaVersion: schema.Version,
+		CreatedAt:     time.Now(),
+		Tables:        make(map[string]map[string]string),
+	}
+	for _, table := range backupTables() {
+		records, err := database.FetchRecords(table)
+		if err != nil {
+			if database.IsEmptyRecord(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to export table %s: %w", table, err)
+		}
+		backup.Tables[table] = records
+	}
+	data, err := json.Marshal(&backup)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err = zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	archive := models.BackupArchive{
+		FormatVersion: models.BACKUP_FORMAT_VERSION,
+		ServerVersion: backup.ServerVersion,
+		CreatedAt:     backup.CreatedAt.Unix(),
+		Data:          buf.Bytes(),
+	}
+	if request.Passphrase != "" {
+		if err = sealArchive(&archive, request.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+	return &archive, nil
+}
+
+// OpenBackup - decrypts and decompresses an archive, checking it can be restored by this server
+func OpenBackup(archive *models.BackupArchive, passphrase string) (*models.ServerBackup, error) {
+	if archive.FormatVersion != models.BACKUP_FORMAT_VERSION {
+		return nil, fmt.Errorf("%w: archive format %d, supported format %d", ErrBackupVersion, archive.FormatVersion, models.BACKUP_FORMAT_VERSION)
+	}
+	data := archive.Data
+	if archive.Encrypted {
+		var err error
+		if data, err = openArchive(archive, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var backup models.ServerBackup
+	if err = json.Unmarshal(raw, &backup); err != nil {
+		return nil, err
+	}
+	if backup.SchemaVersion > migrate.LatestVersion() {
+		return nil, fmt.Errorf("%w: backup from server %s uses schema version %d, this server supports up to %d",
+			ErrBackupVersion, backup.ServerVersion, backup.SchemaVersion, migrate.LatestVersion())
+	}
+	return &backup, nil
+}
+
+// RestoreBackup - replaces the contents of every table with the contents of the archive, revision counters are kept
+// and bumped by the restored records, older backups are migrated to the current schema once restored
+func RestoreBackup(archive *models.BackupArchive, passphrase string) error {
+	backup, err := OpenBackup(archive, passphrase)
+	if err != nil {
+		return err
+	}
+	// archives of older servers may hold revision counters, they are ignored
+	known := map[string]struct{}{database.REVISIONS_TABLE_NAME: {}}
+	tx := database.BeginTx()
+	for _, table := range backupTables() {
+		known[table] = struct{}{}
+		tx.DeleteAll(table)
+		for key, value := range backup.Tables[table] {
+			if err = tx.Insert(key, value, table); err != nil {
+				return fmt.Errorf("invalid record %s in table %s: %w", key, table, err)
+			}
+		}
+	}
+	for table := range backup.Tables {
+		if _, ok := known[table]; !ok {
+			logger.Log(0, "restore: skipping unknown table", table)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	logic.ClearCaches()
+	logic.SetJWTSecret()
+	if err = migrate.Run(); err != nil {
+		return fmt.Errorf("backup restored but migration failed: %w", err)
+	}
+	return nil
+}
+
+func backupKey(passphrase string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+func sealArchive(archive *models.BackupArchive, passphrase string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
+	}
+	key, err := backupKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	archive.Data = secretbox.Seal(nil, archive.Data, &nonce, key)
+	archive.Encrypted = true
+	archive.Salt = salt
+	archive.Nonce = nonce[:]
+	return nil
+}
+
+func openArchive(archive *models.BackupArchive, passphrase string) ([]byte, error) {
+	if passphrase == "" || len(archive.Nonce) != 24 {
+		return nil, ErrBackupPassphrase
+	}
+	key, err := backupKey(passphrase, archive.Salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], archive.Nonce)
+	data, ok := secretbox.Open(nil, archive.Data, &nonce, key)
+	if !ok {
+		return nil, ErrBackupPassphrase
+	}
+	return data, nil
+}
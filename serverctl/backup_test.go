@@ -0,0 +1,71 @@
+package serverctl
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	database.InitializeDatabase()
+	defer database.CloseDB()
+	os.Exit(m.Run())
+}
+
+func TestBackupRestore(t *testing.T) {
+	database.DeleteAllRecords(database.GENERATED_TABLE_NAME)
+	assert.Nil(t, database.Insert("before", `{"value":1}`, database.GENERATED_TABLE_NAME))
+	t.Run("PassphraseRequired", func(t *testing.T) {
+		_, err := CreateBackup(models.BackupRequest{})
+		assert.True(t, errors.Is(err, ErrBackupUnencrypted))
+	})
+	t.Run("Unencrypted", func(t *testing.T) {
+		archive, err := CreateBackup(models.BackupRequest{Insecure: true})
+		assert.Nil(t, err)
+		assert.False(t, archive.Encrypted)
+		backup, err := OpenBackup(archive, "")
+		assert.Nil(t, err)
+		assert.Equal(t, `{"value":1}`, backup.Tables[database.GENERATED_TABLE_NAME]["before"])
+		assert.NotEmpty(t, backup.Tables[database.SERVER_UUID_TABLE_NAME])
+		assert.NotContains(t, backup.Tables, database.REVISIONS_TABLE_NAME)
+	})
+	t.Run("WrongPassphrase", func(t *testing.T) {
+		archive, err := CreateBackup(models.BackupRequest{Passphrase: "secret"})
+		assert.Nil(t, err)
+		assert.True(t, archive.Encrypted)
+		_, err = OpenBackup(archive, "wrong")
+		assert.True(t, errors.Is(err, ErrBackupPassphrase))
+		_, err = OpenBackup(archive, "")
+		assert.True(t, errors.Is(err, ErrBackupPassphrase))
+	})
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		archive, err := CreateBackup(models.BackupRequest{Insecure: true})
+		assert.Nil(t, err)
+		archive.FormatVersion = models.BACKUP_FORMAT_VERSION + 1
+		assert.True(t, errors.Is(RestoreBackup(archive, ""), ErrBackupVersion))
+	})
+	t.Run("Restore", func(t *testing.T) {
+		assert.Nil(t, database.Insert("backupnet", `{"netid":"backupnet"}`, database.NETWORKS_TABLE_NAME))
+		defer database.DeleteRecord(database.NETWORKS_TABLE_NAME, "backupnet")
+		archive, err := CreateBackup(models.BackupRequest{Passphrase: "secret"})
+		assert.Nil(t, err)
+		assert.Nil(t, database.Insert("after", `{"value":2}`, database.GENERATED_TABLE_NAME))
+		assert.Nil(t, database.Insert("backupnet", `{"netid":"backupnet","nodelimit":5}`, database.NETWORKS_TABLE_NAME))
+		revision, err := database.GetRevision(database.NETWORKS_TABLE_NAME, "backupnet")
+		assert.Nil(t, err)
+		assert.Nil(t, RestoreBackup(archive, "secret"))
+		// the restored record gets a revision past the one of the overwritten record
+		restored, err := database.GetRevision(database.NETWORKS_TABLE_NAME, "backupnet")
+		assert.Nil(t, err)
+		assert.Greater(t, restored, revision)
+		_, err = database.FetchRecord(database.GENERATED_TABLE_NAME, "after")
+		assert.True(t, database.IsEmptyRecord(err))
+		record, err := database.FetchRecord(database.GENERATED_TABLE_NAME, "before")
+		assert.Nil(t, err)
+		assert.Equal(t, `{"value":1}`, record)
+	})
+}